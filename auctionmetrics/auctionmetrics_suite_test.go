@@ -0,0 +1,13 @@
+package auctionmetrics_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAuctionmetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Auctionmetrics Suite")
+}
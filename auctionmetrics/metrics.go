@@ -0,0 +1,149 @@
+package auctionmetrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	counterKind   = "counter"
+	gaugeKind     = "gauge"
+	histogramKind = "histogram"
+)
+
+// family is a metric and all of its labelled series, written out in the
+// Prometheus text exposition format. It is not safe for concurrent use.
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	buckets    []float64
+	series     map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	bucketCount []uint64
+	sum         float64
+	count       uint64
+}
+
+func newFamily(kind, name, help string, labelNames ...string) *family {
+	return &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		series:     map[string]*series{},
+	}
+}
+
+func newHistogram(name, help string, buckets []float64, labelNames ...string) *family {
+	f := newFamily(histogramKind, name, help, labelNames...)
+	f.buckets = buckets
+	return f
+}
+
+func (f *family) with(labelValues ...string) *series {
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: labelValues}
+		if f.kind == histogramKind {
+			s.bucketCount = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (f *family) add(delta float64, labelValues ...string) {
+	f.with(labelValues...).value += delta
+}
+
+func (f *family) set(value float64, labelValues ...string) {
+	f.with(labelValues...).value = value
+}
+
+func (f *family) observe(value float64, labelValues ...string) {
+	s := f.with(labelValues...)
+	for i, upperBound := range f.buckets {
+		if value <= upperBound {
+			s.bucketCount[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (f *family) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != histogramKind {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labels(s.labelValues), formatFloat(s.value))
+			continue
+		}
+
+		for i, upperBound := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", formatFloat(upperBound)), s.bucketCount[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labels(s.labelValues), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labels(s.labelValues), s.count)
+	}
+}
+
+// labels renders the label set of a series, with an optional extra label
+// such as a histogram bucket's "le".
+func (f *family) labels(labelValues []string, extra ...string) string {
+	pairs := []string{}
+	for i, name := range f.labelNames {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1 @@
+package auctionmetrics // import "code.cloudfoundry.org/auction/auctionmetrics"
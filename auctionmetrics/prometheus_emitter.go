@@ -0,0 +1,215 @@
+package auctionmetrics
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	lrpType  = "lrp"
+	taskType = "task"
+)
+
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+var waitBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+var batchBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500}
+
+// PrometheusEmitter is an AuctionMetricEmitterDelegate that keeps the metrics
+// it is given in memory and serves them in the Prometheus text format.
+type PrometheusEmitter struct {
+	lock     *sync.Mutex
+	families []*family
+
+	fetchStatesDuration     *family
+	failedCellStateRequests *family
+	cellsFetched            *family
+	scheduleDuration        *family
+	commitDuration          *family
+	cellCommitDuration      *family
+	unknownCommitOutcomes   *family
+	placementFailures       *family
+	inflightLimitRejections *family
+	batchSize               *family
+	queueWait               *family
+	zoneSkew                *family
+	queueDepth              *family
+	oldestQueuedAuctionAge  *family
+	deduplicatedAuctions    *family
+	auctions                *family
+	auctionLatency          *family
+}
+
+func NewPrometheusEmitter() *PrometheusEmitter {
+	e := &PrometheusEmitter{lock: &sync.Mutex{}}
+
+	e.fetchStatesDuration = e.register(newHistogram("auction_fetch_states_duration_seconds", "Time taken to fetch the state of every cell.", durationBuckets))
+	e.failedCellStateRequests = e.register(newFamily(counterKind, "auction_failed_cell_state_requests_total", "Cell state requests that failed."))
+	e.cellsFetched = e.register(newFamily(gaugeKind, "auction_cells_fetched", "Cells whose state was fetched for the last round."))
+	e.scheduleDuration = e.register(newHistogram("auction_schedule_duration_seconds", "Time taken to place and commit the work of a round.", durationBuckets))
+	e.commitDuration = e.register(newHistogram("auction_commit_duration_seconds", "Time taken to commit the work of a round to every cell.", durationBuckets))
+	e.cellCommitDuration = e.register(newHistogram("auction_cell_commit_duration_seconds", "Time taken to commit work to a single cell.", durationBuckets))
+	e.unknownCommitOutcomes = e.register(newFamily(counterKind, "auction_unknown_commit_outcomes_total", "Auctions whose commit could not be confirmed by the cell.", "type"))
+	e.placementFailures = e.register(newFamily(counterKind, "auction_placement_failures_total", "Auctions that failed to be placed, by reason.", "reason", "type"))
+	e.inflightLimitRejections = e.register(newFamily(counterKind, "auction_inflight_limit_rejections_total", "Auctions turned away by the in-flight container creation limit.", "type"))
+	e.batchSize = e.register(newHistogram("auction_batch_size", "Auctions drained for a round.", batchBuckets))
+	e.queueWait = e.register(newHistogram("auction_queue_wait_seconds", "Time auctions spent queued before their round.", waitBuckets))
+	e.zoneSkew = e.register(newFamily(gaugeKind, "auction_zone_skew", "Largest difference in instance counts between zones after the last round."))
+	e.queueDepth = e.register(newFamily(gaugeKind, "auction_queue_depth", "Auctions waiting for a round.", "type"))
+	e.oldestQueuedAuctionAge = e.register(newFamily(gaugeKind, "auction_oldest_queued_auction_age_seconds", "Age of the oldest auction waiting for a round."))
+	e.deduplicatedAuctions = e.register(newFamily(counterKind, "auction_deduplicated_total", "Duplicate auctions merged into another.", "type"))
+	e.auctions = e.register(newFamily(counterKind, "auction_results_total", "Auctions completed, by outcome.", "outcome", "type"))
+	e.auctionLatency = e.register(newHistogram("auction_latency_seconds", "Time from queueing an auction to its successful placement.", waitBuckets, "type"))
+
+	return e
+}
+
+func (e *PrometheusEmitter) register(f *family) *family {
+	e.families = append(e.families, f)
+	return f
+}
+
+func (e *PrometheusEmitter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buffer := &bytes.Buffer{}
+
+	e.lock.Lock()
+	for _, f := range e.families {
+		f.write(buffer)
+	}
+	e.lock.Unlock()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buffer.Bytes())
+}
+
+func (e *PrometheusEmitter) FetchStatesCompleted(duration time.Duration) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.fetchStatesDuration.observe(duration.Seconds())
+	return nil
+}
+
+func (e *PrometheusEmitter) FailedCellStateRequest() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.failedCellStateRequests.add(1)
+}
+
+func (e *PrometheusEmitter) CellStatesFetched(cells int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.cellsFetched.set(float64(cells))
+}
+
+func (e *PrometheusEmitter) ScheduleCompleted(duration time.Duration) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.scheduleDuration.observe(duration.Seconds())
+	return nil
+}
+
+func (e *PrometheusEmitter) CommitCompleted(duration time.Duration) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.commitDuration.observe(duration.Seconds())
+	return nil
+}
+
+func (e *PrometheusEmitter) CellCommitCompleted(_ string, duration time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.cellCommitDuration.observe(duration.Seconds())
+}
+
+func (e *PrometheusEmitter) UnknownCommitOutcomes(lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.unknownCommitOutcomes.add(float64(lrps), lrpType)
+	e.unknownCommitOutcomes.add(float64(tasks), taskType)
+}
+
+func (e *PrometheusEmitter) PlacementFailures(reason string, lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.placementFailures.add(float64(lrps), reason, lrpType)
+	e.placementFailures.add(float64(tasks), reason, taskType)
+}
+
+func (e *PrometheusEmitter) InflightLimitRejections(lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.inflightLimitRejections.add(float64(lrps), lrpType)
+	e.inflightLimitRejections.add(float64(tasks), taskType)
+}
+
+func (e *PrometheusEmitter) BatchSize(lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.batchSize.observe(float64(lrps + tasks))
+}
+
+func (e *PrometheusEmitter) QueueWaitTimes(waitTimes []time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, wait := range waitTimes {
+		e.queueWait.observe(wait.Seconds())
+	}
+}
+
+func (e *PrometheusEmitter) ZoneSkew(instances int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.zoneSkew.set(float64(instances))
+}
+
+func (e *PrometheusEmitter) QueueDepth(lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.queueDepth.set(float64(lrps), lrpType)
+	e.queueDepth.set(float64(tasks), taskType)
+}
+
+func (e *PrometheusEmitter) OldestQueuedAuctionAge(age time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.oldestQueuedAuctionAge.set(age.Seconds())
+}
+
+func (e *PrometheusEmitter) AuctionsDeduplicated(lrps int, tasks int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.deduplicatedAuctions.add(float64(lrps), lrpType)
+	e.deduplicatedAuctions.add(float64(tasks), taskType)
+}
+
+func (e *PrometheusEmitter) AuctionCompleted(results auctiontypes.AuctionResults) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.auctions.add(float64(len(results.SuccessfulLRPs)), "successful", lrpType)
+	e.auctions.add(float64(len(results.SuccessfulTasks)), "successful", taskType)
+	e.auctions.add(float64(len(results.FailedLRPs)), "failed", lrpType)
+	e.auctions.add(float64(len(results.FailedTasks)), "failed", taskType)
+	e.auctions.add(float64(len(results.UnknownLRPs)), "unknown", lrpType)
+	e.auctions.add(float64(len(results.UnknownTasks)), "unknown", taskType)
+	e.auctions.add(float64(len(results.CancelledLRPs)), "cancelled", lrpType)
+	e.auctions.add(float64(len(results.CancelledTasks)), "cancelled", taskType)
+
+	for i := range results.SuccessfulLRPs {
+		e.auctionLatency.observe(results.SuccessfulLRPs[i].WaitDuration.Seconds(), lrpType)
+	}
+	for i := range results.SuccessfulTasks {
+		e.auctionLatency.observe(results.SuccessfulTasks[i].WaitDuration.Seconds(), taskType)
+	}
+}
+
+var _ auctiontypes.AuctionMetricEmitterDelegate = (*PrometheusEmitter)(nil)
+var _ auctiontypes.CommitMetricEmitter = (*PrometheusEmitter)(nil)
+var _ auctiontypes.RoundMetricEmitter = (*PrometheusEmitter)(nil)
+var _ auctiontypes.QueueMetricEmitter = (*PrometheusEmitter)(nil)
+var _ http.Handler = (*PrometheusEmitter)(nil)
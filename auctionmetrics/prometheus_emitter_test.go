@@ -0,0 +1,144 @@
+package auctionmetrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionmetrics"
+	"code.cloudfoundry.org/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusEmitter", func() {
+	var emitter *auctionmetrics.PrometheusEmitter
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		emitter = auctionmetrics.NewPrometheusEmitter()
+		server = httptest.NewServer(emitter)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	scrape := func() string {
+		resp, err := http.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/plain; version=0.0.4; charset=utf-8"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("describes every metric before anything is recorded", func() {
+		body := scrape()
+		Expect(body).To(ContainSubstring("# HELP auction_cells_fetched "))
+		Expect(body).To(ContainSubstring("# TYPE auction_cells_fetched gauge\n"))
+		Expect(body).To(ContainSubstring("# TYPE auction_placement_failures_total counter\n"))
+		Expect(body).To(ContainSubstring("# TYPE auction_latency_seconds histogram\n"))
+		Expect(body).NotTo(ContainSubstring("auction_cells_fetched 0"))
+	})
+
+	It("reports the cells fetched by the last round", func() {
+		emitter.CellStatesFetched(4)
+		emitter.CellStatesFetched(3)
+		Expect(scrape()).To(ContainSubstring("\nauction_cells_fetched 3\n"))
+	})
+
+	It("counts failed cell state requests", func() {
+		emitter.FailedCellStateRequest()
+		emitter.FailedCellStateRequest()
+		Expect(scrape()).To(ContainSubstring("\nauction_failed_cell_state_requests_total 2\n"))
+	})
+
+	It("counts placement failures by reason and type", func() {
+		emitter.PlacementFailures("cell-mismatch", 2, 1)
+		emitter.PlacementFailures("cell-mismatch", 1, 0)
+		emitter.PlacementFailures("insufficient-resources", 0, 5)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`auction_placement_failures_total{reason="cell-mismatch",type="lrp"} 3` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_placement_failures_total{reason="cell-mismatch",type="task"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_placement_failures_total{reason="insufficient-resources",type="task"} 5` + "\n"))
+	})
+
+	It("sets queue depth gauges by type", func() {
+		emitter.QueueDepth(7, 2)
+		emitter.OldestQueuedAuctionAge(1500 * time.Millisecond)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`auction_queue_depth{type="lrp"} 7` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_queue_depth{type="task"} 2` + "\n"))
+		Expect(body).To(ContainSubstring("\nauction_oldest_queued_auction_age_seconds 1.5\n"))
+	})
+
+	It("buckets durations into cumulative histograms", func() {
+		emitter.FetchStatesCompleted(20 * time.Millisecond)
+		emitter.FetchStatesCompleted(200 * time.Millisecond)
+		emitter.FetchStatesCompleted(time.Minute)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`auction_fetch_states_duration_seconds_bucket{le="0.01"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_fetch_states_duration_seconds_bucket{le="0.025"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_fetch_states_duration_seconds_bucket{le="0.25"} 2` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_fetch_states_duration_seconds_bucket{le="30"} 2` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_fetch_states_duration_seconds_bucket{le="+Inf"} 3` + "\n"))
+		Expect(body).To(ContainSubstring("\nauction_fetch_states_duration_seconds_sum 60.22\n"))
+		Expect(body).To(ContainSubstring("\nauction_fetch_states_duration_seconds_count 3\n"))
+	})
+
+	It("observes every queue wait time", func() {
+		emitter.QueueWaitTimes([]time.Duration{time.Second, 45 * time.Second})
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`auction_queue_wait_seconds_bucket{le="1"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`auction_queue_wait_seconds_bucket{le="60"} 2` + "\n"))
+		Expect(body).To(ContainSubstring("\nauction_queue_wait_seconds_count 2\n"))
+	})
+
+	Describe("AuctionCompleted", func() {
+		BeforeEach(func() {
+			placed := auctiontypes.LRPAuction{AuctionRecord: auctiontypes.AuctionRecord{WaitDuration: 3 * time.Second}}
+			task := auctiontypes.TaskAuction{AuctionRecord: auctiontypes.AuctionRecord{WaitDuration: 200 * time.Second}}
+
+			emitter.AuctionCompleted(auctiontypes.AuctionResults{
+				SuccessfulLRPs:  []auctiontypes.LRPAuction{placed},
+				SuccessfulTasks: []auctiontypes.TaskAuction{task},
+				FailedLRPs:      []auctiontypes.LRPAuction{placed, placed},
+				UnknownTasks:    []auctiontypes.TaskAuction{task},
+			})
+		})
+
+		It("counts results by outcome and type", func() {
+			body := scrape()
+			Expect(body).To(ContainSubstring(`auction_results_total{outcome="successful",type="lrp"} 1` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_results_total{outcome="successful",type="task"} 1` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_results_total{outcome="failed",type="lrp"} 2` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_results_total{outcome="unknown",type="task"} 1` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_results_total{outcome="cancelled",type="lrp"} 0` + "\n"))
+		})
+
+		It("records the latency of successful placements", func() {
+			body := scrape()
+			Expect(body).To(ContainSubstring(`auction_latency_seconds_bucket{type="lrp",le="5"} 1` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_latency_seconds_bucket{type="task",le="120"} 0` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_latency_seconds_bucket{type="task",le="300"} 1` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_latency_seconds_sum{type="lrp"} 3` + "\n"))
+			Expect(body).To(ContainSubstring(`auction_latency_seconds_count{type="task"} 1` + "\n"))
+		})
+	})
+
+	It("escapes label values", func() {
+		emitter.PlacementFailures("a \"quoted\"\nreason", 1, 0)
+		Expect(scrape()).To(ContainSubstring(`auction_placement_failures_total{reason="a \"quoted\"\nreason",type="lrp"} 1` + "\n"))
+	})
+})
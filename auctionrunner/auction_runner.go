@@ -1,6 +1,7 @@
 package auctionrunner
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/workpool"
 )
 
@@ -23,6 +25,90 @@ type auctionRunner struct {
 	startingContainerWeight       float64
 	startingContainerCountMaximum int
 	auctionType                   *AuctionType
+	schedulerOptions              []SchedulerOption
+	batchOptions                  []BatchOption
+	pipelined                     bool
+	queueMetricsInterval          time.Duration
+	roundTimeout                  time.Duration
+	gracefulShutdown              bool
+	tracer                        auctiontypes.Tracer
+	auditSink                     AuditSink
+	roundCapturer                 RoundCapturer
+}
+
+type RunnerOption func(*auctionRunner)
+
+// WithSchedulerOptions applies the given options to the Scheduler built for
+// every auction round.
+func WithSchedulerOptions(options ...SchedulerOption) RunnerOption {
+	return func(a *auctionRunner) {
+		a.schedulerOptions = append(a.schedulerOptions, options...)
+	}
+}
+
+// WithBatchOptions configures the Batch that queues work between rounds.
+func WithBatchOptions(options ...BatchOption) RunnerOption {
+	return func(a *auctionRunner) {
+		a.batchOptions = append(a.batchOptions, options...)
+	}
+}
+
+// WithQueueMetricsInterval emits the queue depth and oldest queued auction
+// age on the given interval, in addition to at the start of every round.
+func WithQueueMetricsInterval(interval time.Duration) RunnerOption {
+	return func(a *auctionRunner) {
+		a.queueMetricsInterval = interval
+	}
+}
+
+// WithRoundTimeout gives every auction round a deadline, which is passed on
+// to the delegate and to rep clients that accept a context.
+func WithRoundTimeout(timeout time.Duration) RunnerOption {
+	return func(a *auctionRunner) {
+		a.roundTimeout = timeout
+	}
+}
+
+// WithPipelinedRounds lets the next round fetch cell states while the
+// current round is still committing. Work placed by the in-flight round is
+// accounted against the freshly fetched states before the next round schedules.
+func WithPipelinedRounds() RunnerOption {
+	return func(a *auctionRunner) {
+		a.pipelined = true
+	}
+}
+
+// WithGracefulShutdown lets the round in progress finish when the runner is
+// stopped. Auctions still queued afterwards are reported to the delegate as
+// failed with ErrorAuctioneerShuttingDown, and new work is refused.
+func WithGracefulShutdown() RunnerOption {
+	return func(a *auctionRunner) {
+		a.gracefulShutdown = true
+	}
+}
+
+// WithTracer traces every auction round through tracer, with child spans for
+// fetching cells and their states, scheduling, committing to each cell and
+// notifying the delegate.
+func WithTracer(tracer auctiontypes.Tracer) RunnerOption {
+	return func(a *auctionRunner) {
+		a.tracer = tracer
+	}
+}
+
+// WithAuditSink records the outcome of every auction to sink.
+func WithAuditSink(sink AuditSink) RunnerOption {
+	return func(a *auctionRunner) {
+		a.auditSink = sink
+	}
+}
+
+// WithRoundCapturer hands capturer the cell states, request and results of
+// every round that schedules work, so that it can be replayed with ReplayRound.
+func WithRoundCapturer(capturer RoundCapturer) RunnerOption {
+	return func(a *auctionRunner) {
+		a.roundCapturer = capturer
+	}
 }
 
 func New(
@@ -34,103 +120,518 @@ func New(
 	startingContainerWeight float64,
 	startingContainerCountMaximum int,
 	auctionType *AuctionType,
+	options ...RunnerOption,
 ) *auctionRunner {
-	return &auctionRunner{
+	a := &auctionRunner{
 		logger: logger,
 
 		delegate:                      delegate,
 		metricEmitter:                 metricEmitter,
-		batch:                         NewBatch(clock),
 		clock:                         clock,
 		workPool:                      workPool,
 		startingContainerWeight:       startingContainerWeight,
 		startingContainerCountMaximum: startingContainerCountMaximum,
 		auctionType:                   auctionType,
 	}
+	for _, option := range options {
+		option(a)
+	}
+	batchOptions := append([]BatchOption{WithBatchMetricEmitter(metricEmitter)}, a.batchOptions...)
+	a.batch = NewBatch(clock, batchOptions...)
+	return a
 }
 
 func (a *auctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return a.RunContext(ctx, ready)
+}
+
+// RunContext runs auction rounds until ctx is done, aborting any round still
+// in progress at that point unless the runner shuts down gracefully.
+func (a *auctionRunner) RunContext(ctx context.Context, ready chan<- struct{}) error {
+	err := a.batch.Replay()
+	if err != nil {
+		a.logger.Error("failed-to-replay-auctions", err)
+		return err
+	}
+
 	close(ready)
 
+	if a.queueMetricsInterval > 0 {
+		stopQueueMetrics := make(chan struct{})
+		defer close(stopQueueMetrics)
+		go a.emitQueueMetricsPeriodically(stopQueueMetrics)
+	}
+
 	var hasWork chan struct{}
 	hasWork = a.batch.HasWork
 
-	for {
+	var inflightRound chan auctiontypes.AuctionResults
+
+	roundParent := ctx
+	if a.gracefulShutdown {
+		roundParent = context.Background()
+	}
+
+	for ctx.Err() == nil {
 		select {
 		case <-hasWork:
-			logger := a.logger.Session("auction")
-
-			logger.Info("fetching-cell-reps")
-			clients, err := a.delegate.FetchCellReps()
-			if err != nil {
-				logger.Error("failed-to-fetch-reps", err)
-				time.Sleep(time.Second)
-				hasWork = make(chan struct{}, 1)
-				hasWork <- struct{}{}
+			if ctx.Err() != nil {
+				// the select picks at random when both are ready, so do not
+				// start a round once shutting down has begun
 				break
 			}
-			logger.Info("fetched-cell-reps", lager.Data{"cell-reps-count": len(clients)})
+			hasWork, inflightRound = a.round(roundParent, inflightRound)
+		case <-ctx.Done():
+		}
+	}
 
-			hasWork = a.batch.HasWork
+	if inflightRound != nil {
+		<-inflightRound
+	}
+	if a.gracefulShutdown {
+		a.handBackQueuedAuctions()
+	}
+	return nil
+}
 
-			logger.Info("fetching-zone-state")
-			fetchStatesStartTime := time.Now()
-			zones := FetchStateAndBuildZones(logger, a.workPool, clients, a.metricEmitter)
-			fetchStateDuration := time.Since(fetchStatesStartTime)
-			err = a.metricEmitter.FetchStatesCompleted(fetchStateDuration)
-			if err != nil {
-				logger.Error("failed-sending-fetch-states-completed-metric", err)
-			}
+// handBackQueuedAuctions reports every auction that never made it into a
+// round as failed, so that it can be queued again elsewhere. The auctions are
+// left in the auction store, if any, so that the next process replays them.
+func (a *auctionRunner) handBackQueuedAuctions() {
+	logger := a.logger.Session("hand-back-queued-auctions")
+
+	a.batch.Close()
+	lrpAuctions, taskAuctions := a.batch.DrainAll()
+	results := a.drainWithdrawn()
+
+	for i := range lrpAuctions {
+		lrpAuctions[i].PlacementError = auctiontypes.ErrorAuctioneerShuttingDown.Error()
+		results.FailedLRPs = append(results.FailedLRPs, lrpAuctions[i])
+	}
+	for i := range taskAuctions {
+		taskAuctions[i].PlacementError = auctiontypes.ErrorAuctioneerShuttingDown.Error()
+		results.FailedTasks = append(results.FailedTasks, taskAuctions[i])
+	}
+
+	logger.Info("handing-back", lager.Data{
+		"lrp-start-auctions":      len(lrpAuctions),
+		"task-auctions":           len(taskAuctions),
+		"cancelled-lrp-auctions":  len(results.CancelledLRPs),
+		"cancelled-task-auctions": len(results.CancelledTasks),
+	})
+	if len(results.FailedLRPs) == 0 && len(results.FailedTasks) == 0 &&
+		len(results.CancelledLRPs) == 0 && len(results.CancelledTasks) == 0 {
+		return
+	}
+	a.streamFailures(results)
+	a.emitFailureMetrics(results)
+	a.audit(logger, results, nil, nil)
+	a.delegate.AuctionCompleted(results)
+}
+
+// round runs a single auction round. It returns the channel that triggers the
+// next round and, in pipelined mode, the channel the committing round reports on.
+func (a *auctionRunner) round(ctx context.Context, inflightRound chan auctiontypes.AuctionResults) (chan struct{}, chan auctiontypes.AuctionResults) {
+	logger := a.logger.Session("auction")
+
+	roundCtx, cancelRoundContext := context.WithCancel(ctx)
+	if a.roundTimeout > 0 {
+		roundCtx, cancelRoundContext = context.WithTimeout(ctx, a.roundTimeout)
+	}
+	if a.tracer != nil {
+		roundCtx = auctiontypes.ContextWithTracer(roundCtx, a.tracer)
+	}
+	roundCtx, roundSpan := startSpan(roundCtx, "auction-round")
+	cancelRound := func() {
+		roundSpan.End()
+		cancelRoundContext()
+	}
+
+	logger.Info("fetching-cell-reps")
+	clients, err := a.fetchCellReps(roundCtx)
+	if err != nil {
+		roundSpan.RecordError(err)
+		cancelRound()
+		logger.Error("failed-to-fetch-reps", err)
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+		retry := make(chan struct{}, 1)
+		retry <- struct{}{}
+		return retry, inflightRound
+	}
+	logger.Info("fetched-cell-reps", lager.Data{"cell-reps-count": len(clients)})
+
+	logger.Info("fetching-zone-state")
+	fetchStatesStartTime := time.Now()
+	zones := FetchStateAndBuildZonesContext(roundCtx, logger, a.workPool, clients, a.metricEmitter)
+	fetchStateDuration := time.Since(fetchStatesStartTime)
+	err = a.metricEmitter.FetchStatesCompleted(fetchStateDuration)
+	if err != nil {
+		logger.Error("failed-sending-fetch-states-completed-metric", err)
+	}
+
+	cellCount := 0
+	for zone, cells := range zones {
+		logger.Info("zone-state", lager.Data{"zone": zone, "cell-count": len(cells)})
+		cellCount += len(cells)
+	}
+	if metricEmitter, ok := a.metricEmitter.(auctiontypes.RoundMetricEmitter); ok {
+		metricEmitter.CellStatesFetched(cellCount)
+	}
+	logger.Info("fetched-zone-state", lager.Data{
+		"cell-state-count":    cellCount,
+		"num-failed-requests": len(clients) - cellCount,
+		"duration":            fetchStateDuration.String(),
+	})
+
+	if ctx.Err() != nil {
+		cancelRound()
+		logger.Info("round-aborted-before-draining")
+		return a.batch.HasWork, inflightRound
+	}
+
+	logger.Info("fetching-auctions")
+	a.emitQueueMetrics()
+	lrpAuctions, taskAuctions := a.batch.DedupeAndDrain()
+	withdrawn := a.drainWithdrawn()
+	logger.Info("fetched-auctions", lager.Data{
+		"lrp-start-auctions":      len(lrpAuctions),
+		"task-auctions":           len(taskAuctions),
+		"cancelled-lrp-auctions":  len(withdrawn.CancelledLRPs),
+		"cancelled-task-auctions": len(withdrawn.CancelledTasks),
+		"dropped-lrp-auctions":    len(withdrawn.FailedLRPs),
+		"dropped-task-auctions":   len(withdrawn.FailedTasks),
+	})
+	if len(lrpAuctions) == 0 && len(taskAuctions) == 0 {
+		defer cancelRound()
+		logger.Info("nothing-to-auction")
+		if len(withdrawn.CancelledLRPs) > 0 || len(withdrawn.CancelledTasks) > 0 ||
+			len(withdrawn.FailedLRPs) > 0 || len(withdrawn.FailedTasks) > 0 {
+			a.streamFailures(withdrawn)
+			a.emitFailureMetrics(withdrawn)
+			a.audit(logger, withdrawn, nil, nil)
+			a.notifyDelegate(roundCtx, withdrawn)
+		}
+		return a.batch.HasWork, inflightRound
+	}
+	roundSpan.SetAttributes(auctionGuidAttributes(lrpAuctions, taskAuctions)...)
+	a.emitBatchMetrics(lrpAuctions, taskAuctions)
+
+	if inflightRound != nil {
+		logger.Info("waiting-for-inflight-round")
+		ApplyInflightResults(zones, <-inflightRound)
+		inflightRound = nil
+	}
+
+	logger.Info("scheduling")
+	auctionRequest := auctiontypes.AuctionRequest{
+		LRPs:  lrpAuctions,
+		Tasks: taskAuctions,
+	}
+	capture := a.newRoundCapture(logger, zones, auctionRequest)
+
+	if a.pipelined {
+		inflightRound = make(chan auctiontypes.AuctionResults, 1)
+		go func(done chan<- auctiontypes.AuctionResults) {
+			defer cancelRound()
+			done <- a.schedule(roundCtx, logger, zones, auctionRequest, withdrawn, capture)
+		}(inflightRound)
+		return a.batch.HasWork, inflightRound
+	}
+
+	defer cancelRound()
+	a.schedule(roundCtx, logger, zones, auctionRequest, withdrawn, capture)
+	return a.batch.HasWork, nil
+}
+
+func (a *auctionRunner) fetchCellReps(ctx context.Context) (map[string]rep.Client, error) {
+	ctx, span := startSpan(ctx, "fetch-cell-reps")
+	defer span.End()
+
+	var clients map[string]rep.Client
+	var err error
+	if delegate, ok := a.delegate.(auctiontypes.ContextAuctionRunnerDelegate); ok {
+		clients, err = delegate.FetchCellRepsContext(ctx)
+	} else {
+		clients, err = a.delegate.FetchCellReps()
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(auctiontypes.NewAttribute("cell-reps-count", len(clients)))
+	return clients, nil
+}
+
+func (a *auctionRunner) notifyDelegate(ctx context.Context, results auctiontypes.AuctionResults) {
+	_, span := startSpan(ctx, "notify-delegate",
+		auctiontypes.NewAttribute("successful-lrps", len(results.SuccessfulLRPs)),
+		auctiontypes.NewAttribute("successful-tasks", len(results.SuccessfulTasks)),
+		auctiontypes.NewAttribute("failed-lrps", len(results.FailedLRPs)),
+		auctiontypes.NewAttribute("failed-tasks", len(results.FailedTasks)),
+		auctiontypes.NewAttribute("unknown-lrps", len(results.UnknownLRPs)),
+		auctiontypes.NewAttribute("unknown-tasks", len(results.UnknownTasks)),
+	)
+	defer span.End()
+	a.delegate.AuctionCompleted(results)
+}
+
+func (a *auctionRunner) schedule(
+	ctx context.Context,
+	logger lager.Logger,
+	zones map[string]Zone,
+	auctionRequest auctiontypes.AuctionRequest,
+	withdrawn auctiontypes.AuctionResults,
+	capture *RoundCapture,
+) auctiontypes.AuctionResults {
+	schedulerOptions := append([]SchedulerOption{WithMetricEmitter(a.metricEmitter)}, a.schedulerOptions...)
+	if delegate, ok := a.delegate.(auctiontypes.StreamingAuctionRunnerDelegate); ok {
+		schedulerOptions = append(schedulerOptions, WithAuctionListener(delegate))
+	}
+	a.streamFailures(withdrawn)
+
+	scheduler := NewScheduler(a.workPool, zones, a.clock, logger, a.startingContainerWeight, a.startingContainerCountMaximum, a.auctionType, schedulerOptions...)
+	auctionResults := scheduler.ScheduleContext(ctx, auctionRequest)
+	auctionResults.FailedLRPs = append(auctionResults.FailedLRPs, withdrawn.FailedLRPs...)
+	auctionResults.FailedTasks = append(auctionResults.FailedTasks, withdrawn.FailedTasks...)
+	auctionResults.CancelledLRPs = withdrawn.CancelledLRPs
+	auctionResults.CancelledTasks = withdrawn.CancelledTasks
+	logger.Info("scheduled", lager.Data{
+		"successful-lrp-start-auctions": len(auctionResults.SuccessfulLRPs),
+		"successful-task-auctions":      len(auctionResults.SuccessfulTasks),
+		"failed-lrp-start-auctions":     len(auctionResults.FailedLRPs),
+		"failed-task-auctions":          len(auctionResults.FailedTasks),
+		"unknown-lrp-start-auctions":    len(auctionResults.UnknownLRPs),
+		"unknown-task-auctions":         len(auctionResults.UnknownTasks),
+	})
+
+	if metricEmitter, ok := a.metricEmitter.(auctiontypes.CommitMetricEmitter); ok && (len(auctionResults.UnknownLRPs) > 0 || len(auctionResults.UnknownTasks) > 0) {
+		metricEmitter.UnknownCommitOutcomes(len(auctionResults.UnknownLRPs), len(auctionResults.UnknownTasks))
+	}
+	if metricEmitter, ok := a.metricEmitter.(auctiontypes.RoundMetricEmitter); ok && len(auctionResults.SuccessfulLRPs) > 0 {
+		metricEmitter.ZoneSkew(zoneSkew(zones, auctionResults.SuccessfulLRPs))
+	}
+	a.emitFailureMetrics(auctionResults)
+	lrpScores, taskScores := scheduler.WinningScores()
+	a.audit(logger, auctionResults, lrpScores, taskScores)
+	a.captureRound(logger, capture, auctionResults)
+	a.metricEmitter.AuctionCompleted(auctionResults)
+	a.notifyDelegate(ctx, auctionResults)
+	return auctionResults
+}
+
+// streamFailures tells a streaming delegate about failures that never went
+// through the scheduler.
+func (a *auctionRunner) streamFailures(results auctiontypes.AuctionResults) {
+	delegate, ok := a.delegate.(auctiontypes.StreamingAuctionRunnerDelegate)
+	if !ok {
+		return
+	}
+	for i := range results.FailedLRPs {
+		delegate.LRPFailed(results.FailedLRPs[i])
+	}
+	for i := range results.FailedTasks {
+		delegate.TaskFailed(results.FailedTasks[i])
+	}
+}
 
-			cellCount := 0
-			for zone, cells := range zones {
-				logger.Info("zone-state", lager.Data{"zone": zone, "cell-count": len(cells)})
-				cellCount += len(cells)
+func (a *auctionRunner) audit(logger lager.Logger, results auctiontypes.AuctionResults, lrpScores, taskScores map[string]float64) {
+	if a.auditSink == nil {
+		return
+	}
+	records := NewAuditRecords(results, lrpScores, taskScores, a.clock.Now())
+	err := a.auditSink.Record(records)
+	if err != nil {
+		logger.Error("failed-to-record-audit", err, lager.Data{"records": len(records)})
+	}
+}
+
+// newRoundCapture snapshots the inputs of a round when rounds are captured.
+func (a *auctionRunner) newRoundCapture(logger lager.Logger, zones map[string]Zone, auctionRequest auctiontypes.AuctionRequest) *RoundCapture {
+	if a.roundCapturer == nil {
+		return nil
+	}
+	capture, err := NewRoundCapture(a.clock.Now(), zones, auctionRequest, a.startingContainerWeight, a.startingContainerCountMaximum)
+	if err != nil {
+		logger.Error("failed-to-capture-round", err)
+		return nil
+	}
+	return &capture
+}
+
+func (a *auctionRunner) captureRound(logger lager.Logger, capture *RoundCapture, results auctiontypes.AuctionResults) {
+	if capture == nil {
+		return
+	}
+	capture.Results = results
+	err := a.roundCapturer.CaptureRound(*capture)
+	if err != nil {
+		logger.Error("failed-to-capture-round", err)
+	}
+}
+
+// drainWithdrawn collects the auctions that left the Batch without being
+// drained: cancelled ones, and ones dropped because the queue was full.
+func (a *auctionRunner) drainWithdrawn() auctiontypes.AuctionResults {
+	cancelledLRPs, cancelledTasks := a.batch.DrainCancelled()
+	droppedLRPs, droppedTasks := a.batch.DrainDropped()
+	return auctiontypes.AuctionResults{
+		FailedLRPs:     droppedLRPs,
+		FailedTasks:    droppedTasks,
+		CancelledLRPs:  cancelledLRPs,
+		CancelledTasks: cancelledTasks,
+	}
+}
+
+func (a *auctionRunner) emitBatchMetrics(lrpAuctions []auctiontypes.LRPAuction, taskAuctions []auctiontypes.TaskAuction) {
+	metricEmitter, ok := a.metricEmitter.(auctiontypes.RoundMetricEmitter)
+	if !ok {
+		return
+	}
+
+	metricEmitter.BatchSize(len(lrpAuctions), len(taskAuctions))
+
+	now := a.clock.Now()
+	waitTimes := make([]time.Duration, 0, len(lrpAuctions)+len(taskAuctions))
+	for i := range lrpAuctions {
+		waitTimes = append(waitTimes, now.Sub(lrpAuctions[i].QueueTime))
+	}
+	for i := range taskAuctions {
+		waitTimes = append(waitTimes, now.Sub(taskAuctions[i].QueueTime))
+	}
+	metricEmitter.QueueWaitTimes(waitTimes)
+}
+
+func (a *auctionRunner) emitFailureMetrics(results auctiontypes.AuctionResults) {
+	metricEmitter, ok := a.metricEmitter.(auctiontypes.RoundMetricEmitter)
+	if !ok {
+		return
+	}
+
+	type failureCount struct{ lrps, tasks int }
+	failures := map[string]*failureCount{}
+	countFor := func(placementError string) *failureCount {
+		reason := auctiontypes.PlacementFailureReason(placementError)
+		if failures[reason] == nil {
+			failures[reason] = &failureCount{}
+		}
+		return failures[reason]
+	}
+
+	for i := range results.FailedLRPs {
+		countFor(results.FailedLRPs[i].PlacementError).lrps++
+	}
+	for i := range results.FailedTasks {
+		countFor(results.FailedTasks[i].PlacementError).tasks++
+	}
+
+	for reason, count := range failures {
+		metricEmitter.PlacementFailures(reason, count.lrps, count.tasks)
+	}
+	if count, ok := failures[auctiontypes.PlacementFailureReason(auctiontypes.ErrorExceededInflightCreation.Error())]; ok {
+		metricEmitter.InflightLimitRejections(count.lrps, count.tasks)
+	}
+}
+
+// zoneSkew returns the largest difference in instance counts between two
+// zones among the processes of the given LRPs.
+func zoneSkew(zones map[string]Zone, lrpAuctions []auctiontypes.LRPAuction) int {
+	skew := 0
+	seen := map[string]bool{}
+	for i := range lrpAuctions {
+		processGuid := lrpAuctions[i].ProcessGuid
+		if seen[processGuid] {
+			continue
+		}
+		seen[processGuid] = true
+
+		fewest, most := -1, 0
+		for _, lrpByZone := range accumulateZonesByInstances(zones, processGuid) {
+			if fewest == -1 || lrpByZone.Instances < fewest {
+				fewest = lrpByZone.Instances
 			}
-			logger.Info("fetched-zone-state", lager.Data{
-				"cell-state-count":    cellCount,
-				"num-failed-requests": len(clients) - cellCount,
-				"duration":            fetchStateDuration.String(),
-			})
-
-			logger.Info("fetching-auctions")
-			lrpAuctions, taskAuctions := a.batch.DedupeAndDrain()
-			logger.Info("fetched-auctions", lager.Data{
-				"lrp-start-auctions": len(lrpAuctions),
-				"task-auctions":      len(taskAuctions),
-			})
-			if len(lrpAuctions) == 0 && len(taskAuctions) == 0 {
-				logger.Info("nothing-to-auction")
-				break
+			if lrpByZone.Instances > most {
+				most = lrpByZone.Instances
 			}
+		}
+		if most-fewest > skew {
+			skew = most - fewest
+		}
+	}
+	return skew
+}
 
-			logger.Info("scheduling")
-			auctionRequest := auctiontypes.AuctionRequest{
-				LRPs:  lrpAuctions,
-				Tasks: taskAuctions,
-			}
+func (a *auctionRunner) emitQueueMetricsPeriodically(stop <-chan struct{}) {
+	ticker := a.clock.NewTicker(a.queueMetricsInterval)
+	defer ticker.Stop()
 
-			scheduler := NewScheduler(a.workPool, zones, a.clock, logger, a.startingContainerWeight, a.startingContainerCountMaximum, a.auctionType)
-			auctionResults := scheduler.Schedule(auctionRequest)
-			logger.Info("scheduled", lager.Data{
-				"successful-lrp-start-auctions": len(auctionResults.SuccessfulLRPs),
-				"successful-task-auctions":      len(auctionResults.SuccessfulTasks),
-				"failed-lrp-start-auctions":     len(auctionResults.FailedLRPs),
-				"failed-task-auctions":          len(auctionResults.FailedTasks),
-			})
-
-			a.metricEmitter.AuctionCompleted(auctionResults)
-			a.delegate.AuctionCompleted(auctionResults)
-		case <-signals:
-			return nil
+	for {
+		select {
+		case <-ticker.C():
+			a.emitQueueMetrics()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (a *auctionRunner) emitQueueMetrics() {
+	metricEmitter, ok := a.metricEmitter.(auctiontypes.QueueMetricEmitter)
+	if !ok {
+		return
+	}
+
+	pending := a.batch.Snapshot()
+	metricEmitter.QueueDepth(len(pending.LRPs), len(pending.Tasks))
+
+	var oldestQueueTime time.Time
+	for i := range pending.LRPs {
+		if oldestQueueTime.IsZero() || pending.LRPs[i].QueueTime.Before(oldestQueueTime) {
+			oldestQueueTime = pending.LRPs[i].QueueTime
 		}
 	}
+	for i := range pending.Tasks {
+		if oldestQueueTime.IsZero() || pending.Tasks[i].QueueTime.Before(oldestQueueTime) {
+			oldestQueueTime = pending.Tasks[i].QueueTime
+		}
+	}
+
+	var oldestAge time.Duration
+	if !oldestQueueTime.IsZero() {
+		oldestAge = a.clock.Since(oldestQueueTime)
+	}
+	metricEmitter.OldestQueuedAuctionAge(oldestAge)
+}
+
+func (a *auctionRunner) ScheduleLRPsForAuctions(lrpStarts []auctioneer.LRPStartRequest) error {
+	return a.batch.AddLRPStarts(lrpStarts)
+}
+
+func (a *auctionRunner) ScheduleTasksForAuctions(tasks []auctioneer.TaskStartRequest) error {
+	return a.batch.AddTasks(tasks)
+}
+
+func (a *auctionRunner) CancelLRPAuctions(processGuid string, indices []int) {
+	a.batch.CancelLRPAuctions(processGuid, indices)
 }
 
-func (a *auctionRunner) ScheduleLRPsForAuctions(lrpStarts []auctioneer.LRPStartRequest) {
-	a.batch.AddLRPStarts(lrpStarts)
+func (a *auctionRunner) CancelTaskAuctions(taskGuids []string) {
+	a.batch.CancelTaskAuctions(taskGuids)
 }
 
-func (a *auctionRunner) ScheduleTasksForAuctions(tasks []auctioneer.TaskStartRequest) {
-	a.batch.AddTasks(tasks)
+func (a *auctionRunner) PendingAuctions() auctiontypes.PendingAuctions {
+	return a.batch.Snapshot()
 }
@@ -0,0 +1,810 @@
+package auctionrunner_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontracing"
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
+	"code.cloudfoundry.org/workpool"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type contextDelegate struct {
+	*fakes.FakeAuctionRunnerDelegate
+	contexts chan context.Context
+}
+
+func (d *contextDelegate) FetchCellRepsContext(ctx context.Context) (map[string]rep.Client, error) {
+	d.contexts <- ctx
+	return d.FetchCellReps()
+}
+
+type recordingAuditSink struct {
+	records chan []auctionrunner.AuditRecord
+}
+
+func (s *recordingAuditSink) Record(records []auctionrunner.AuditRecord) error {
+	s.records <- records
+	return nil
+}
+
+type recordingRoundCapturer struct {
+	captures chan auctionrunner.RoundCapture
+}
+
+func (c *recordingRoundCapturer) CaptureRound(capture auctionrunner.RoundCapture) error {
+	c.captures <- capture
+	return nil
+}
+
+type streamingDelegate struct {
+	*fakes.FakeAuctionRunnerDelegate
+	*fakes.FakeAuctionListener
+}
+
+var _ = Describe("AuctionRunner", func() {
+	var (
+		clock          *fakeclock.FakeClock
+		delegate       *fakes.FakeAuctionRunnerDelegate
+		runnerDelegate auctiontypes.AuctionRunnerDelegate
+		metricEmitter  *fakeMetricEmitter
+		emitter        auctiontypes.AuctionMetricEmitterDelegate
+		workPool       *workpool.WorkPool
+		runnerOptions  []auctionrunner.RunnerOption
+
+		runner  auctiontypes.AuctionRunner
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		delegate = &fakes.FakeAuctionRunnerDelegate{}
+		delegate.FetchCellRepsReturns(map[string]rep.Client{}, nil)
+		runnerDelegate = delegate
+		metricEmitter = newFakeMetricEmitter()
+		emitter = metricEmitter
+		runnerOptions = nil
+
+		var err error
+		workPool, err = workpool.NewWorkPool(5)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		runner = auctionrunner.New(
+			logger,
+			runnerDelegate,
+			emitter,
+			clock,
+			workPool,
+			0.25,
+			5,
+			auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+			runnerOptions...,
+		)
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		workPool.Stop()
+	})
+
+	Describe("PendingAuctions", func() {
+		BeforeEach(func() {
+			runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+				auctionrunner.WithCoalescingWindow(time.Minute, 0),
+			))
+		})
+
+		It("returns the auctions waiting for the next round", func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "other-domain", "linux", 10, 10, 10),
+			})
+
+			pending := runner.PendingAuctions()
+			Expect(pending.LRPs).To(HaveLen(1))
+			Expect(pending.LRPs[0].ProcessGuid).To(Equal("pg-1"))
+			Expect(pending.LRPs[0].Domain).To(Equal("domain"))
+			Expect(pending.LRPs[0].QueueTime).To(Equal(clock.Now()))
+			Expect(pending.LRPs[0].Attempts).To(Equal(0))
+			Expect(pending.Tasks).To(HaveLen(1))
+			Expect(pending.Tasks[0].TaskGuid).To(Equal("tg-1"))
+			Expect(pending.Tasks[0].Domain).To(Equal("other-domain"))
+		})
+	})
+
+	Describe("queue metrics", func() {
+		It("emits the queue depth and oldest auction age at the start of each round", func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+
+			Eventually(metricEmitter.QueueDepthCallCount).Should(Equal(1))
+			lrps, tasks := metricEmitter.QueueDepthArgsForCall(0)
+			Expect(lrps).To(Equal(2))
+			Expect(tasks).To(Equal(0))
+			Expect(metricEmitter.OldestQueuedAuctionAgeCallCount()).To(Equal(1))
+			Expect(metricEmitter.OldestQueuedAuctionAgeArgsForCall(0)).To(BeZero())
+		})
+
+		Context("when a queue metrics interval is configured", func() {
+			BeforeEach(func() {
+				runnerOptions = append(runnerOptions,
+					auctionrunner.WithQueueMetricsInterval(time.Second),
+					auctionrunner.WithBatchOptions(auctionrunner.WithCoalescingWindow(time.Minute, 0)),
+				)
+			})
+
+			It("emits the queue metrics periodically while work is waiting", func() {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				})
+
+				clock.WaitForNWatchersAndIncrement(time.Second, 2)
+				Eventually(metricEmitter.QueueDepthCallCount).Should(Equal(1))
+				lrps, tasks := metricEmitter.QueueDepthArgsForCall(0)
+				Expect(lrps).To(Equal(0))
+				Expect(tasks).To(Equal(1))
+				Eventually(metricEmitter.OldestQueuedAuctionAgeCallCount).Should(Equal(1))
+				Expect(metricEmitter.OldestQueuedAuctionAgeArgsForCall(0)).To(Equal(time.Second))
+			})
+		})
+	})
+
+	Describe("round metrics", func() {
+		BeforeEach(func() {
+			runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+				auctionrunner.WithCoalescingWindow(time.Minute, 0),
+			))
+		})
+
+		JustBeforeEach(func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0, 1, 2, 3, 4, 5}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+			})
+			clock.WaitForWatcherAndIncrement(30 * time.Second)
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", linuxRootFSURL, 10, 10, 10),
+			})
+			clock.Increment(time.Minute)
+			Eventually(metricEmitter.AuctionCompletedCallCount).Should(Equal(1))
+		})
+
+		It("emits the batch size and how long each auction waited", func() {
+			Expect(metricEmitter.BatchSizeCallCount()).To(Equal(1))
+			lrps, tasks := metricEmitter.BatchSizeArgsForCall(0)
+			Expect(lrps).To(Equal(6))
+			Expect(tasks).To(Equal(1))
+
+			Expect(metricEmitter.QueueWaitTimesCallCount()).To(Equal(1))
+			waitTimes := metricEmitter.QueueWaitTimesArgsForCall(0)
+			Expect(waitTimes).To(HaveLen(7))
+			Expect(waitTimes).To(ContainElement(90 * time.Second))
+			Expect(waitTimes).To(ContainElement(time.Minute))
+		})
+
+		Context("when there are no cells", func() {
+			It("emits the failures by reason", func() {
+				Expect(metricEmitter.PlacementFailuresCallCount()).To(Equal(1))
+				reason, lrps, tasks := metricEmitter.PlacementFailuresArgsForCall(0)
+				Expect(reason).To(Equal("cell-communication"))
+				Expect(lrps).To(Equal(6))
+				Expect(tasks).To(Equal(1))
+				Expect(metricEmitter.InflightLimitRejectionsCallCount()).To(Equal(0))
+				Expect(metricEmitter.ZoneSkewCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when cells in two zones accept the work", func() {
+			BeforeEach(func() {
+				clientA := &repfakes.FakeSimClient{}
+				clientA.StateReturns(BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+				clientB := &repfakes.FakeSimClient{}
+				clientB.StateReturns(BuildCellState("B", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, []rep.LRP{
+					*BuildLRP("pg-1", "domain", 9, linuxRootFSURL, 10, 10, 10, []string{}),
+				}, []string{}, []string{}, []string{}), nil)
+				delegate.FetchCellRepsReturns(map[string]rep.Client{"cell-a": clientA, "cell-b": clientB}, nil)
+			})
+
+			It("emits the in-flight limit rejections", func() {
+				Expect(metricEmitter.InflightLimitRejectionsCallCount()).To(Equal(1))
+				lrps, tasks := metricEmitter.InflightLimitRejectionsArgsForCall(0)
+				Expect(lrps + tasks).To(Equal(2))
+			})
+
+			It("emits the zone skew of the placed processes", func() {
+				Expect(metricEmitter.ZoneSkewCallCount()).To(Equal(1))
+				Expect(metricEmitter.ZoneSkewArgsForCall(0)).To(BeNumerically("<=", 1))
+			})
+
+			It("emits the number of cells whose state was fetched", func() {
+				Expect(metricEmitter.CellStatesFetchedCallCount()).To(Equal(1))
+				Expect(metricEmitter.CellStatesFetchedArgsForCall(0)).To(Equal(2))
+			})
+		})
+	})
+
+	Context("when the metric emitter implements none of the optional metric emitters", func() {
+		var baseEmitter *fakes.FakeAuctionMetricEmitterDelegate
+
+		BeforeEach(func() {
+			baseEmitter = &fakes.FakeAuctionMetricEmitterDelegate{}
+			emitter = baseEmitter
+		})
+
+		It("still runs rounds and emits the required metrics", func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+			})
+
+			Eventually(baseEmitter.AuctionCompletedCallCount).Should(Equal(1))
+			Expect(baseEmitter.FetchStatesCompletedCallCount()).To(Equal(1))
+			Expect(delegate.AuctionCompletedCallCount()).To(Equal(1))
+		})
+	})
+
+	Describe("tracing", func() {
+		var recorder *auctiontracing.Recorder
+
+		roundEnded := func() bool {
+			rounds := recorder.SpansNamed("auction-round")
+			return len(rounds) > 0 && rounds[0].Ended
+		}
+
+		BeforeEach(func() {
+			recorder = auctiontracing.NewRecorder()
+			runnerOptions = append(runnerOptions, auctionrunner.WithTracer(recorder))
+		})
+
+		Context("when the round places work", func() {
+			BeforeEach(func() {
+				clientA := &repfakes.FakeSimClient{}
+				clientA.StateReturns(BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+				clientB := &repfakes.FakeSimClient{}
+				clientB.StateReturns(BuildCellState("B", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+				delegate.FetchCellRepsReturns(map[string]rep.Client{"cell-a": clientA, "cell-b": clientB}, nil)
+			})
+
+			It("traces every phase of the round beneath a round span", func() {
+				runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+				})
+				Eventually(roundEnded).Should(BeTrue())
+
+				roundSpan := recorder.SpansNamed("auction-round")[0]
+				Expect(roundSpan.ParentID).To(BeZero())
+				Expect(roundSpan.Attributes).To(HaveKeyWithValue("lrp-guids", []string{"pg-1.0"}))
+				Expect(roundSpan.Attributes).To(HaveKeyWithValue("task-guids", []string{}))
+
+				names := []string{}
+				for _, child := range recorder.Children(roundSpan.ID) {
+					Expect(child.Ended).To(BeTrue())
+					names = append(names, child.Name)
+				}
+				Expect(names).To(ConsistOf("fetch-cell-reps", "fetch-cell-state", "fetch-cell-state", "schedule", "notify-delegate"))
+
+				scheduleSpan := recorder.SpansNamed("schedule")[0]
+				Expect(scheduleSpan.Attributes).To(HaveKeyWithValue("lrp-guids", []string{"pg-1.0"}))
+
+				commitSpans := recorder.Children(scheduleSpan.ID)
+				Expect(commitSpans).To(HaveLen(1))
+				Expect(commitSpans[0].Name).To(Equal("commit"))
+				Expect(commitSpans[0].Attributes).To(HaveKeyWithValue("cell-guid", BeElementOf("cell-a", "cell-b")))
+				Expect(commitSpans[0].Attributes).To(HaveKeyWithValue("lrp-guids", []string{"pg-1.0"}))
+			})
+		})
+
+		Context("when fetching the cell reps fails", func() {
+			BeforeEach(func() {
+				delegate.FetchCellRepsReturns(nil, errors.New("boom"))
+			})
+
+			It("records the error on the round", func() {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", linuxRootFSURL, 10, 10, 10),
+				})
+				Eventually(roundEnded).Should(BeTrue())
+
+				roundSpan := recorder.SpansNamed("auction-round")[0]
+				Expect(roundSpan.Errors).To(ConsistOf(MatchError("boom")))
+				Expect(recorder.SpansNamed("fetch-cell-reps")[0].Errors).To(ConsistOf(MatchError("boom")))
+				Expect(recorder.SpansNamed("schedule")).To(BeEmpty())
+			})
+		})
+
+		Context("when the round only withdraws auctions", func() {
+			var roundEndedAtCompletion chan bool
+
+			BeforeEach(func() {
+				roundEndedAtCompletion = make(chan bool, 1)
+				delegate.AuctionCompletedStub = func(auctiontypes.AuctionResults) {
+					roundEndedAtCompletion <- roundEnded()
+				}
+				runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+					auctionrunner.WithCoalescingWindow(time.Minute, 0),
+				))
+			})
+
+			It("notifies the delegate before ending the round", func() {
+				Expect(runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+				})).To(Succeed())
+				runner.CancelLRPAuctions("pg-1", []int{0})
+				clock.WaitForWatcherAndIncrement(time.Minute)
+
+				Eventually(roundEndedAtCompletion).Should(Receive(BeFalse()))
+				Eventually(roundEnded).Should(BeTrue())
+
+				roundSpan := recorder.SpansNamed("auction-round")[0]
+				notifySpans := recorder.SpansNamed("notify-delegate")
+				Expect(notifySpans).To(HaveLen(1))
+				Expect(notifySpans[0].ParentID).To(Equal(roundSpan.ID))
+				Expect(notifySpans[0].Ended).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("auditing", func() {
+		var sink *recordingAuditSink
+
+		BeforeEach(func() {
+			sink = &recordingAuditSink{records: make(chan []auctionrunner.AuditRecord, 10)}
+			runnerOptions = append(runnerOptions, auctionrunner.WithAuditSink(sink))
+
+			clientA := &repfakes.FakeSimClient{}
+			clientA.StateReturns(BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+			delegate.FetchCellRepsReturns(map[string]rep.Client{"cell-a": clientA}, nil)
+		})
+
+		It("records the outcome of every auction in the round", func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+			})
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", windowsRootFSURL, 10, 10, 10),
+			})
+
+			var records []auctionrunner.AuditRecord
+			Eventually(func() int {
+				select {
+				case batch := <-sink.records:
+					records = append(records, batch...)
+				default:
+				}
+				return len(records)
+			}).Should(Equal(2))
+
+			placed, failed := records[0], records[1]
+			if placed.Outcome != auctionrunner.AuditOutcomeSuccessful {
+				placed, failed = failed, placed
+			}
+
+			Expect(placed.Type).To(Equal(auctionrunner.AuditTypeLRP))
+			Expect(placed.Guid).To(Equal("pg-1"))
+			Expect(placed.Winner).To(Equal("cell-a"))
+			Expect(placed.Score).NotTo(BeNil())
+			Expect(placed.Attempts).To(Equal(1))
+
+			Expect(failed.Type).To(Equal(auctionrunner.AuditTypeTask))
+			Expect(failed.Outcome).To(Equal(auctionrunner.AuditOutcomeFailed))
+			Expect(failed.Guid).To(Equal("tg-1"))
+			Expect(failed.Error).To(Equal(auctiontypes.ErrorCellMismatch.Error()))
+			Expect(failed.Score).To(BeNil())
+		})
+	})
+
+	Describe("capturing rounds", func() {
+		var capturer *recordingRoundCapturer
+
+		BeforeEach(func() {
+			capturer = &recordingRoundCapturer{captures: make(chan auctionrunner.RoundCapture, 10)}
+			runnerOptions = append(runnerOptions, auctionrunner.WithRoundCapturer(capturer))
+
+			clientA := &repfakes.FakeSimClient{}
+			clientA.StateReturns(BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+			delegate.FetchCellRepsReturns(map[string]rep.Client{"cell-a": clientA}, nil)
+		})
+
+		It("captures the cell states, request and results of each round", func() {
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, linuxRootFSURL, 10, 10, 10, []string{}, []string{}),
+			})
+
+			var capture auctionrunner.RoundCapture
+			Eventually(capturer.captures).Should(Receive(&capture))
+
+			Expect(capture.Time).To(Equal(clock.Now()))
+			Expect(capture.StartingContainerWeight).To(Equal(0.25))
+			Expect(capture.StartingContainerCountMaximum).To(Equal(5))
+			Expect(capture.Cells).To(HaveLen(1))
+			Expect(capture.Cells[0].Guid).To(Equal("cell-a"))
+			Expect(capture.Cells[0].State.LRPs).To(BeEmpty())
+			Expect(capture.Request.LRPs).To(HaveLen(1))
+			Expect(capture.Request.LRPs[0].Winner).To(BeEmpty())
+			Expect(capture.Results.SuccessfulLRPs).To(HaveLen(1))
+			Expect(capture.Results.SuccessfulLRPs[0].Winner).To(Equal("cell-a"))
+		})
+	})
+
+	Describe("bounded queue", func() {
+		Context("when new work is rejected", func() {
+			BeforeEach(func() {
+				runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+					auctionrunner.WithMaxQueueSize(1, auctionrunner.RejectNewWork),
+					auctionrunner.WithCoalescingWindow(time.Minute, 0),
+				))
+			})
+
+			It("returns the back-pressure error to the caller", func() {
+				Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				})).To(Succeed())
+
+				err := runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				Expect(err).To(Equal(auctiontypes.ErrorAuctionQueueFull))
+			})
+		})
+
+		Context("when the oldest work is dropped", func() {
+			BeforeEach(func() {
+				runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+					auctionrunner.WithMaxQueueSize(1, auctionrunner.DropOldest),
+					auctionrunner.WithCoalescingWindow(time.Minute, 0),
+				))
+			})
+
+			It("reports the dropped auctions as failed", func() {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				})
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+				})
+
+				clock.WaitForWatcherAndIncrement(time.Minute)
+				Eventually(delegate.AuctionCompletedCallCount).Should(Equal(1))
+
+				results := delegate.AuctionCompletedArgsForCall(0)
+				Expect(results.FailedTasks).To(HaveLen(2))
+				Expect(results.FailedTasks[1].TaskGuid).To(Equal("tg-1"))
+				Expect(results.FailedTasks[1].PlacementError).To(Equal(auctiontypes.ErrorAuctionQueueFull.Error()))
+			})
+		})
+	})
+
+	Describe("shutting down", func() {
+		var (
+			client       *repfakes.FakeSimClient
+			stateBlocker chan struct{}
+		)
+
+		BeforeEach(func() {
+			stateBlocker = make(chan struct{})
+			client = &repfakes.FakeSimClient{}
+			client.StateStub = func(lager.Logger) (rep.CellState, error) {
+				<-stateBlocker
+				return BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil
+			}
+			delegate.FetchCellRepsReturns(map[string]rep.Client{"cell": client}, nil)
+		})
+
+		AfterEach(func() {
+			close(stateBlocker)
+		})
+
+		It("aborts a round waiting on cell states and leaves its work queued", func() {
+			Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})).To(Succeed())
+			Eventually(client.StateCallCount).Should(Equal(1))
+
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+
+			Expect(delegate.AuctionCompletedCallCount()).To(Equal(0))
+			Expect(runner.PendingAuctions().Tasks).To(HaveLen(1))
+		})
+	})
+
+	Describe("shutting down gracefully", func() {
+		BeforeEach(func() {
+			runnerOptions = append(runnerOptions, auctionrunner.WithGracefulShutdown())
+		})
+
+		Context("when work is queued", func() {
+			BeforeEach(func() {
+				runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+					auctionrunner.WithCoalescingWindow(time.Minute, 0),
+				))
+			})
+
+			It("reports the queued auctions as failed and refuses new work", func() {
+				Expect(runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+				})).To(Succeed())
+				runner.CancelLRPAuctions("pg-1", []int{0})
+				Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				})).To(Succeed())
+
+				process.Signal(os.Interrupt)
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+
+				Expect(delegate.AuctionCompletedCallCount()).To(Equal(1))
+				results := delegate.AuctionCompletedArgsForCall(0)
+				Expect(results.CancelledLRPs).To(HaveLen(1))
+				Expect(results.FailedTasks).To(HaveLen(1))
+				Expect(results.FailedTasks[0].TaskGuid).To(Equal("tg-1"))
+				Expect(results.FailedTasks[0].Attempts).To(Equal(0))
+				Expect(results.FailedTasks[0].PlacementError).To(Equal(auctiontypes.ErrorAuctioneerShuttingDown.Error()))
+
+				err := runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+				})
+				Expect(err).To(Equal(auctiontypes.ErrorAuctioneerShuttingDown))
+			})
+		})
+
+		Context("when a round is in progress", func() {
+			var (
+				client       *repfakes.FakeSimClient
+				stateBlocker chan struct{}
+			)
+
+			BeforeEach(func() {
+				stateBlocker = make(chan struct{})
+				client = &repfakes.FakeSimClient{}
+				client.StateStub = func(lager.Logger) (rep.CellState, error) {
+					<-stateBlocker
+					return BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil
+				}
+				delegate.FetchCellRepsReturns(map[string]rep.Client{"cell": client}, nil)
+
+				runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+					auctionrunner.WithMaxBatchSize(1),
+				))
+			})
+
+			It("finishes the round before handing back the rest of the queue", func() {
+				Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", linuxRootFSURL, 10, 10, 10),
+					BuildTaskStartRequest("tg-2", "domain", linuxRootFSURL, 10, 10, 10),
+				})).To(Succeed())
+				Eventually(client.StateCallCount).Should(Equal(1))
+
+				process.Signal(os.Interrupt)
+				Consistently(process.Wait()).ShouldNot(Receive())
+
+				close(stateBlocker)
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+
+				Expect(delegate.AuctionCompletedCallCount()).To(Equal(2))
+				results := delegate.AuctionCompletedArgsForCall(0)
+				Expect(results.SuccessfulTasks).To(HaveLen(1))
+				Expect(results.SuccessfulTasks[0].TaskGuid).To(Equal("tg-1"))
+
+				results = delegate.AuctionCompletedArgsForCall(1)
+				Expect(results.FailedTasks).To(HaveLen(1))
+				Expect(results.FailedTasks[0].TaskGuid).To(Equal("tg-2"))
+				Expect(results.FailedTasks[0].PlacementError).To(Equal(auctiontypes.ErrorAuctioneerShuttingDown.Error()))
+			})
+		})
+	})
+
+	Context("when the delegate streams outcomes", func() {
+		var (
+			listener          *fakes.FakeAuctionListener
+			completedAtPlaced chan int
+		)
+
+		BeforeEach(func() {
+			client := &repfakes.FakeSimClient{}
+			client.StateReturns(BuildCellState("A", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+			delegate.FetchCellRepsReturns(map[string]rep.Client{"cell": client}, nil)
+
+			completedAtPlaced = make(chan int, 1)
+			listener = &fakes.FakeAuctionListener{}
+			listener.TaskPlacedStub = func(auctiontypes.TaskAuction) {
+				completedAtPlaced <- delegate.AuctionCompletedCallCount()
+			}
+			runnerDelegate = &streamingDelegate{FakeAuctionRunnerDelegate: delegate, FakeAuctionListener: listener}
+		})
+
+		It("reports each placement before the round completes", func() {
+			Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", linuxRootFSURL, 10, 10, 10),
+			})).To(Succeed())
+
+			Eventually(completedAtPlaced).Should(Receive(Equal(0)))
+			Expect(listener.TaskPlacedArgsForCall(0).TaskGuid).To(Equal("tg-1"))
+			Eventually(delegate.AuctionCompletedCallCount).Should(Equal(1))
+		})
+	})
+
+	Describe("RunContext", func() {
+		It("returns once the context is cancelled", func() {
+			contextRunner := auctionrunner.New(
+				logger,
+				delegate,
+				metricEmitter,
+				clock,
+				workPool,
+				0.25,
+				5,
+				auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ready := make(chan struct{})
+			errs := make(chan error, 1)
+			go func() {
+				errs <- contextRunner.RunContext(ctx, ready)
+			}()
+			Eventually(ready).Should(BeClosed())
+
+			cancel()
+			Eventually(errs).Should(Receive(BeNil()))
+		})
+	})
+
+	Context("when the delegate accepts a context", func() {
+		var contexts chan context.Context
+
+		BeforeEach(func() {
+			contexts = make(chan context.Context, 10)
+			runnerDelegate = &contextDelegate{FakeAuctionRunnerDelegate: delegate, contexts: contexts}
+			runnerOptions = append(runnerOptions, auctionrunner.WithRoundTimeout(time.Minute))
+		})
+
+		It("fetches the cell reps with the round's deadline", func() {
+			Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})).To(Succeed())
+
+			var ctx context.Context
+			Eventually(contexts).Should(Receive(&ctx))
+			_, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeTrue())
+			Expect(delegate.FetchCellRepsCallCount()).To(Equal(1))
+		})
+	})
+
+	Describe("durable queue", func() {
+		var (
+			tmpDir    string
+			storePath string
+			store     *auctionrunner.FileAuctionStore
+			queuedAt  time.Time
+		)
+
+		newStore := func() *auctionrunner.FileAuctionStore {
+			store, err := auctionrunner.NewFileAuctionStore(logger, storePath)
+			Expect(err).NotTo(HaveOccurred())
+			return store
+		}
+
+		restart := func() {
+			process.Signal(os.Kill)
+			Eventually(process.Wait()).Should(Receive())
+			Expect(store.Close()).To(Succeed())
+
+			store = newStore()
+			runner = auctionrunner.New(
+				logger,
+				delegate,
+				metricEmitter,
+				clock,
+				workPool,
+				0.25,
+				5,
+				auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+				auctionrunner.WithBatchOptions(
+					auctionrunner.WithAuctionStore(store),
+					auctionrunner.WithCoalescingWindow(time.Minute, 0),
+				),
+			)
+			process = ifrit.Invoke(runner)
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "auction-runner-store")
+			Expect(err).NotTo(HaveOccurred())
+			storePath = filepath.Join(tmpDir, "auctions.log")
+			store = newStore()
+
+			runnerOptions = append(runnerOptions, auctionrunner.WithBatchOptions(
+				auctionrunner.WithAuctionStore(store),
+				auctionrunner.WithCoalescingWindow(time.Minute, 0),
+			))
+		})
+
+		JustBeforeEach(func() {
+			queuedAt = clock.Now()
+			Expect(runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+			})).To(Succeed())
+			Expect(runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})).To(Succeed())
+			clock.Increment(10 * time.Second)
+		})
+
+		AfterEach(func() {
+			store.Close()
+			os.RemoveAll(tmpDir)
+		})
+
+		It("replays queued auctions after a restart", func() {
+			restart()
+
+			pending := runner.PendingAuctions()
+			Expect(pending.LRPs).To(HaveLen(1))
+			Expect(pending.LRPs[0].ProcessGuid).To(Equal("pg-1"))
+			Expect(pending.LRPs[0].QueueTime).To(BeTemporally("==", queuedAt))
+			Expect(pending.Tasks).To(HaveLen(1))
+			Expect(pending.Tasks[0].TaskGuid).To(Equal("tg-1"))
+
+			clock.WaitForWatcherAndIncrement(time.Minute)
+			Eventually(delegate.AuctionCompletedCallCount).Should(Equal(1))
+			results := delegate.AuctionCompletedArgsForCall(0)
+			Expect(results.FailedLRPs).To(HaveLen(1))
+			Expect(results.FailedLRPs[0].QueueTime).To(BeTemporally("==", queuedAt))
+			Expect(results.FailedTasks).To(HaveLen(1))
+		})
+
+		Context("when shutting down gracefully", func() {
+			BeforeEach(func() {
+				runnerOptions = append(runnerOptions, auctionrunner.WithGracefulShutdown())
+			})
+
+			It("replays the auctions it handed back after a restart", func() {
+				process.Signal(os.Interrupt)
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+				Expect(delegate.AuctionCompletedCallCount()).To(Equal(1))
+
+				restart()
+
+				pending := runner.PendingAuctions()
+				Expect(pending.LRPs).To(HaveLen(1))
+				Expect(pending.LRPs[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(pending.Tasks).To(HaveLen(1))
+				Expect(pending.Tasks[0].TaskGuid).To(Equal("tg-1"))
+			})
+		})
+
+		It("does not replay auctions that were already drained", func() {
+			clock.WaitForWatcherAndIncrement(time.Minute)
+			Eventually(delegate.AuctionCompletedCallCount).Should(Equal(1))
+
+			restart()
+
+			pending := runner.PendingAuctions()
+			Expect(pending.LRPs).To(BeEmpty())
+			Expect(pending.Tasks).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,246 @@
+package auctionrunner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/lager"
+)
+
+// AuctionStore persists the auctions waiting in a Batch so they can be
+// replayed after a restart.
+type AuctionStore interface {
+	Add(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) error
+	// Remove forgets every stored auction sharing an identifier with the given
+	// ones.
+	Remove(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) error
+	Load() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction, error)
+}
+
+const (
+	addRecord    = "add"
+	removeRecord = "remove"
+)
+
+type auctionStoreRecord struct {
+	Op    string                     `json:"op"`
+	LRPs  []auctiontypes.LRPAuction  `json:"lrps,omitempty"`
+	Tasks []auctiontypes.TaskAuction `json:"tasks,omitempty"`
+}
+
+// FileAuctionStore is an AuctionStore backed by an append-only file of JSON
+// records. The file is compacted every time it is loaded.
+type FileAuctionStore struct {
+	logger lager.Logger
+	path   string
+	lock   *sync.Mutex
+	file   *os.File
+}
+
+func NewFileAuctionStore(logger lager.Logger, path string) (*FileAuctionStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAuctionStore{
+		logger: logger.Session("file-auction-store", lager.Data{"path": path}),
+		path:   path,
+		lock:   &sync.Mutex{},
+		file:   file,
+	}, nil
+}
+
+func (s *FileAuctionStore) Add(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) error {
+	if len(lrps) == 0 && len(tasks) == 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.append(auctionStoreRecord{Op: addRecord, LRPs: lrps, Tasks: tasks})
+}
+
+func (s *FileAuctionStore) Remove(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) error {
+	if len(lrps) == 0 && len(tasks) == 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.append(auctionStoreRecord{Op: removeRecord, LRPs: lrps, Tasks: tasks})
+	if err != nil {
+		s.logger.Error("failed-to-remove-auctions", err, lager.Data{"lrps": len(lrps), "tasks": len(tasks)})
+	}
+	return err
+}
+
+func (s *FileAuctionStore) Load() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	lrps := newStoredAuctions()
+	tasks := newStoredAuctions()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var record auctionStoreRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.logger.Error("ignoring-unreadable-records", err)
+			break
+		}
+
+		switch record.Op {
+		case addRecord:
+			for i := range record.LRPs {
+				lrps.add(record.LRPs[i].Identifier(), record.LRPs[i])
+			}
+			for i := range record.Tasks {
+				tasks.add(record.Tasks[i].Identifier(), record.Tasks[i])
+			}
+		case removeRecord:
+			for i := range record.LRPs {
+				lrps.remove(record.LRPs[i].Identifier())
+			}
+			for i := range record.Tasks {
+				tasks.remove(record.Tasks[i].Identifier())
+			}
+		}
+	}
+
+	compacted := auctionStoreRecord{Op: addRecord}
+	for _, auction := range lrps.remaining() {
+		compacted.LRPs = append(compacted.LRPs, auction.(auctiontypes.LRPAuction))
+	}
+	for _, auction := range tasks.remaining() {
+		compacted.Tasks = append(compacted.Tasks, auction.(auctiontypes.TaskAuction))
+	}
+
+	err = s.compact(compacted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("loaded-auctions", lager.Data{"lrps": len(compacted.LRPs), "tasks": len(compacted.Tasks)})
+	return compacted.LRPs, compacted.Tasks, nil
+}
+
+func (s *FileAuctionStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileAuctionStore) append(record auctionStoreRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.file.Write(append(payload, '\n'))
+	if err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+// compact replaces the file with a single record holding the given auctions.
+func (s *FileAuctionStore) compact(record auctionStoreRecord) error {
+	tmpPath := s.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if len(record.LRPs) > 0 || len(record.Tasks) > 0 {
+		payload, err := json.Marshal(record)
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+
+		_, err = tmpFile.Write(append(payload, '\n'))
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+
+	err = tmpFile.Sync()
+	if err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, s.path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	s.file.Close()
+	s.file = file
+	return nil
+}
+
+// storedAuctions replays add and remove records, keeping the surviving
+// auctions in the order they were added.
+type storedAuctions struct {
+	auctions    []interface{}
+	removed     []bool
+	identifiers map[string][]int
+}
+
+func newStoredAuctions() *storedAuctions {
+	return &storedAuctions{identifiers: map[string][]int{}}
+}
+
+func (s *storedAuctions) add(identifier string, auction interface{}) {
+	s.identifiers[identifier] = append(s.identifiers[identifier], len(s.auctions))
+	s.auctions = append(s.auctions, auction)
+	s.removed = append(s.removed, false)
+}
+
+func (s *storedAuctions) remove(identifier string) {
+	for _, i := range s.identifiers[identifier] {
+		s.removed[i] = true
+	}
+	delete(s.identifiers, identifier)
+}
+
+func (s *storedAuctions) remaining() []interface{} {
+	remaining := []interface{}{}
+	for i, auction := range s.auctions {
+		if !s.removed[i] {
+			remaining = append(remaining, auction)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,144 @@
+package auctionrunner_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileAuctionStore", func() {
+	var (
+		tmpDir    string
+		storePath string
+		store     *auctionrunner.FileAuctionStore
+		queueTime time.Time
+
+		lrpAuction  auctiontypes.LRPAuction
+		taskAuction auctiontypes.TaskAuction
+	)
+
+	reopen := func() {
+		Expect(store.Close()).To(Succeed())
+
+		var err error
+		store, err = auctionrunner.NewFileAuctionStore(logger, storePath)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "auction-store")
+		Expect(err).NotTo(HaveOccurred())
+		storePath = filepath.Join(tmpDir, "auctions.log")
+
+		store, err = auctionrunner.NewFileAuctionStore(logger, storePath)
+		Expect(err).NotTo(HaveOccurred())
+
+		queueTime = time.Unix(1500000000, 0)
+		lrpAuction = BuildLRPAuction("pg-1", "domain", 1, "linux", 10, 10, 10, queueTime, []string{"driver-1"}, []string{"tag-1"})
+		lrpAuction.Attempts = 2
+		taskAuction = BuildTaskAuction(BuildTask("tg-1", "domain", "linux", 10, 10, 10, []string{}, []string{}), queueTime)
+	})
+
+	AfterEach(func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("starts off empty", func() {
+		lrpAuctions, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lrpAuctions).To(BeEmpty())
+		Expect(taskAuctions).To(BeEmpty())
+	})
+
+	It("loads added auctions after being reopened", func() {
+		Expect(store.Add([]auctiontypes.LRPAuction{lrpAuction}, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		reopen()
+
+		lrpAuctions, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lrpAuctions).To(HaveLen(1))
+		Expect(lrpAuctions[0].LRP).To(Equal(lrpAuction.LRP))
+		Expect(lrpAuctions[0].Attempts).To(Equal(2))
+		Expect(lrpAuctions[0].QueueTime).To(BeTemporally("==", queueTime))
+		Expect(taskAuctions).To(HaveLen(1))
+		Expect(taskAuctions[0].Task).To(Equal(taskAuction.Task))
+		Expect(taskAuctions[0].QueueTime).To(BeTemporally("==", queueTime))
+	})
+
+	It("forgets every auction sharing an identifier with a removed one", func() {
+		otherLRPAuction := BuildLRPAuction("pg-1", "domain", 2, "linux", 10, 10, 10, queueTime, []string{}, []string{})
+		Expect(store.Add([]auctiontypes.LRPAuction{lrpAuction, otherLRPAuction, lrpAuction}, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		Expect(store.Remove([]auctiontypes.LRPAuction{lrpAuction}, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+
+		lrpAuctions, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lrpAuctions).To(HaveLen(1))
+		Expect(lrpAuctions[0].Index).To(BeEquivalentTo(2))
+		Expect(taskAuctions).To(BeEmpty())
+	})
+
+	It("keeps auctions added again after being removed", func() {
+		Expect(store.Add(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		Expect(store.Remove(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		Expect(store.Add(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+
+		_, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(taskAuctions).To(HaveLen(1))
+	})
+
+	It("fails to remove auctions it cannot write the removal of", func() {
+		Expect(store.Add(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		Expect(store.Close()).To(Succeed())
+
+		Expect(store.Remove(nil, []auctiontypes.TaskAuction{taskAuction})).NotTo(Succeed())
+	})
+
+	It("compacts the file when loading", func() {
+		Expect(store.Add([]auctiontypes.LRPAuction{lrpAuction}, nil)).To(Succeed())
+		Expect(store.Add(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		Expect(store.Remove(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+
+		_, _, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(storePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(string(contents), "\n")).To(Equal(1))
+
+		Expect(store.Add(nil, []auctiontypes.TaskAuction{taskAuction})).To(Succeed())
+		lrpAuctions, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lrpAuctions).To(HaveLen(1))
+		Expect(taskAuctions).To(HaveLen(1))
+	})
+
+	It("ignores a record that was only partially written", func() {
+		Expect(store.Add([]auctiontypes.LRPAuction{lrpAuction}, nil)).To(Succeed())
+		Expect(store.Close()).To(Succeed())
+
+		file, err := os.OpenFile(storePath, os.O_APPEND|os.O_WRONLY, 0600)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(`{"op":"add","tasks":[{"task_gu`)
+		Expect(err).NotTo(HaveOccurred())
+		file.Close()
+
+		store, err = auctionrunner.NewFileAuctionStore(logger, storePath)
+		Expect(err).NotTo(HaveOccurred())
+
+		lrpAuctions, taskAuctions, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lrpAuctions).To(HaveLen(1))
+		Expect(taskAuctions).To(BeEmpty())
+	})
+})
@@ -0,0 +1,244 @@
+package auctionrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+// AuditSink receives one AuditRecord for every auction outcome of a round.
+type AuditSink interface {
+	Record(records []AuditRecord) error
+}
+
+const (
+	AuditOutcomeSuccessful = "successful"
+	AuditOutcomeFailed     = "failed"
+	AuditOutcomeUnknown    = "unknown"
+	AuditOutcomeCancelled  = "cancelled"
+
+	AuditTypeLRP  = "lrp"
+	AuditTypeTask = "task"
+)
+
+type AuditRecord struct {
+	Time      time.Time      `json:"time"`
+	Type      string         `json:"type"`
+	Outcome   string         `json:"outcome"`
+	Guid      string         `json:"guid"`
+	Index     *int32         `json:"index,omitempty"`
+	Domain    string         `json:"domain"`
+	Resources AuditResources `json:"resources"`
+	Winner    string         `json:"winner,omitempty"`
+	// Score is the winning cell's bid, when the auction was placed. Lower is
+	// better.
+	Score     *float64      `json:"score,omitempty"`
+	Attempts  int           `json:"attempts"`
+	QueueTime time.Time     `json:"queue_time"`
+	Wait      time.Duration `json:"wait_ns"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type AuditResources struct {
+	MemoryMB int32 `json:"memory_mb"`
+	DiskMB   int32 `json:"disk_mb"`
+	MaxPids  int32 `json:"max_pids"`
+}
+
+// NewAuditRecords flattens results into one record per auction. The scores
+// are those returned by Scheduler.WinningScores and may be nil.
+func NewAuditRecords(results auctiontypes.AuctionResults, lrpScores, taskScores map[string]float64, now time.Time) []AuditRecord {
+	records := []AuditRecord{}
+	addLRPs := func(outcome string, lrps []auctiontypes.LRPAuction) {
+		for i := range lrps {
+			index := lrps[i].Index
+			record := AuditRecord{
+				Time:      now,
+				Type:      AuditTypeLRP,
+				Outcome:   outcome,
+				Guid:      lrps[i].ProcessGuid,
+				Index:     &index,
+				Domain:    lrps[i].Domain,
+				Resources: AuditResources{lrps[i].MemoryMB, lrps[i].DiskMB, lrps[i].MaxPids},
+				Winner:    lrps[i].Winner,
+				Attempts:  lrps[i].Attempts,
+				QueueTime: lrps[i].QueueTime,
+				Wait:      lrps[i].WaitDuration,
+				Error:     lrps[i].PlacementError,
+			}
+			if score, ok := lrpScores[lrps[i].Identifier()]; ok && outcome != AuditOutcomeFailed {
+				record.Score = &score
+			}
+			records = append(records, record)
+		}
+	}
+	addTasks := func(outcome string, tasks []auctiontypes.TaskAuction) {
+		for i := range tasks {
+			record := AuditRecord{
+				Time:      now,
+				Type:      AuditTypeTask,
+				Outcome:   outcome,
+				Guid:      tasks[i].TaskGuid,
+				Domain:    tasks[i].Domain,
+				Resources: AuditResources{tasks[i].MemoryMB, tasks[i].DiskMB, tasks[i].MaxPids},
+				Winner:    tasks[i].Winner,
+				Attempts:  tasks[i].Attempts,
+				QueueTime: tasks[i].QueueTime,
+				Wait:      tasks[i].WaitDuration,
+				Error:     tasks[i].PlacementError,
+			}
+			if score, ok := taskScores[tasks[i].Identifier()]; ok && outcome != AuditOutcomeFailed {
+				record.Score = &score
+			}
+			records = append(records, record)
+		}
+	}
+
+	addLRPs(AuditOutcomeSuccessful, results.SuccessfulLRPs)
+	addTasks(AuditOutcomeSuccessful, results.SuccessfulTasks)
+	addLRPs(AuditOutcomeFailed, results.FailedLRPs)
+	addTasks(AuditOutcomeFailed, results.FailedTasks)
+	addLRPs(AuditOutcomeUnknown, results.UnknownLRPs)
+	addTasks(AuditOutcomeUnknown, results.UnknownTasks)
+	addLRPs(AuditOutcomeCancelled, results.CancelledLRPs)
+	addTasks(AuditOutcomeCancelled, results.CancelledTasks)
+	return records
+}
+
+// FileAuditSink writes audit records to a file as JSON lines. Once the file
+// would grow beyond maxBytes it is renamed to path.1, path.1 to path.2 and
+// so on, keeping at most maxBackups old files. A maxBytes <= 0 never rotates.
+type FileAuditSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+	lock       *sync.Mutex
+	file       *os.File
+	size       int64
+}
+
+func NewFileAuditSink(path string, maxBytes int64, maxBackups int) (*FileAuditSink, error) {
+	s := &FileAuditSink{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+		lock:       &sync.Mutex{},
+	}
+
+	err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileAuditSink) Record(records []AuditRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// a file that fails to rotate keeps growing rather than losing records,
+	// and rotating is tried again on the next Record
+	var rotateErr error
+	for i := range records {
+		payload, err := json.Marshal(records[i])
+		if err != nil {
+			return err
+		}
+		payload = append(payload, '\n')
+
+		if rotateErr == nil && s.maxBytes > 0 && s.size > 0 && s.size+int64(len(payload)) > s.maxBytes {
+			rotateErr = s.rotate()
+		}
+
+		n, err := s.file.Write(payload)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return rotateErr
+}
+
+func (s *FileAuditSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileAuditSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate leaves the current file open until the new one is, so that a
+// failure keeps appending to it.
+func (s *FileAuditSink) rotate() error {
+	if s.maxBackups <= 0 {
+		return s.rotateWithoutBackups()
+	}
+
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(s.backupPath(i), s.backupPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	err := os.Rename(s.path, s.backupPath(1))
+	if err != nil {
+		return err
+	}
+
+	previous := s.file
+	err = s.open()
+	if err != nil {
+		return err
+	}
+	return previous.Close()
+}
+
+// rotateWithoutBackups only removes the full file once its replacement is
+// open, moving it back if that fails so that records are not appended to an
+// unlinked file.
+func (s *FileAuditSink) rotateWithoutBackups() error {
+	rotatingPath := s.path + ".rotating"
+	err := os.Rename(s.path, rotatingPath)
+	if err != nil {
+		return err
+	}
+
+	previous := s.file
+	err = s.open()
+	if err != nil {
+		if renameErr := os.Rename(rotatingPath, s.path); renameErr != nil {
+			return renameErr
+		}
+		return err
+	}
+
+	err = previous.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(rotatingPath)
+}
+
+func (s *FileAuditSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
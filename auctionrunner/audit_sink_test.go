@@ -0,0 +1,291 @@
+package auctionrunner_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit records", func() {
+	var (
+		queueTime   time.Time
+		now         time.Time
+		lrpAuction  auctiontypes.LRPAuction
+		taskAuction auctiontypes.TaskAuction
+	)
+
+	BeforeEach(func() {
+		queueTime = time.Unix(1500000000, 0).UTC()
+		now = queueTime.Add(time.Minute)
+
+		lrpAuction = BuildLRPAuction("pg-1", "domain", 2, "linux", 10, 20, 30, queueTime, []string{}, []string{})
+		lrpAuction.Winner = "A-cell"
+		lrpAuction.Attempts = 1
+		lrpAuction.WaitDuration = time.Minute
+
+		taskAuction = BuildTaskAuction(BuildTask("tg-1", "other-domain", "linux", 40, 50, 60, []string{}, []string{}), queueTime)
+		taskAuction.Attempts = 3
+		taskAuction.PlacementError = auctiontypes.ErrorCellMismatch.Error()
+	})
+
+	Describe("NewAuditRecords", func() {
+		It("creates a record for every auction outcome", func() {
+			cancelledTask := BuildTaskAuction(BuildTask("tg-2", "domain", "linux", 10, 10, 10, []string{}, []string{}), queueTime)
+			records := auctionrunner.NewAuditRecords(auctiontypes.AuctionResults{
+				SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction},
+				FailedTasks:    []auctiontypes.TaskAuction{taskAuction},
+				CancelledTasks: []auctiontypes.TaskAuction{cancelledTask},
+			}, map[string]float64{"pg-1.2": 0.75}, map[string]float64{"tg-1": 0.5}, now)
+
+			Expect(records).To(HaveLen(3))
+
+			index := int32(2)
+			score := 0.75
+			Expect(records[0]).To(Equal(auctionrunner.AuditRecord{
+				Time:      now,
+				Type:      auctionrunner.AuditTypeLRP,
+				Outcome:   auctionrunner.AuditOutcomeSuccessful,
+				Guid:      "pg-1",
+				Index:     &index,
+				Domain:    "domain",
+				Resources: auctionrunner.AuditResources{MemoryMB: 10, DiskMB: 20, MaxPids: 30},
+				Winner:    "A-cell",
+				Score:     &score,
+				Attempts:  1,
+				QueueTime: queueTime,
+				Wait:      time.Minute,
+			}))
+
+			Expect(records[1]).To(Equal(auctionrunner.AuditRecord{
+				Time:      now,
+				Type:      auctionrunner.AuditTypeTask,
+				Outcome:   auctionrunner.AuditOutcomeFailed,
+				Guid:      "tg-1",
+				Domain:    "other-domain",
+				Resources: auctionrunner.AuditResources{MemoryMB: 40, DiskMB: 50, MaxPids: 60},
+				Attempts:  3,
+				QueueTime: queueTime,
+				Error:     auctiontypes.ErrorCellMismatch.Error(),
+			}))
+
+			Expect(records[2].Outcome).To(Equal(auctionrunner.AuditOutcomeCancelled))
+			Expect(records[2].Guid).To(Equal("tg-2"))
+			Expect(records[2].Score).To(BeNil())
+		})
+	})
+
+	Describe("FileAuditSink", func() {
+		var (
+			tmpDir   string
+			sinkPath string
+			sink     *auctionrunner.FileAuditSink
+		)
+
+		readRecords := func(path string) []map[string]interface{} {
+			file, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			records := []map[string]interface{}{}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				record := map[string]interface{}{}
+				Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+				records = append(records, record)
+			}
+			Expect(scanner.Err()).NotTo(HaveOccurred())
+			return records
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "audit-sink")
+			Expect(err).NotTo(HaveOccurred())
+			sinkPath = filepath.Join(tmpDir, "audit.log")
+		})
+
+		AfterEach(func() {
+			if sink != nil {
+				sink.Close()
+			}
+			os.RemoveAll(tmpDir)
+		})
+
+		Context("without rotation", func() {
+			BeforeEach(func() {
+				var err error
+				sink, err = auctionrunner.NewFileAuditSink(sinkPath, 0, 0)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("writes one JSON record per line", func() {
+				records := auctionrunner.NewAuditRecords(auctiontypes.AuctionResults{
+					SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction},
+					FailedTasks:    []auctiontypes.TaskAuction{taskAuction},
+				}, map[string]float64{"pg-1.2": 0.75}, nil, now)
+				Expect(sink.Record(records)).To(Succeed())
+
+				written := readRecords(sinkPath)
+				Expect(written).To(HaveLen(2))
+				Expect(written[0]).To(HaveKeyWithValue("guid", "pg-1"))
+				Expect(written[0]).To(HaveKeyWithValue("index", BeNumerically("==", 2)))
+				Expect(written[0]).To(HaveKeyWithValue("outcome", "successful"))
+				Expect(written[0]).To(HaveKeyWithValue("winner", "A-cell"))
+				Expect(written[0]).To(HaveKeyWithValue("score", 0.75))
+				Expect(written[0]).To(HaveKeyWithValue("wait_ns", BeNumerically("==", time.Minute)))
+				Expect(written[0]["resources"]).To(Equal(map[string]interface{}{
+					"memory_mb": 10.0,
+					"disk_mb":   20.0,
+					"max_pids":  30.0,
+				}))
+				Expect(written[1]).To(HaveKeyWithValue("guid", "tg-1"))
+				Expect(written[1]).NotTo(HaveKey("index"))
+				Expect(written[1]).NotTo(HaveKey("score"))
+				Expect(written[1]).To(HaveKeyWithValue("error", auctiontypes.ErrorCellMismatch.Error()))
+			})
+
+			It("appends to an existing file", func() {
+				records := auctionrunner.NewAuditRecords(auctiontypes.AuctionResults{
+					SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction},
+				}, nil, nil, now)
+				Expect(sink.Record(records)).To(Succeed())
+				Expect(sink.Close()).To(Succeed())
+
+				var err error
+				sink, err = auctionrunner.NewFileAuditSink(sinkPath, 0, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink.Record(records)).To(Succeed())
+
+				Expect(readRecords(sinkPath)).To(HaveLen(2))
+			})
+		})
+
+		Context("with rotation", func() {
+			var records []auctionrunner.AuditRecord
+			var recordSize int64
+
+			BeforeEach(func() {
+				records = auctionrunner.NewAuditRecords(auctiontypes.AuctionResults{
+					SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction},
+				}, nil, nil, now)
+				payload, err := json.Marshal(records[0])
+				Expect(err).NotTo(HaveOccurred())
+				recordSize = int64(len(payload) + 1)
+
+				sink, err = auctionrunner.NewFileAuditSink(sinkPath, 2*recordSize, 2)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("moves full files aside, keeping the configured number of backups", func() {
+				for i := 0; i < 7; i++ {
+					Expect(sink.Record(records)).To(Succeed())
+				}
+
+				Expect(readRecords(sinkPath)).To(HaveLen(1))
+				Expect(readRecords(sinkPath + ".1")).To(HaveLen(2))
+				Expect(readRecords(sinkPath + ".2")).To(HaveLen(2))
+				Expect(sinkPath + ".3").NotTo(BeAnExistingFile())
+			})
+
+			It("accounts for what the file held when it was opened", func() {
+				Expect(sink.Record(records)).To(Succeed())
+				Expect(sink.Close()).To(Succeed())
+
+				var err error
+				sink, err = auctionrunner.NewFileAuditSink(sinkPath, 2*recordSize, 2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink.Record(append(records, records...))).To(Succeed())
+
+				Expect(readRecords(sinkPath)).To(HaveLen(1))
+				Expect(readRecords(sinkPath + ".1")).To(HaveLen(2))
+			})
+
+			Context("when a backup cannot be renamed", func() {
+				BeforeEach(func() {
+					for _, backup := range []string{sinkPath + ".1", sinkPath + ".2"} {
+						Expect(os.MkdirAll(filepath.Join(backup, "in-the-way"), 0700)).To(Succeed())
+					}
+				})
+
+				It("keeps appending to the current file", func() {
+					Expect(sink.Record(append(records, records...))).To(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+
+					Expect(readRecords(sinkPath)).To(HaveLen(4))
+				})
+
+				It("rotates once the backup can be renamed again", func() {
+					Expect(sink.Record(append(records, records...))).To(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+
+					Expect(os.RemoveAll(sinkPath + ".2")).To(Succeed())
+					Expect(os.RemoveAll(sinkPath + ".1")).To(Succeed())
+					Expect(sink.Record(records)).To(Succeed())
+
+					Expect(readRecords(sinkPath)).To(HaveLen(1))
+					Expect(readRecords(sinkPath + ".1")).To(HaveLen(3))
+				})
+			})
+		})
+
+		Context("with rotation but no backups", func() {
+			var records []auctionrunner.AuditRecord
+
+			BeforeEach(func() {
+				records = auctionrunner.NewAuditRecords(auctiontypes.AuctionResults{
+					SuccessfulLRPs: []auctiontypes.LRPAuction{lrpAuction},
+				}, nil, nil, now)
+				payload, err := json.Marshal(records[0])
+				Expect(err).NotTo(HaveOccurred())
+
+				sink, err = auctionrunner.NewFileAuditSink(sinkPath, 2*int64(len(payload)+1), 0)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("starts the file over once it is full", func() {
+				for i := 0; i < 5; i++ {
+					Expect(sink.Record(records)).To(Succeed())
+				}
+
+				Expect(readRecords(sinkPath)).To(HaveLen(1))
+				Expect(sinkPath + ".1").NotTo(BeAnExistingFile())
+				Expect(sinkPath + ".rotating").NotTo(BeAnExistingFile())
+			})
+
+			Context("when the full file cannot be moved aside", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(filepath.Join(sinkPath+".rotating", "in-the-way"), 0700)).To(Succeed())
+				})
+
+				It("keeps appending to the current file", func() {
+					Expect(sink.Record(append(records, records...))).To(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+
+					Expect(readRecords(sinkPath)).To(HaveLen(4))
+				})
+
+				It("starts the file over once it can be moved aside again", func() {
+					Expect(sink.Record(append(records, records...))).To(Succeed())
+					Expect(sink.Record(records)).NotTo(Succeed())
+
+					Expect(os.RemoveAll(sinkPath + ".rotating")).To(Succeed())
+					Expect(sink.Record(records)).To(Succeed())
+
+					Expect(readRecords(sinkPath)).To(HaveLen(1))
+					Expect(sinkPath + ".rotating").NotTo(BeAnExistingFile())
+				})
+			})
+		})
+	})
+})
@@ -1,7 +1,9 @@
 package auctionrunner
 
 import (
+	"sort"
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/auctioneer"
@@ -12,23 +14,140 @@ import (
 )
 
 type Batch struct {
-	lrpAuctions  []auctiontypes.LRPAuction
-	taskAuctions []auctiontypes.TaskAuction
-	lock         *sync.Mutex
-	HasWork      chan struct{}
-	clock        clock.Clock
+	lrpAuctions           []auctiontypes.LRPAuction
+	taskAuctions          []auctiontypes.TaskAuction
+	cancelledLRPAuctions  []auctiontypes.LRPAuction
+	cancelledTaskAuctions []auctiontypes.TaskAuction
+	droppedLRPAuctions    []auctiontypes.LRPAuction
+	droppedTaskAuctions   []auctiontypes.TaskAuction
+	lock                  *sync.Mutex
+	HasWork               chan struct{}
+	clock                 clock.Clock
+
+	minDelay      time.Duration
+	maxDelay      time.Duration
+	maxBatchSize  int
+	window        *batchWindow
+	mergePolicy   DedupeMergePolicy
+	metricEmitter auctiontypes.AuctionMetricEmitterDelegate
+
+	maxQueueSize   int
+	overflowPolicy OverflowPolicy
+	blockTimeout   time.Duration
+	spaceFreed     chan struct{}
+	closed         bool
+
+	store          AuctionStore
+	unremovedLRPs  []auctiontypes.LRPAuction
+	unremovedTasks []auctiontypes.TaskAuction
+}
+
+// OverflowPolicy decides what happens to new work when the Batch already
+// holds its maximum queue size.
+type OverflowPolicy int
+
+const (
+	// RejectNewWork fails the add with ErrorAuctionQueueFull.
+	RejectNewWork OverflowPolicy = iota
+	// DropOldest accepts the new work and drops the oldest queued auctions,
+	// which are returned by the next DrainDropped.
+	DropOldest
+	// BlockUntilSpace waits for a drain to make room, failing the add with
+	// ErrorAuctionQueueFull once the block timeout passes.
+	BlockUntilSpace
+)
+
+// DedupeMergePolicy controls how DedupeAndDrain combines auctions for the same
+// instance or task. The zero value keeps the first auction and drops the rest.
+type DedupeMergePolicy struct {
+	// LatestSpecWins takes the resources and placement constraints from the
+	// most recently added auction.
+	LatestSpecWins bool
+	// EarliestQueueTime keeps the earliest QueueTime of the merged auctions.
+	EarliestQueueTime bool
+	// MaxAttempts keeps the highest Attempts of the merged auctions.
+	MaxAttempts bool
+}
+
+type batchWindow struct {
+	openedAt      time.Time
+	lastArrivalAt time.Time
+}
+
+type BatchOption func(*Batch)
+
+// WithCoalescingWindow delays HasWork until no work has arrived for minDelay,
+// or until maxDelay has passed since the first unclaimed work arrived. A zero
+// maxDelay leaves the window uncapped.
+func WithCoalescingWindow(minDelay, maxDelay time.Duration) BatchOption {
+	return func(b *Batch) {
+		b.minDelay = minDelay
+		b.maxDelay = maxDelay
+	}
+}
+
+// WithMaxBatchSize limits the number of auctions returned by a single
+// DedupeAndDrain. The oldest auctions are drained first and the rest are kept
+// for the next round.
+func WithMaxBatchSize(maxBatchSize int) BatchOption {
+	return func(b *Batch) {
+		b.maxBatchSize = maxBatchSize
+	}
+}
+
+func WithDedupeMergePolicy(policy DedupeMergePolicy) BatchOption {
+	return func(b *Batch) {
+		b.mergePolicy = policy
+	}
+}
+
+// WithBatchMetricEmitter reports the number of duplicate auctions merged away
+// by each DedupeAndDrain.
+func WithBatchMetricEmitter(metricEmitter auctiontypes.AuctionMetricEmitterDelegate) BatchOption {
+	return func(b *Batch) {
+		b.metricEmitter = metricEmitter
+	}
 }
 
-func NewBatch(clock clock.Clock) *Batch {
-	return &Batch{
+// WithMaxQueueSize bounds the number of auctions waiting in the Batch.
+func WithMaxQueueSize(maxQueueSize int, policy OverflowPolicy) BatchOption {
+	return func(b *Batch) {
+		b.maxQueueSize = maxQueueSize
+		b.overflowPolicy = policy
+	}
+}
+
+// WithBlockTimeout limits how long BlockUntilSpace waits. A zero timeout
+// waits indefinitely.
+func WithBlockTimeout(timeout time.Duration) BatchOption {
+	return func(b *Batch) {
+		b.blockTimeout = timeout
+	}
+}
+
+// WithAuctionStore persists queued auctions to the given store until they
+// are drained, cancelled or dropped. Call Replay to restore them.
+func WithAuctionStore(store AuctionStore) BatchOption {
+	return func(b *Batch) {
+		b.store = store
+	}
+}
+
+func NewBatch(clock clock.Clock, options ...BatchOption) *Batch {
+	b := &Batch{
 		lrpAuctions: []auctiontypes.LRPAuction{},
 		lock:        &sync.Mutex{},
 		clock:       clock,
 		HasWork:     make(chan struct{}, 1),
+		spaceFreed:  make(chan struct{}),
 	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
 }
 
-func (b *Batch) AddLRPStarts(starts []auctioneer.LRPStartRequest) {
+func (b *Batch) AddLRPStarts(starts []auctioneer.LRPStartRequest) error {
 	auctions := make([]auctiontypes.LRPAuction, 0, len(starts))
 	now := b.clock.Now()
 	for i := range starts {
@@ -41,12 +160,25 @@ func (b *Batch) AddLRPStarts(starts []auctioneer.LRPStartRequest) {
 	}
 
 	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	err := b.waitForSpace(len(auctions))
+	if err != nil {
+		return err
+	}
+	if b.store != nil {
+		err = b.store.Add(auctions, nil)
+		if err != nil {
+			return err
+		}
+	}
 	b.lrpAuctions = append(b.lrpAuctions, auctions...)
-	b.claimToHaveWork()
-	b.lock.Unlock()
+	b.dropOverflow()
+	b.workArrived(now)
+	return nil
 }
 
-func (b *Batch) AddTasks(tasks []auctioneer.TaskStartRequest) {
+func (b *Batch) AddTasks(tasks []auctioneer.TaskStartRequest) error {
 	auctions := make([]auctiontypes.TaskAuction, 0, len(tasks))
 	now := b.clock.Now()
 	for i := range tasks {
@@ -54,48 +186,473 @@ func (b *Batch) AddTasks(tasks []auctioneer.TaskStartRequest) {
 	}
 
 	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	err := b.waitForSpace(len(auctions))
+	if err != nil {
+		return err
+	}
+	if b.store != nil {
+		err = b.store.Add(nil, auctions)
+		if err != nil {
+			return err
+		}
+	}
 	b.taskAuctions = append(b.taskAuctions, auctions...)
-	b.claimToHaveWork()
-	b.lock.Unlock()
+	b.dropOverflow()
+	b.workArrived(now)
+	return nil
+}
+
+// Replay restores the auctions left in the store by a previous process,
+// keeping their original QueueTime and Attempts.
+func (b *Batch) Replay() error {
+	if b.store == nil {
+		return nil
+	}
+
+	lrpAuctions, taskAuctions, err := b.store.Load()
+	if err != nil {
+		return err
+	}
+	if len(lrpAuctions) == 0 && len(taskAuctions) == 0 {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.lrpAuctions = append(lrpAuctions, b.lrpAuctions...)
+	b.taskAuctions = append(taskAuctions, b.taskAuctions...)
+	b.workArrived(b.clock.Now())
+	return nil
+}
+
+// CancelLRPAuctions withdraws the queued auctions for the given instances.
+// Withdrawn auctions are returned by the next DrainCancelled.
+func (b *Batch) CancelLRPAuctions(processGuid string, indices []int) {
+	cancelledIndices := map[int32]bool{}
+	for _, index := range indices {
+		cancelledIndices[int32(index)] = false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	previouslyCancelled := len(b.cancelledLRPAuctions)
+	remaining := make([]auctiontypes.LRPAuction, 0, len(b.lrpAuctions))
+	for _, auction := range b.lrpAuctions {
+		alreadyCancelled, ok := cancelledIndices[auction.Index]
+		if !ok || auction.ProcessGuid != processGuid {
+			remaining = append(remaining, auction)
+			continue
+		}
+		if !alreadyCancelled {
+			cancelledIndices[auction.Index] = true
+			auction.PlacementError = auctiontypes.ErrorAuctionCancelled.Error()
+			b.cancelledLRPAuctions = append(b.cancelledLRPAuctions, auction)
+		}
+	}
+	b.lrpAuctions = remaining
+	b.removeFromStore(b.cancelledLRPAuctions[previouslyCancelled:], nil)
+	b.freeSpace()
+}
+
+// CancelTaskAuctions withdraws the queued auctions for the given tasks.
+// Withdrawn auctions are returned by the next DrainCancelled.
+func (b *Batch) CancelTaskAuctions(taskGuids []string) {
+	cancelledGuids := map[string]bool{}
+	for _, guid := range taskGuids {
+		cancelledGuids[guid] = false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	previouslyCancelled := len(b.cancelledTaskAuctions)
+	remaining := make([]auctiontypes.TaskAuction, 0, len(b.taskAuctions))
+	for _, auction := range b.taskAuctions {
+		alreadyCancelled, ok := cancelledGuids[auction.TaskGuid]
+		if !ok {
+			remaining = append(remaining, auction)
+			continue
+		}
+		if !alreadyCancelled {
+			cancelledGuids[auction.TaskGuid] = true
+			auction.PlacementError = auctiontypes.ErrorAuctionCancelled.Error()
+			b.cancelledTaskAuctions = append(b.cancelledTaskAuctions, auction)
+		}
+	}
+	b.taskAuctions = remaining
+	b.removeFromStore(nil, b.cancelledTaskAuctions[previouslyCancelled:])
+	b.freeSpace()
+}
+
+func (b *Batch) DrainCancelled() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	lrpAuctions := b.cancelledLRPAuctions
+	taskAuctions := b.cancelledTaskAuctions
+	b.cancelledLRPAuctions = nil
+	b.cancelledTaskAuctions = nil
+	return lrpAuctions, taskAuctions
+}
+
+// Snapshot returns a copy of the auctions waiting to be drained.
+func (b *Batch) Snapshot() auctiontypes.PendingAuctions {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	pending := auctiontypes.PendingAuctions{
+		LRPs:  make([]auctiontypes.LRPAuction, 0, len(b.lrpAuctions)),
+		Tasks: make([]auctiontypes.TaskAuction, 0, len(b.taskAuctions)),
+	}
+	for i := range b.lrpAuctions {
+		pending.LRPs = append(pending.LRPs, b.lrpAuctions[i].Copy())
+	}
+	for i := range b.taskAuctions {
+		pending.Tasks = append(pending.Tasks, b.taskAuctions[i].Copy())
+	}
+	return pending
+}
+
+// DrainDropped returns the auctions dropped by the DropOldest overflow policy
+// since the last call.
+func (b *Batch) DrainDropped() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	lrpAuctions := b.droppedLRPAuctions
+	taskAuctions := b.droppedTaskAuctions
+	b.droppedLRPAuctions = nil
+	b.droppedTaskAuctions = nil
+	return lrpAuctions, taskAuctions
+}
+
+// Close stops the Batch from accepting work. Adding work afterwards, or
+// while blocked waiting for space, fails with ErrorAuctioneerShuttingDown.
+func (b *Batch) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.closed = true
+	b.freeSpace()
 }
 
 func (b *Batch) DedupeAndDrain() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	return b.dedupeAndDrain(b.maxBatchSize, true)
+}
+
+// DrainAll is DedupeAndDrain without the maximum batch size. The drained
+// auctions stay in the auction store, so that they are replayed on restart.
+func (b *Batch) DrainAll() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	return b.dedupeAndDrain(0, false)
+}
+
+func (b *Batch) dedupeAndDrain(maxBatchSize int, removeFromStore bool) ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
 	b.lock.Lock()
 	lrpAuctions := b.lrpAuctions
 	taskAuctions := b.taskAuctions
 	b.lrpAuctions = []auctiontypes.LRPAuction{}
 	b.taskAuctions = []auctiontypes.TaskAuction{}
+	b.window = nil
+	b.freeSpace()
 	select {
 	case <-b.HasWork:
 	default:
 	}
-	b.lock.Unlock()
 
 	dedupedLRPAuctions := []auctiontypes.LRPAuction{}
-	presentLRPAuctions := map[string]bool{}
+	presentLRPAuctions := map[string]int{}
 	for _, startAuction := range lrpAuctions {
 		id := startAuction.Identifier()
-		if presentLRPAuctions[id] {
+		if i, ok := presentLRPAuctions[id]; ok {
+			if b.mergePolicy.LatestSpecWins {
+				dedupedLRPAuctions[i].LRP = startAuction.LRP
+			}
+			b.mergeRecords(&dedupedLRPAuctions[i].AuctionRecord, startAuction.AuctionRecord)
 			continue
 		}
-		presentLRPAuctions[id] = true
+		presentLRPAuctions[id] = len(dedupedLRPAuctions)
 		dedupedLRPAuctions = append(dedupedLRPAuctions, startAuction)
 	}
 
 	dedupedTaskAuctions := []auctiontypes.TaskAuction{}
-	presentTaskAuctions := map[string]bool{}
+	presentTaskAuctions := map[string]int{}
 	for _, taskAuction := range taskAuctions {
 		id := taskAuction.Identifier()
-		if presentTaskAuctions[id] {
+		if i, ok := presentTaskAuctions[id]; ok {
+			if b.mergePolicy.LatestSpecWins {
+				dedupedTaskAuctions[i].Task = taskAuction.Task
+			}
+			b.mergeRecords(&dedupedTaskAuctions[i].AuctionRecord, taskAuction.AuctionRecord)
 			continue
 		}
-		presentTaskAuctions[id] = true
+		presentTaskAuctions[id] = len(dedupedTaskAuctions)
 		dedupedTaskAuctions = append(dedupedTaskAuctions, taskAuction)
 	}
 
+	duplicateLRPs := len(lrpAuctions) - len(dedupedLRPAuctions)
+	duplicateTasks := len(taskAuctions) - len(dedupedTaskAuctions)
+
+	if maxBatchSize > 0 && len(dedupedLRPAuctions)+len(dedupedTaskAuctions) > maxBatchSize {
+		var overflowLRPAuctions []auctiontypes.LRPAuction
+		var overflowTaskAuctions []auctiontypes.TaskAuction
+		dedupedLRPAuctions, dedupedTaskAuctions, overflowLRPAuctions, overflowTaskAuctions = splitOldest(maxBatchSize, dedupedLRPAuctions, dedupedTaskAuctions)
+
+		b.lrpAuctions = overflowLRPAuctions
+		b.taskAuctions = overflowTaskAuctions
+		b.claimToHaveWork()
+	}
+
+	if removeFromStore {
+		b.removeFromStore(dedupedLRPAuctions, dedupedTaskAuctions)
+	}
+	b.lock.Unlock()
+
+	if metricEmitter, ok := b.metricEmitter.(auctiontypes.QueueMetricEmitter); ok && (duplicateLRPs > 0 || duplicateTasks > 0) {
+		metricEmitter.AuctionsDeduplicated(duplicateLRPs, duplicateTasks)
+	}
+
 	return dedupedLRPAuctions, dedupedTaskAuctions
 }
 
+func (b *Batch) mergeRecords(merged *auctiontypes.AuctionRecord, duplicate auctiontypes.AuctionRecord) {
+	if b.mergePolicy.EarliestQueueTime && duplicate.QueueTime.Before(merged.QueueTime) {
+		merged.QueueTime = duplicate.QueueTime
+	}
+	if b.mergePolicy.MaxAttempts && duplicate.Attempts > merged.Attempts {
+		merged.Attempts = duplicate.Attempts
+	}
+}
+
+// splitOldest takes the maxBatchSize oldest auctions across both lists. The
+// lists are not necessarily in QueueTime order, since merging duplicates can
+// move an auction's QueueTime earlier, so the auctions are compared directly.
+// Both the drained auctions and the overflow keep their order in the queue.
+func splitOldest(
+	maxBatchSize int,
+	lrpAuctions []auctiontypes.LRPAuction,
+	taskAuctions []auctiontypes.TaskAuction,
+) ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction, []auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+	type queued struct {
+		isTask    bool
+		index     int
+		queueTime time.Time
+	}
+	oldest := make([]queued, 0, len(lrpAuctions)+len(taskAuctions))
+	for i := range lrpAuctions {
+		oldest = append(oldest, queued{index: i, queueTime: lrpAuctions[i].QueueTime})
+	}
+	for i := range taskAuctions {
+		oldest = append(oldest, queued{isTask: true, index: i, queueTime: taskAuctions[i].QueueTime})
+	}
+	sort.SliceStable(oldest, func(i, j int) bool {
+		return oldest[i].queueTime.Before(oldest[j].queueTime)
+	})
+
+	drainedLRPs := make([]bool, len(lrpAuctions))
+	drainedTasks := make([]bool, len(taskAuctions))
+	for _, auction := range oldest[:maxBatchSize] {
+		if auction.isTask {
+			drainedTasks[auction.index] = true
+		} else {
+			drainedLRPs[auction.index] = true
+		}
+	}
+
+	lrps, overflowLRPs := []auctiontypes.LRPAuction{}, []auctiontypes.LRPAuction{}
+	for i := range lrpAuctions {
+		if drainedLRPs[i] {
+			lrps = append(lrps, lrpAuctions[i])
+		} else {
+			overflowLRPs = append(overflowLRPs, lrpAuctions[i])
+		}
+	}
+	tasks, overflowTasks := []auctiontypes.TaskAuction{}, []auctiontypes.TaskAuction{}
+	for i := range taskAuctions {
+		if drainedTasks[i] {
+			tasks = append(tasks, taskAuctions[i])
+		} else {
+			overflowTasks = append(overflowTasks, taskAuctions[i])
+		}
+	}
+	return lrps, tasks, overflowLRPs, overflowTasks
+}
+
+// waitForSpace must be called with the lock held. The BlockUntilSpace policy
+// releases the lock while it waits.
+func (b *Batch) waitForSpace(count int) error {
+	if b.closed {
+		return auctiontypes.ErrorAuctioneerShuttingDown
+	}
+	if b.maxQueueSize <= 0 || b.overflowPolicy == DropOldest || b.fits(count) {
+		return nil
+	}
+	if b.overflowPolicy == RejectNewWork || count > b.maxQueueSize {
+		return auctiontypes.ErrorAuctionQueueFull
+	}
+
+	var timeout <-chan time.Time
+	if b.blockTimeout > 0 {
+		timer := b.clock.NewTimer(b.blockTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	for !b.fits(count) {
+		spaceFreed := b.spaceFreed
+		b.lock.Unlock()
+		select {
+		case <-spaceFreed:
+			b.lock.Lock()
+			if b.closed {
+				return auctiontypes.ErrorAuctioneerShuttingDown
+			}
+		case <-timeout:
+			b.lock.Lock()
+			return auctiontypes.ErrorAuctionQueueFull
+		}
+	}
+	return nil
+}
+
+func (b *Batch) fits(count int) bool {
+	return len(b.lrpAuctions)+len(b.taskAuctions)+count <= b.maxQueueSize
+}
+
+func (b *Batch) freeSpace() {
+	close(b.spaceFreed)
+	b.spaceFreed = make(chan struct{})
+}
+
+func (b *Batch) dropOverflow() {
+	if b.maxQueueSize <= 0 || b.overflowPolicy != DropOldest {
+		return
+	}
+
+	alreadyDroppedLRPs := len(b.droppedLRPAuctions)
+	alreadyDroppedTasks := len(b.droppedTaskAuctions)
+	for len(b.lrpAuctions)+len(b.taskAuctions) > b.maxQueueSize {
+		if len(b.taskAuctions) == 0 ||
+			(len(b.lrpAuctions) > 0 && !b.taskAuctions[0].QueueTime.Before(b.lrpAuctions[0].QueueTime)) {
+			dropped := b.lrpAuctions[0]
+			dropped.PlacementError = auctiontypes.ErrorAuctionQueueFull.Error()
+			b.droppedLRPAuctions = append(b.droppedLRPAuctions, dropped)
+			b.lrpAuctions = b.lrpAuctions[1:]
+		} else {
+			dropped := b.taskAuctions[0]
+			dropped.PlacementError = auctiontypes.ErrorAuctionQueueFull.Error()
+			b.droppedTaskAuctions = append(b.droppedTaskAuctions, dropped)
+			b.taskAuctions = b.taskAuctions[1:]
+		}
+	}
+
+	b.removeFromStore(b.unqueuedLRPs(b.droppedLRPAuctions[alreadyDroppedLRPs:]), b.unqueuedTasks(b.droppedTaskAuctions[alreadyDroppedTasks:]))
+}
+
+// removeFromStore must be called with the lock held. Auctions the store fails
+// to remove are removed along with the next ones instead, unless they have
+// been queued again in the meantime.
+func (b *Batch) removeFromStore(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) {
+	if b.store == nil {
+		return
+	}
+
+	lrps = append(b.unqueuedLRPs(b.unremovedLRPs), lrps...)
+	tasks = append(b.unqueuedTasks(b.unremovedTasks), tasks...)
+	err := b.store.Remove(lrps, tasks)
+	if err != nil {
+		b.unremovedLRPs = lrps
+		b.unremovedTasks = tasks
+		return
+	}
+	b.unremovedLRPs = nil
+	b.unremovedTasks = nil
+}
+
+// unqueuedLRPs filters out auctions that still have a duplicate in the queue,
+// since the store removes every auction sharing an identifier.
+func (b *Batch) unqueuedLRPs(auctions []auctiontypes.LRPAuction) []auctiontypes.LRPAuction {
+	queued := map[string]bool{}
+	for i := range b.lrpAuctions {
+		queued[b.lrpAuctions[i].Identifier()] = true
+	}
+
+	unqueued := []auctiontypes.LRPAuction{}
+	for i := range auctions {
+		if !queued[auctions[i].Identifier()] {
+			unqueued = append(unqueued, auctions[i])
+		}
+	}
+	return unqueued
+}
+
+func (b *Batch) unqueuedTasks(auctions []auctiontypes.TaskAuction) []auctiontypes.TaskAuction {
+	queued := map[string]bool{}
+	for i := range b.taskAuctions {
+		queued[b.taskAuctions[i].Identifier()] = true
+	}
+
+	unqueued := []auctiontypes.TaskAuction{}
+	for i := range auctions {
+		if !queued[auctions[i].Identifier()] {
+			unqueued = append(unqueued, auctions[i])
+		}
+	}
+	return unqueued
+}
+
+func (b *Batch) workArrived(now time.Time) {
+	if b.minDelay <= 0 {
+		b.claimToHaveWork()
+		return
+	}
+
+	if b.window != nil {
+		b.window.lastArrivalAt = now
+		return
+	}
+
+	b.window = &batchWindow{openedAt: now, lastArrivalAt: now}
+	go b.waitForWindow(b.window, b.clock.NewTimer(b.windowRemaining(now)))
+}
+
+func (b *Batch) waitForWindow(window *batchWindow, timer clock.Timer) {
+	for {
+		now := <-timer.C()
+
+		b.lock.Lock()
+		if b.window != window {
+			b.lock.Unlock()
+			return
+		}
+
+		remaining := b.windowRemaining(now)
+		if remaining <= 0 {
+			b.window = nil
+			b.claimToHaveWork()
+			b.lock.Unlock()
+			return
+		}
+
+		timer.Reset(remaining)
+		b.lock.Unlock()
+	}
+}
+
+func (b *Batch) windowRemaining(now time.Time) time.Duration {
+	closesAt := b.window.lastArrivalAt.Add(b.minDelay)
+	if b.maxDelay > 0 {
+		deadline := b.window.openedAt.Add(b.maxDelay)
+		if deadline.Before(closesAt) {
+			closesAt = deadline
+		}
+	}
+	return closesAt.Sub(now)
+}
+
 func (b *Batch) claimToHaveWork() {
 	select {
 	case b.HasWork <- struct{}{}:
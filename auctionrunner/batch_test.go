@@ -1,6 +1,10 @@
 package auctionrunner_test
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/auction/auctionrunner"
@@ -115,4 +119,683 @@ var _ = Describe("Batch", func() {
 			Expect(batch.HasWork).NotTo(Receive())
 		})
 	})
+
+	Describe("coalescing window", func() {
+		BeforeEach(func() {
+			batch = auctionrunner.NewBatch(clock, auctionrunner.WithCoalescingWindow(100*time.Millisecond, time.Second))
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+		})
+
+		It("does not have work until the minimum delay has passed", func() {
+			Consistently(batch.HasWork).ShouldNot(Receive())
+
+			clock.WaitForWatcherAndIncrement(100 * time.Millisecond)
+			Eventually(batch.HasWork).Should(Receive())
+		})
+
+		It("extends the window when more work arrives", func() {
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			clock.Increment(50 * time.Millisecond)
+			batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10)})
+
+			clock.Increment(50 * time.Millisecond)
+			Consistently(batch.HasWork).ShouldNot(Receive())
+
+			clock.WaitForWatcherAndIncrement(50 * time.Millisecond)
+			Eventually(batch.HasWork).Should(Receive())
+
+			lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+			Expect(lrpAuctions).To(HaveLen(1))
+			Expect(taskAuctions).To(HaveLen(1))
+		})
+
+		It("does not extend the window past the maximum delay", func() {
+			for i := 0; i < 10; i++ {
+				clock.WaitForWatcherAndIncrement(90 * time.Millisecond)
+				batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10)})
+			}
+			Consistently(batch.HasWork).ShouldNot(Receive())
+
+			clock.WaitForWatcherAndIncrement(100 * time.Millisecond)
+			Eventually(batch.HasWork).Should(Receive())
+		})
+
+		It("does not claim work that was already drained", func() {
+			batch.DedupeAndDrain()
+
+			clock.Increment(100 * time.Millisecond)
+			Consistently(batch.HasWork).ShouldNot(Receive())
+		})
+	})
+
+	Describe("maximum batch size", func() {
+		BeforeEach(func() {
+			batch = auctionrunner.NewBatch(clock, auctionrunner.WithMaxBatchSize(3))
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			clock.Increment(time.Second)
+			batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10)})
+			clock.Increment(time.Second)
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-2", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10)})
+		})
+
+		It("drains the oldest auctions up to the maximum", func() {
+			lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+			Expect(lrpAuctions).To(HaveLen(2))
+			Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+			Expect(lrpAuctions[1].ProcessGuid).To(Equal("pg-1"))
+			Expect(taskAuctions).To(HaveLen(1))
+			Expect(taskAuctions[0].TaskGuid).To(Equal("tg-1"))
+		})
+
+		It("keeps the overflow for the next round", func() {
+			batch.DedupeAndDrain()
+			Expect(batch.HasWork).To(Receive())
+
+			lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+			Expect(lrpAuctions).To(HaveLen(1))
+			Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-2"))
+			Expect(taskAuctions).To(HaveLen(1))
+			Expect(taskAuctions[0].TaskGuid).To(Equal("tg-2"))
+			Expect(batch.HasWork).NotTo(Receive())
+		})
+
+		It("preserves the queue time of the overflow", func() {
+			batch.DedupeAndDrain()
+			lrpAuctions, _ := batch.DedupeAndDrain()
+			Expect(lrpAuctions[0].QueueTime).To(Equal(clock.Now()))
+		})
+
+		It("is ignored when draining everything", func() {
+			lrpAuctions, taskAuctions := batch.DrainAll()
+			Expect(lrpAuctions).To(HaveLen(3))
+			Expect(taskAuctions).To(HaveLen(2))
+			Expect(batch.HasWork).NotTo(Receive())
+		})
+	})
+
+	Describe("closing", func() {
+		BeforeEach(func() {
+			batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10)})
+			batch.Close()
+		})
+
+		It("refuses new work", func() {
+			err := batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			Expect(err).To(Equal(auctiontypes.ErrorAuctioneerShuttingDown))
+
+			err = batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10)})
+			Expect(err).To(Equal(auctiontypes.ErrorAuctioneerShuttingDown))
+		})
+
+		It("keeps the work queued before it was closed", func() {
+			_, taskAuctions := batch.DrainAll()
+			Expect(taskAuctions).To(HaveLen(1))
+			Expect(taskAuctions[0].TaskGuid).To(Equal("tg-1"))
+		})
+	})
+
+	Describe("cancelling auctions", func() {
+		BeforeEach(func() {
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0, 1, 2}, "linux", 10, 10, 10, []string{}, []string{}),
+				BuildLRPStartRequest("pg-1", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{}),
+				BuildLRPStartRequest("pg-2", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+			})
+		})
+
+		It("should start off with nothing cancelled", func() {
+			lrpAuctions, taskAuctions := batch.DrainCancelled()
+			Expect(lrpAuctions).To(BeEmpty())
+			Expect(taskAuctions).To(BeEmpty())
+		})
+
+		Context("when lrp auctions are cancelled", func() {
+			BeforeEach(func() {
+				batch.CancelLRPAuctions("pg-1", []int{1, 2, 5})
+			})
+
+			It("removes every queued auction for the cancelled instances", func() {
+				lrpAuctions, _ := batch.DedupeAndDrain()
+				Expect(lrpAuctions).To(ConsistOf(
+					BuildLRPAuction("pg-1", "domain", 0, "linux", 10, 10, 10, clock.Now(), []string{}, []string{}),
+					BuildLRPAuction("pg-2", "domain", 1, "linux", 10, 10, 10, clock.Now(), []string{}, []string{}),
+				))
+			})
+
+			It("reports each cancelled instance once", func() {
+				lrpAuctions, taskAuctions := batch.DrainCancelled()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(lrpAuctions[0].Index).To(BeEquivalentTo(1))
+				Expect(lrpAuctions[1].Index).To(BeEquivalentTo(2))
+				Expect(lrpAuctions[0].PlacementError).To(Equal(auctiontypes.ErrorAuctionCancelled.Error()))
+				Expect(taskAuctions).To(BeEmpty())
+			})
+
+			It("clears out the cancelled auctions once drained", func() {
+				batch.DrainCancelled()
+				lrpAuctions, _ := batch.DrainCancelled()
+				Expect(lrpAuctions).To(BeEmpty())
+			})
+		})
+
+		Context("when task auctions are cancelled", func() {
+			BeforeEach(func() {
+				batch.CancelTaskAuctions([]string{"tg-2", "tg-3"})
+			})
+
+			It("removes the queued auctions", func() {
+				_, taskAuctions := batch.DedupeAndDrain()
+				Expect(taskAuctions).To(HaveLen(1))
+				Expect(taskAuctions[0].TaskGuid).To(Equal("tg-1"))
+			})
+
+			It("reports them as cancelled", func() {
+				lrpAuctions, taskAuctions := batch.DrainCancelled()
+				Expect(lrpAuctions).To(BeEmpty())
+				Expect(taskAuctions).To(HaveLen(1))
+				Expect(taskAuctions[0].TaskGuid).To(Equal("tg-2"))
+				Expect(taskAuctions[0].PlacementError).To(Equal(auctiontypes.ErrorAuctionCancelled.Error()))
+			})
+		})
+
+		Context("when the auctions have already been drained", func() {
+			It("does not report anything as cancelled", func() {
+				batch.DedupeAndDrain()
+				batch.CancelLRPAuctions("pg-1", []int{0})
+				batch.CancelTaskAuctions([]string{"tg-1"})
+
+				lrpAuctions, taskAuctions := batch.DrainCancelled()
+				Expect(lrpAuctions).To(BeEmpty())
+				Expect(taskAuctions).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Snapshot", func() {
+		BeforeEach(func() {
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})
+		})
+
+		It("returns the queued auctions without draining them", func() {
+			pending := batch.Snapshot()
+			Expect(pending.LRPs).To(ConsistOf(BuildLRPAuction("pg-1", "domain", 1, "linux", 10, 10, 10, clock.Now(), []string{}, []string{})))
+			Expect(pending.Tasks).To(HaveLen(1))
+			Expect(pending.Tasks[0].TaskGuid).To(Equal("tg-1"))
+
+			lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+			Expect(lrpAuctions).To(HaveLen(1))
+			Expect(taskAuctions).To(HaveLen(1))
+		})
+
+		It("returns copies of the queued auctions", func() {
+			pending := batch.Snapshot()
+			pending.LRPs[0].Attempts = 5
+
+			lrpAuctions, _ := batch.DedupeAndDrain()
+			Expect(lrpAuctions[0].Attempts).To(Equal(0))
+		})
+	})
+
+	Describe("merging duplicates", func() {
+		var metricEmitter *fakeMetricEmitter
+		var firstQueueTime time.Time
+
+		addDuplicates := func() {
+			firstQueueTime = clock.Now()
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{"tag-1"}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})
+			clock.Increment(time.Second)
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{1}, "linux", 20, 20, 20, []string{}, []string{"tag-2"}),
+				BuildLRPStartRequest("pg-2", "domain", []int{1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 20, 20, 20),
+			})
+		}
+
+		BeforeEach(func() {
+			metricEmitter = newFakeMetricEmitter()
+		})
+
+		Context("with the default policy", func() {
+			BeforeEach(func() {
+				batch = auctionrunner.NewBatch(clock, auctionrunner.WithBatchMetricEmitter(metricEmitter))
+				addDuplicates()
+			})
+
+			It("keeps the first auction", func() {
+				lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(lrpAuctions[0].MemoryMB).To(BeEquivalentTo(10))
+				Expect(lrpAuctions[0].QueueTime).To(Equal(firstQueueTime))
+				Expect(taskAuctions).To(HaveLen(1))
+				Expect(taskAuctions[0].MemoryMB).To(BeEquivalentTo(10))
+			})
+
+			It("counts the deduplicated auctions", func() {
+				batch.DedupeAndDrain()
+				Expect(metricEmitter.AuctionsDeduplicatedCallCount()).To(Equal(1))
+				lrps, tasks := metricEmitter.AuctionsDeduplicatedArgsForCall(0)
+				Expect(lrps).To(Equal(1))
+				Expect(tasks).To(Equal(1))
+			})
+		})
+
+		Context("when the latest spec wins and the earliest queue time is kept", func() {
+			BeforeEach(func() {
+				batch = auctionrunner.NewBatch(clock,
+					auctionrunner.WithBatchMetricEmitter(metricEmitter),
+					auctionrunner.WithDedupeMergePolicy(auctionrunner.DedupeMergePolicy{
+						LatestSpecWins:    true,
+						EarliestQueueTime: true,
+						MaxAttempts:       true,
+					}),
+				)
+				addDuplicates()
+			})
+
+			It("takes the spec of the latest lrp auction and the queue time of the first", func() {
+				lrpAuctions, _ := batch.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(lrpAuctions[0].MemoryMB).To(BeEquivalentTo(20))
+				Expect(lrpAuctions[0].PlacementConstraint.PlacementTags).To(ConsistOf("tag-2"))
+				Expect(lrpAuctions[0].QueueTime).To(Equal(firstQueueTime))
+				Expect(lrpAuctions[1].ProcessGuid).To(Equal("pg-2"))
+			})
+
+			It("takes the spec of the latest task auction and the queue time of the first", func() {
+				_, taskAuctions := batch.DedupeAndDrain()
+				Expect(taskAuctions).To(HaveLen(1))
+				Expect(taskAuctions[0].MemoryMB).To(BeEquivalentTo(20))
+				Expect(taskAuctions[0].QueueTime).To(Equal(firstQueueTime))
+			})
+
+			It("counts the deduplicated auctions", func() {
+				batch.DedupeAndDrain()
+				Expect(metricEmitter.AuctionsDeduplicatedCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when there are no duplicates", func() {
+			BeforeEach(func() {
+				batch = auctionrunner.NewBatch(clock, auctionrunner.WithBatchMetricEmitter(metricEmitter))
+				batch.AddTasks([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+				})
+			})
+
+			It("does not emit the metric", func() {
+				batch.DedupeAndDrain()
+				Expect(metricEmitter.AuctionsDeduplicatedCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("bounded queue", func() {
+		Context("when new work is rejected", func() {
+			BeforeEach(func() {
+				batch = auctionrunner.NewBatch(clock, auctionrunner.WithMaxQueueSize(3, auctionrunner.RejectNewWork))
+				err := batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("accepts work that fits", func() {
+				err := batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10)})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("rejects work that does not fit", func() {
+				err := batch.AddTasks([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+					BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+				})
+				Expect(err).To(Equal(auctiontypes.ErrorAuctionQueueFull))
+
+				lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(taskAuctions).To(BeEmpty())
+			})
+
+			It("accepts work again once drained", func() {
+				batch.DedupeAndDrain()
+				err := batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-2", "domain", []int{0, 1, 2}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the oldest work is dropped", func() {
+			BeforeEach(func() {
+				batch = auctionrunner.NewBatch(clock, auctionrunner.WithMaxQueueSize(3, auctionrunner.DropOldest))
+				batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				clock.Increment(time.Second)
+				err := batch.AddTasks([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+					BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("keeps the newest work", func() {
+				lrpAuctions, taskAuctions := batch.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(1))
+				Expect(lrpAuctions[0].Index).To(BeEquivalentTo(1))
+				Expect(taskAuctions).To(HaveLen(2))
+			})
+
+			It("returns the dropped auctions", func() {
+				lrpAuctions, taskAuctions := batch.DrainDropped()
+				Expect(lrpAuctions).To(HaveLen(1))
+				Expect(lrpAuctions[0].Index).To(BeEquivalentTo(0))
+				Expect(lrpAuctions[0].PlacementError).To(Equal(auctiontypes.ErrorAuctionQueueFull.Error()))
+				Expect(taskAuctions).To(BeEmpty())
+
+				lrpAuctions, _ = batch.DrainDropped()
+				Expect(lrpAuctions).To(BeEmpty())
+			})
+		})
+
+		Context("when adding blocks until there is space", func() {
+			var errs chan error
+
+			addTask := func(taskGuid string) {
+				go func(errs chan<- error) {
+					errs <- batch.AddTasks([]auctioneer.TaskStartRequest{BuildTaskStartRequest(taskGuid, "domain", "linux", 10, 10, 10)})
+				}(errs)
+			}
+
+			BeforeEach(func() {
+				errs = make(chan error, 1)
+				batch = auctionrunner.NewBatch(clock,
+					auctionrunner.WithMaxQueueSize(2, auctionrunner.BlockUntilSpace),
+					auctionrunner.WithBlockTimeout(time.Second),
+				)
+				batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+			})
+
+			It("adds the work once the batch is drained", func() {
+				addTask("tg-1")
+				Consistently(errs).ShouldNot(Receive())
+
+				batch.DedupeAndDrain()
+				Eventually(errs).Should(Receive(BeNil()))
+
+				_, taskAuctions := batch.DedupeAndDrain()
+				Expect(taskAuctions).To(HaveLen(1))
+			})
+
+			It("adds the work once queued work is cancelled", func() {
+				addTask("tg-1")
+				Consistently(errs).ShouldNot(Receive())
+
+				batch.CancelLRPAuctions("pg-1", []int{0})
+				Eventually(errs).Should(Receive(BeNil()))
+			})
+
+			It("gives up after the block timeout", func() {
+				addTask("tg-1")
+
+				clock.WaitForWatcherAndIncrement(time.Second)
+				Eventually(errs).Should(Receive(Equal(auctiontypes.ErrorAuctionQueueFull)))
+
+				_, taskAuctions := batch.DedupeAndDrain()
+				Expect(taskAuctions).To(BeEmpty())
+			})
+
+			It("gives up when the batch is closed", func() {
+				addTask("tg-1")
+				Consistently(errs).ShouldNot(Receive())
+
+				batch.Close()
+				Eventually(errs).Should(Receive(Equal(auctiontypes.ErrorAuctioneerShuttingDown)))
+			})
+
+			It("immediately rejects work that can never fit", func() {
+				err := batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-2", "domain", []int{0, 1, 2}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				Expect(err).To(Equal(auctiontypes.ErrorAuctionQueueFull))
+			})
+		})
+	})
+
+	Describe("with an auction store", func() {
+		var (
+			tmpDir    string
+			storePath string
+			store     *auctionrunner.FileAuctionStore
+		)
+
+		storedAuctions := func() ([]auctiontypes.LRPAuction, []auctiontypes.TaskAuction) {
+			otherStore, err := auctionrunner.NewFileAuctionStore(logger, storePath)
+			Expect(err).NotTo(HaveOccurred())
+			defer otherStore.Close()
+
+			lrpAuctions, taskAuctions, err := otherStore.Load()
+			Expect(err).NotTo(HaveOccurred())
+			return lrpAuctions, taskAuctions
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "batch-store")
+			Expect(err).NotTo(HaveOccurred())
+			storePath = filepath.Join(tmpDir, "auctions.log")
+
+			store, err = auctionrunner.NewFileAuctionStore(logger, storePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			batch = auctionrunner.NewBatch(clock, auctionrunner.WithAuctionStore(store))
+			batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-1", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			batch.AddTasks([]auctioneer.TaskStartRequest{
+				BuildTaskStartRequest("tg-1", "domain", "linux", 10, 10, 10),
+			})
+		})
+
+		AfterEach(func() {
+			store.Close()
+			os.RemoveAll(tmpDir)
+		})
+
+		It("stores queued auctions", func() {
+			lrpAuctions, taskAuctions := storedAuctions()
+			Expect(lrpAuctions).To(HaveLen(2))
+			Expect(taskAuctions).To(HaveLen(1))
+		})
+
+		It("forgets drained auctions", func() {
+			batch.DedupeAndDrain()
+
+			lrpAuctions, taskAuctions := storedAuctions()
+			Expect(lrpAuctions).To(BeEmpty())
+			Expect(taskAuctions).To(BeEmpty())
+		})
+
+		It("keeps the auctions drained by DrainAll", func() {
+			batch.DrainAll()
+
+			lrpAuctions, taskAuctions := storedAuctions()
+			Expect(lrpAuctions).To(HaveLen(2))
+			Expect(taskAuctions).To(HaveLen(1))
+		})
+
+		It("forgets cancelled auctions", func() {
+			batch.CancelLRPAuctions("pg-1", []int{0})
+			batch.CancelTaskAuctions([]string{"tg-1"})
+
+			lrpAuctions, taskAuctions := storedAuctions()
+			Expect(lrpAuctions).To(HaveLen(1))
+			Expect(lrpAuctions[0].Index).To(BeEquivalentTo(1))
+			Expect(taskAuctions).To(BeEmpty())
+		})
+
+		It("does not store work that was rejected", func() {
+			batch.DedupeAndDrain()
+
+			batch = auctionrunner.NewBatch(clock,
+				auctionrunner.WithAuctionStore(store),
+				auctionrunner.WithMaxQueueSize(1, auctionrunner.RejectNewWork),
+			)
+			err := batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+				BuildLRPStartRequest("pg-2", "domain", []int{0, 1}, "linux", 10, 10, 10, []string{}, []string{}),
+			})
+			Expect(err).To(Equal(auctiontypes.ErrorAuctionQueueFull))
+
+			lrpAuctions, _ := storedAuctions()
+			Expect(lrpAuctions).To(BeEmpty())
+		})
+
+		Context("when the store fails to remove auctions", func() {
+			var failingStore *failingRemoveStore
+
+			BeforeEach(func() {
+				failingStore = &failingRemoveStore{AuctionStore: store, failing: true}
+				batch = auctionrunner.NewBatch(clock, auctionrunner.WithAuctionStore(failingStore))
+				batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-2", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				batch.DedupeAndDrain()
+				failingStore.failing = false
+			})
+
+			It("removes them along with the next auctions it forgets", func() {
+				batch.AddTasks([]auctioneer.TaskStartRequest{
+					BuildTaskStartRequest("tg-2", "domain", "linux", 10, 10, 10),
+				})
+				batch.DedupeAndDrain()
+
+				lrpAuctions, taskAuctions := storedAuctions()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(taskAuctions).To(HaveLen(1))
+				Expect(taskAuctions[0].TaskGuid).To(Equal("tg-1"))
+			})
+
+			It("does not remove them once they are queued again", func() {
+				batch.AddLRPStarts([]auctioneer.LRPStartRequest{
+					BuildLRPStartRequest("pg-2", "domain", []int{0}, "linux", 10, 10, 10, []string{}, []string{}),
+				})
+				batch.CancelTaskAuctions([]string{"tg-2"})
+
+				lrpAuctions, _ := storedAuctions()
+				Expect(lrpAuctions).To(HaveLen(4))
+				Expect(lrpAuctions[3].ProcessGuid).To(Equal("pg-2"))
+			})
+		})
+
+		Context("when merging duplicates moves a queue time earlier", func() {
+			var replayed *auctionrunner.Batch
+			var earliestQueueTime time.Time
+
+			BeforeEach(func() {
+				earliestQueueTime = clock.Now().Add(-time.Minute)
+				late := BuildLRPAuction("pg-2", "domain", 0, "linux", 10, 10, 10, clock.Now().Add(time.Minute), []string{}, []string{})
+				early := BuildLRPAuction("pg-2", "domain", 0, "linux", 10, 10, 10, earliestQueueTime, []string{}, []string{})
+				Expect(store.Add([]auctiontypes.LRPAuction{late, early}, nil)).To(Succeed())
+
+				replayed = auctionrunner.NewBatch(clock,
+					auctionrunner.WithAuctionStore(store),
+					auctionrunner.WithMaxBatchSize(2),
+					auctionrunner.WithDedupeMergePolicy(auctionrunner.DedupeMergePolicy{EarliestQueueTime: true}),
+				)
+				Expect(replayed.Replay()).To(Succeed())
+			})
+
+			It("drains the oldest auctions by their merged queue time", func() {
+				lrpAuctions, taskAuctions := replayed.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(2))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(lrpAuctions[0].Index).To(BeEquivalentTo(0))
+				Expect(lrpAuctions[1].ProcessGuid).To(Equal("pg-2"))
+				Expect(lrpAuctions[1].QueueTime).To(BeTemporally("==", earliestQueueTime))
+				Expect(taskAuctions).To(BeEmpty())
+			})
+
+			It("keeps the overflow in queue order", func() {
+				replayed.DedupeAndDrain()
+
+				lrpAuctions, taskAuctions := replayed.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(1))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(lrpAuctions[0].Index).To(BeEquivalentTo(1))
+				Expect(taskAuctions).To(HaveLen(1))
+			})
+		})
+
+		Describe("Replay", func() {
+			var replayed *auctionrunner.Batch
+
+			BeforeEach(func() {
+				queuedAt := clock.Now()
+				clock.Increment(time.Minute)
+
+				retried := BuildLRPAuction("pg-2", "domain", 0, "linux", 10, 10, 10, queuedAt, []string{}, []string{})
+				retried.Attempts = 3
+				Expect(store.Add([]auctiontypes.LRPAuction{retried}, nil)).To(Succeed())
+
+				replayed = auctionrunner.NewBatch(clock, auctionrunner.WithAuctionStore(store))
+				Expect(replayed.Replay()).To(Succeed())
+			})
+
+			It("restores the stored auctions with their queue time and attempts", func() {
+				lrpAuctions, taskAuctions := replayed.DedupeAndDrain()
+				Expect(lrpAuctions).To(HaveLen(3))
+				Expect(lrpAuctions[0].ProcessGuid).To(Equal("pg-1"))
+				Expect(lrpAuctions[0].QueueTime).To(BeTemporally("==", clock.Now().Add(-time.Minute)))
+				Expect(lrpAuctions[2].ProcessGuid).To(Equal("pg-2"))
+				Expect(lrpAuctions[2].Attempts).To(Equal(3))
+				Expect(taskAuctions).To(HaveLen(1))
+			})
+
+			It("has work", func() {
+				Expect(replayed.HasWork).To(Receive())
+			})
+		})
+	})
 })
+
+type failingRemoveStore struct {
+	auctionrunner.AuctionStore
+	failing bool
+}
+
+func (s *failingRemoveStore) Remove(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) error {
+	if s.failing {
+		return errors.New("remove failed")
+	}
+	return s.AuctionStore.Remove(lrps, tasks)
+}
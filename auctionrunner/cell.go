@@ -1,12 +1,23 @@
 package auctionrunner
 
 import (
+	"context"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 )
 
 const LocalityOffset = 1000
 
+// ContextClient is implemented by rep clients whose requests can be cancelled
+// or given a deadline. Cells use it in place of State and Perform when their
+// client supports it.
+type ContextClient interface {
+	StateContext(ctx context.Context, logger lager.Logger) (rep.CellState, error)
+	PerformContext(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error)
+}
+
 type Cell struct {
 	logger lager.Logger
 	Guid   string
@@ -74,18 +85,136 @@ func (c *Cell) ReserveTask(task *rep.Task) error {
 	return nil
 }
 
-func (c *Cell) Commit() rep.Work {
-	if len(c.workToCommit.LRPs) == 0 && len(c.workToCommit.Tasks) == 0 {
-		return rep.Work{}
+// releaseLRP frees the resources reserved for an LRP the cell rejected.
+func (c *Cell) releaseLRP(lrp *rep.LRP) {
+	for i := range c.State.LRPs {
+		if c.State.LRPs[i].Identifier() == lrp.Identifier() {
+			lrps := make([]rep.LRP, 0, len(c.State.LRPs)-1)
+			c.State.LRPs = append(append(lrps, c.State.LRPs[:i]...), c.State.LRPs[i+1:]...)
+			c.release(&lrp.Resource)
+			return
+		}
+	}
+}
+
+// releaseTask frees the resources reserved for a task the cell rejected.
+func (c *Cell) releaseTask(task *rep.Task) {
+	for i := range c.State.Tasks {
+		if c.State.Tasks[i].Identifier() == task.Identifier() {
+			tasks := make([]rep.Task, 0, len(c.State.Tasks)-1)
+			c.State.Tasks = append(append(tasks, c.State.Tasks[:i]...), c.State.Tasks[i+1:]...)
+			c.release(&task.Resource)
+			return
+		}
+	}
+}
+
+func (c *Cell) release(resource *rep.Resource) {
+	c.State.AvailableResources.MemoryMB += resource.MemoryMB
+	c.State.AvailableResources.DiskMB += resource.DiskMB
+	c.State.AvailableResources.Containers++
+	c.State.StartingContainerCount--
+}
+
+func (c *Cell) addInflightLRP(lrp *rep.LRP) {
+	for i := range c.State.LRPs {
+		if c.State.LRPs[i].Identifier() == lrp.Identifier() {
+			return
+		}
+	}
+	c.State.AddLRP(lrp)
+}
+
+func (c *Cell) addInflightTask(task *rep.Task) {
+	for i := range c.State.Tasks {
+		if c.State.Tasks[i].Identifier() == task.Identifier() {
+			return
+		}
 	}
+	c.State.AddTask(task)
+}
 
-	failedWork, err := c.client.Perform(c.logger, c.workToCommit)
+// CommitOutcome classifies committed work by what the cell reported back.
+// Unknown work was sent, but the cell could not confirm whether it was started.
+// Unsent work timed out before it could be sent, so the cell never saw it.
+type CommitOutcome struct {
+	Accepted rep.Work
+	Rejected rep.Work
+	Unknown  rep.Work
+	Unsent   rep.Work
+}
+
+func (c *Cell) Commit() CommitOutcome {
+	return c.CommitContext(context.Background())
+}
+
+func (c *Cell) CommitContext(ctx context.Context) CommitOutcome {
+	return c.commit(ctx, c.takeWorkToCommit())
+}
+
+// takeWorkToCommit hands over the work reserved on the cell so far, leaving
+// nothing to commit.
+func (c *Cell) takeWorkToCommit() rep.Work {
+	workToCommit := c.workToCommit
+	c.workToCommit = rep.Work{}
+	return workToCommit
+}
+
+func (c *Cell) commit(ctx context.Context, workToCommit rep.Work) CommitOutcome {
+	if len(workToCommit.LRPs) == 0 && len(workToCommit.Tasks) == 0 {
+		return CommitOutcome{}
+	}
+
+	ctx, span := startSpan(ctx, "commit", append(workGuidAttributes(workToCommit), auctiontypes.NewAttribute("cell-guid", c.Guid))...)
+	defer span.End()
+
+	failedWork, err := c.perform(ctx, workToCommit)
 	if err != nil {
+		span.RecordError(err)
 		c.logger.Error("failed-to-commit", err, lager.Data{"cell-guid": c.Guid})
 		//an error may indicate partial failure
 		//in this case we don't reschedule work in order to make sure we don't
-		//create duplicates of things -- we'll let the converger figure things out for us later
-		return rep.Work{}
+		//create duplicates of things -- we report the work as unknown so the
+		//delegate can reconcile it
+		return CommitOutcome{Unknown: workToCommit}
+	}
+	span.SetAttributes(
+		auctiontypes.NewAttribute("rejected-lrps", len(failedWork.LRPs)),
+		auctiontypes.NewAttribute("rejected-tasks", len(failedWork.Tasks)),
+	)
+	return CommitOutcome{
+		Accepted: acceptedWork(workToCommit, failedWork),
+		Rejected: failedWork,
+	}
+}
+
+func (c *Cell) perform(ctx context.Context, work rep.Work) (rep.Work, error) {
+	if client, ok := c.client.(ContextClient); ok {
+		return client.PerformContext(ctx, c.logger, work)
+	}
+	return c.client.Perform(c.logger, work)
+}
+
+func acceptedWork(committed, failed rep.Work) rep.Work {
+	failedLRPs := map[string]bool{}
+	for i := range failed.LRPs {
+		failedLRPs[failed.LRPs[i].Identifier()] = true
+	}
+	failedTasks := map[string]bool{}
+	for i := range failed.Tasks {
+		failedTasks[failed.Tasks[i].Identifier()] = true
+	}
+
+	accepted := rep.Work{}
+	for i := range committed.LRPs {
+		if !failedLRPs[committed.LRPs[i].Identifier()] {
+			accepted.LRPs = append(accepted.LRPs, committed.LRPs[i])
+		}
+	}
+	for i := range committed.Tasks {
+		if !failedTasks[committed.Tasks[i].Identifier()] {
+			accepted.Tasks = append(accepted.Tasks, committed.Tasks[i])
+		}
 	}
-	return failedWork
+	return accepted
 }
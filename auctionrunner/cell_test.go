@@ -1,6 +1,7 @@
 package auctionrunner_test
 
 import (
+	"context"
 	"errors"
 
 	"code.cloudfoundry.org/auction/auctionfashion"
@@ -142,8 +143,8 @@ var _ = Describe("Cell", func() {
 	Describe("Commit", func() {
 		Context("with nothing to commit", func() {
 			It("does nothing and returns empty", func() {
-				failedWork := cell.Commit()
-				Expect(failedWork).To(BeZero())
+				outcome := cell.Commit()
+				Expect(outcome).To(BeZero())
 				Expect(client.PerformCallCount()).To(Equal(0))
 			})
 		})
@@ -163,22 +164,72 @@ var _ = Describe("Cell", func() {
 				Expect(work).To(Equal(rep.Work{LRPs: []rep.LRP{lrp}}))
 			})
 
+			It("does not commit the same work twice", func() {
+				cell.Commit()
+				Expect(cell.Commit()).To(BeZero())
+				Expect(client.PerformCallCount()).To(Equal(1))
+			})
+
+			It("reports the work as accepted", func() {
+				outcome := cell.Commit()
+				Expect(outcome.Accepted).To(Equal(rep.Work{LRPs: []rep.LRP{lrp}}))
+				Expect(outcome.Rejected).To(BeZero())
+				Expect(outcome.Unknown).To(BeZero())
+			})
+
 			Context("when the client returns some failed work", func() {
-				It("forwards the failed work", func() {
+				var otherLRP rep.LRP
+
+				BeforeEach(func() {
+					otherLRP = *BuildLRP("pg-other", "domain", 0, linuxRootFSURL, 20, 10, 10, []string{})
+					Expect(cell.ReserveLRP(&otherLRP)).To(Succeed())
+				})
+
+				It("forwards the failed work as rejected", func() {
 					failedWork := rep.Work{
 						LRPs: []rep.LRP{lrp},
 					}
 					client.PerformReturns(failedWork, nil)
-					Expect(cell.Commit()).To(Equal(failedWork))
+
+					outcome := cell.Commit()
+					Expect(outcome.Rejected).To(Equal(failedWork))
+					Expect(outcome.Accepted).To(Equal(rep.Work{LRPs: []rep.LRP{otherLRP}}))
+					Expect(outcome.Unknown).To(BeZero())
 				})
 			})
 
 			Context("when the client returns an error", func() {
-				It("does not return any failed work", func() {
+				It("reports all of the work as unknown", func() {
 					client.PerformReturns(rep.Work{}, errors.New("boom"))
-					Expect(cell.Commit()).To(BeZero())
+
+					outcome := cell.Commit()
+					Expect(outcome.Unknown).To(Equal(rep.Work{LRPs: []rep.LRP{lrp}}))
+					Expect(outcome.Accepted).To(BeZero())
+					Expect(outcome.Rejected).To(BeZero())
 				})
 			})
 		})
+
+		Context("when the client accepts a context", func() {
+			var contextClient *contextSimClient
+
+			BeforeEach(func() {
+				contextClient = newContextSimClient()
+				state := BuildCellState("the-zone", 100, 200, 50, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{})
+				cell = auctionrunner.NewCell(logger, "the-cell", contextClient, state)
+
+				lrp := *BuildLRP("pg-new", "domain", 0, linuxRootFSURL, 20, 10, 10, []string{})
+				Expect(cell.ReserveLRP(&lrp)).To(Succeed())
+			})
+
+			It("hands the commit's context to the client", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				cell.CommitContext(ctx)
+				Expect(contextClient.PerformCallCount()).To(Equal(1))
+				Expect(contextClient.contexts).To(Receive(Equal(ctx)))
+			})
+		})
 	})
 })
@@ -0,0 +1,241 @@
+package auctionrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/workpool"
+)
+
+// RoundCapture holds the inputs of an auction round, as they were just
+// before scheduling, along with the results the round produced.
+type RoundCapture struct {
+	Time                          time.Time                   `json:"time"`
+	StartingContainerWeight       float64                     `json:"starting_container_weight"`
+	StartingContainerCountMaximum int                         `json:"starting_container_count_maximum"`
+	Cells                         []CapturedCell              `json:"cells"`
+	Request                       auctiontypes.AuctionRequest `json:"request"`
+	Results                       auctiontypes.AuctionResults `json:"results"`
+}
+
+type CapturedCell struct {
+	Guid  string        `json:"guid"`
+	State rep.CellState `json:"state"`
+}
+
+// RoundCapturer stores the capture of every auction round.
+type RoundCapturer interface {
+	CaptureRound(RoundCapture) error
+}
+
+// NewRoundCapture snapshots zones and auctionRequest. Later changes to either
+// do not affect the capture.
+func NewRoundCapture(
+	now time.Time,
+	zones map[string]Zone,
+	auctionRequest auctiontypes.AuctionRequest,
+	startingContainerWeight float64,
+	startingContainerCountMaximum int,
+) (RoundCapture, error) {
+	capture := RoundCapture{
+		Time:                          now,
+		StartingContainerWeight:       startingContainerWeight,
+		StartingContainerCountMaximum: startingContainerCountMaximum,
+		Request: auctiontypes.AuctionRequest{
+			LRPs:  append([]auctiontypes.LRPAuction{}, auctionRequest.LRPs...),
+			Tasks: append([]auctiontypes.TaskAuction{}, auctionRequest.Tasks...),
+		},
+	}
+
+	for _, zone := range zones {
+		for _, cell := range zone {
+			capture.Cells = append(capture.Cells, CapturedCell{Guid: cell.Guid, State: cell.State})
+		}
+	}
+	sort.Slice(capture.Cells, func(i, j int) bool { return capture.Cells[i].Guid < capture.Cells[j].Guid })
+
+	// round trip the cell states so reservations made while scheduling are
+	// not reflected in the capture
+	payload, err := json.Marshal(capture.Cells)
+	if err != nil {
+		return RoundCapture{}, err
+	}
+	capture.Cells = nil
+	err = json.Unmarshal(payload, &capture.Cells)
+	if err != nil {
+		return RoundCapture{}, err
+	}
+
+	return capture, nil
+}
+
+// DirectoryRoundCapturer writes every round to its own JSON file in a
+// directory, named after the time of the round.
+type DirectoryRoundCapturer struct {
+	dir string
+}
+
+func NewDirectoryRoundCapturer(dir string) (*DirectoryRoundCapturer, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &DirectoryRoundCapturer{dir: dir}, nil
+}
+
+func (c *DirectoryRoundCapturer) CaptureRound(capture RoundCapture) error {
+	payload, err := json.Marshal(capture)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(c.dir, fmt.Sprintf("round-%020d.json", capture.Time.UnixNano()))
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, payload, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func LoadRoundCapture(path string) (RoundCapture, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return RoundCapture{}, err
+	}
+
+	var capture RoundCapture
+	err = json.Unmarshal(payload, &capture)
+	if err != nil {
+		return RoundCapture{}, err
+	}
+	return capture, nil
+}
+
+// PlacementDiff is an auction that was placed differently when replayed.
+// Placements are the winning cell's guid, or the outcome and error of an
+// auction that was not placed.
+type PlacementDiff struct {
+	Type       string
+	Identifier string
+	Recorded   string
+	Replayed   string
+}
+
+type ReplayResult struct {
+	Results auctiontypes.AuctionResults
+	Diffs   []PlacementDiff
+}
+
+// ReplayRound schedules the captured request against the captured cells
+// using auctionType. Every cell accepts the work committed to it, so work
+// rejected by a cell during the original round shows up as a difference.
+func ReplayRound(logger lager.Logger, clock clock.Clock, capture RoundCapture, auctionType *AuctionType) (ReplayResult, error) {
+	workPool, err := workpool.NewWorkPool(len(capture.Cells) + 1)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	defer workPool.Stop()
+
+	zones := map[string]Zone{}
+	for _, captured := range capture.Cells {
+		cell := NewCell(logger, captured.Guid, replayClient{}, captured.State)
+		zones[captured.State.Zone] = append(zones[captured.State.Zone], cell)
+	}
+
+	auctionRequest := auctiontypes.AuctionRequest{
+		LRPs:  append([]auctiontypes.LRPAuction{}, capture.Request.LRPs...),
+		Tasks: append([]auctiontypes.TaskAuction{}, capture.Request.Tasks...),
+	}
+
+	scheduler := NewScheduler(workPool, zones, clock, logger, capture.StartingContainerWeight, capture.StartingContainerCountMaximum, auctionType)
+	results := scheduler.Schedule(auctionRequest)
+
+	requested := placements(auctiontypes.AuctionResults{SuccessfulLRPs: capture.Request.LRPs, SuccessfulTasks: capture.Request.Tasks})
+	recorded := placements(capture.Results)
+	for key := range recorded {
+		if _, ok := requested[key]; !ok {
+			delete(recorded, key)
+		}
+	}
+	replayed := placements(results)
+
+	diffs := []PlacementDiff{}
+	for key, placement := range recorded {
+		if replayed[key] != placement {
+			diffs = append(diffs, PlacementDiff{Type: key.auctionType, Identifier: key.identifier, Recorded: placement, Replayed: replayed[key]})
+		}
+	}
+	for key, placement := range replayed {
+		if _, ok := recorded[key]; !ok {
+			diffs = append(diffs, PlacementDiff{Type: key.auctionType, Identifier: key.identifier, Replayed: placement})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Type != diffs[j].Type {
+			return diffs[i].Type < diffs[j].Type
+		}
+		return diffs[i].Identifier < diffs[j].Identifier
+	})
+
+	return ReplayResult{Results: results, Diffs: diffs}, nil
+}
+
+type placementKey struct {
+	auctionType string
+	identifier  string
+}
+
+func placements(results auctiontypes.AuctionResults) map[placementKey]string {
+	placed := map[placementKey]string{}
+	addLRPs := func(outcome string, lrps []auctiontypes.LRPAuction) {
+		for i := range lrps {
+			placed[placementKey{AuditTypeLRP, lrps[i].Identifier()}] = placement(outcome, lrps[i].AuctionRecord)
+		}
+	}
+	addTasks := func(outcome string, tasks []auctiontypes.TaskAuction) {
+		for i := range tasks {
+			placed[placementKey{AuditTypeTask, tasks[i].Identifier()}] = placement(outcome, tasks[i].AuctionRecord)
+		}
+	}
+
+	addLRPs(AuditOutcomeSuccessful, results.SuccessfulLRPs)
+	addTasks(AuditOutcomeSuccessful, results.SuccessfulTasks)
+	addLRPs(AuditOutcomeFailed, results.FailedLRPs)
+	addTasks(AuditOutcomeFailed, results.FailedTasks)
+	addLRPs(AuditOutcomeUnknown, results.UnknownLRPs)
+	addTasks(AuditOutcomeUnknown, results.UnknownTasks)
+	addLRPs(AuditOutcomeCancelled, results.CancelledLRPs)
+	addTasks(AuditOutcomeCancelled, results.CancelledTasks)
+	return placed
+}
+
+func placement(outcome string, record auctiontypes.AuctionRecord) string {
+	switch outcome {
+	case AuditOutcomeSuccessful:
+		return record.Winner
+	case AuditOutcomeFailed:
+		return outcome + ": " + record.PlacementError
+	default:
+		return outcome
+	}
+}
+
+// replayClient accepts all work. The scheduler only ever calls Perform on
+// the cells it is given.
+type replayClient struct {
+	rep.Client
+}
+
+func (replayClient) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+	return rep.Work{}, nil
+}
@@ -0,0 +1,186 @@
+package auctionrunner_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
+	"code.cloudfoundry.org/workpool"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Round capture", func() {
+	var (
+		clock          *fakeclock.FakeClock
+		zones          map[string]auctionrunner.Zone
+		auctionRequest auctiontypes.AuctionRequest
+		capture        auctiontypes.AuctionRequest
+	)
+
+	newZones := func() map[string]auctionrunner.Zone {
+		return map[string]auctionrunner.Zone{
+			"Z0": {auctionrunner.NewCell(logger, "A-cell", &repfakes.FakeSimClient{},
+				BuildCellState("Z0", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, []rep.LRP{
+					*BuildLRP("pg-1", "domain", 0, linuxRootFSURL, 10, 10, 10, []string{}),
+				}, []string{}, []string{}, []string{}))},
+			"Z1": {auctionrunner.NewCell(logger, "B-cell", &repfakes.FakeSimClient{},
+				BuildCellState("Z1", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}))},
+		}
+	}
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Unix(1500000000, 0).UTC())
+		zones = newZones()
+		auctionRequest = auctiontypes.AuctionRequest{
+			LRPs: []auctiontypes.LRPAuction{
+				BuildLRPAuction("pg-1", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{}),
+			},
+			Tasks: []auctiontypes.TaskAuction{
+				BuildTaskAuction(BuildTask("tg-1", "domain", linuxRootFSURL, 10, 10, 10, nil, []string{}), clock.Now()),
+				BuildTaskAuction(BuildTask("tg-2", "domain", windowsRootFSURL, 10, 10, 10, nil, []string{}), clock.Now()),
+			},
+		}
+		capture = auctionRequest
+	})
+
+	Describe("NewRoundCapture", func() {
+		It("is not affected by work reserved after it was taken", func() {
+			roundCapture, err := auctionrunner.NewRoundCapture(clock.Now(), zones, auctionRequest, 0.25, 5)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(zones["Z1"][0].ReserveLRP(&auctionRequest.LRPs[0].LRP)).To(Succeed())
+			auctionRequest.LRPs[0].PlacementError = "changed"
+
+			Expect(roundCapture.Time).To(Equal(clock.Now()))
+			Expect(roundCapture.StartingContainerWeight).To(Equal(0.25))
+			Expect(roundCapture.StartingContainerCountMaximum).To(Equal(5))
+			Expect(roundCapture.Cells).To(HaveLen(2))
+			Expect(roundCapture.Cells[0].Guid).To(Equal("A-cell"))
+			Expect(roundCapture.Cells[0].State.LRPs).To(HaveLen(1))
+			Expect(roundCapture.Cells[1].Guid).To(Equal("B-cell"))
+			Expect(roundCapture.Cells[1].State.LRPs).To(BeEmpty())
+			Expect(roundCapture.Cells[1].State.AvailableResources.MemoryMB).To(BeEquivalentTo(100))
+			Expect(roundCapture.Request.LRPs[0].PlacementError).To(BeEmpty())
+			Expect(roundCapture.Request.Tasks).To(HaveLen(2))
+		})
+	})
+
+	Describe("DirectoryRoundCapturer", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "round-capture")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("writes a file per round that can be loaded back", func() {
+			capturer, err := auctionrunner.NewDirectoryRoundCapturer(filepath.Join(tmpDir, "rounds"))
+			Expect(err).NotTo(HaveOccurred())
+
+			roundCapture, err := auctionrunner.NewRoundCapture(clock.Now(), zones, auctionRequest, 0.25, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(capturer.CaptureRound(roundCapture)).To(Succeed())
+			clock.Increment(time.Second)
+			roundCapture.Time = clock.Now()
+			Expect(capturer.CaptureRound(roundCapture)).To(Succeed())
+
+			paths, err := filepath.Glob(filepath.Join(tmpDir, "rounds", "round-*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(HaveLen(2))
+
+			loaded, err := auctionrunner.LoadRoundCapture(paths[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Time.Equal(clock.Now().Add(-time.Second))).To(BeTrue())
+			Expect(loaded.StartingContainerWeight).To(Equal(0.25))
+			Expect(loaded.Cells).To(HaveLen(2))
+			Expect(loaded.Cells[0].State.MatchRootFS(linuxRootFSURL)).To(BeTrue())
+			Expect(loaded.Cells[0].State.MatchRootFS(windowsRootFSURL)).To(BeFalse())
+			Expect(loaded.Request.LRPs[0].Identifier()).To(Equal("pg-1.1"))
+			Expect(loaded.Request.Tasks[1].TaskGuid).To(Equal("tg-2"))
+		})
+	})
+
+	Describe("ReplayRound", func() {
+		var roundCapture auctionrunner.RoundCapture
+		var auctionType *auctionrunner.AuctionType
+
+		BeforeEach(func() {
+			auctionType = auctionfashion.NewAuctionType(auctionfashion.DefaultAuction)
+
+			var err error
+			roundCapture, err = auctionrunner.NewRoundCapture(clock.Now(), zones, capture, 0.25, 5)
+			Expect(err).NotTo(HaveOccurred())
+
+			workPool, err := workpool.NewWorkPool(2)
+			Expect(err).NotTo(HaveOccurred())
+			defer workPool.Stop()
+			scheduler := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.25, 5, auctionType)
+			roundCapture.Results = scheduler.Schedule(auctionRequest)
+			roundCapture.Results.CancelledTasks = []auctiontypes.TaskAuction{
+				BuildTaskAuction(BuildTask("tg-3", "domain", linuxRootFSURL, 10, 10, 10, nil, []string{}), clock.Now()),
+			}
+		})
+
+		It("places the captured request the same way with the same auction type", func() {
+			result, err := auctionrunner.ReplayRound(logger, clock, roundCapture, auctionType)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Results.SuccessfulLRPs).To(HaveLen(1))
+			Expect(result.Results.SuccessfulLRPs[0].Winner).To(Equal("B-cell"))
+			Expect(result.Results.SuccessfulTasks).To(HaveLen(1))
+			Expect(result.Results.FailedTasks).To(HaveLen(1))
+			Expect(result.Diffs).To(BeEmpty())
+		})
+
+		It("reports auctions placed differently", func() {
+			rejected := roundCapture.Results.SuccessfulLRPs[0]
+			rejected.PlacementError = ""
+			roundCapture.Results.SuccessfulLRPs = nil
+			roundCapture.Results.FailedLRPs = []auctiontypes.LRPAuction{rejected}
+
+			result, err := auctionrunner.ReplayRound(logger, clock, roundCapture, auctionType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Diffs).To(Equal([]auctionrunner.PlacementDiff{{
+				Type:       auctionrunner.AuditTypeLRP,
+				Identifier: "pg-1.1",
+				Recorded:   "failed: ",
+				Replayed:   "B-cell",
+			}}))
+		})
+
+		It("replays with a different auction type", func() {
+			onlyA := auctionfashion.NewAuctionType(auctionfashion.DefaultAuction)
+			onlyA.ScoreForLRP = func(c *auctionrunner.Cell, lrp *rep.LRP, _ float64) (float64, error) {
+				if c.Guid != "A-cell" {
+					return 0, rep.ErrorIncompatibleRootfs
+				}
+				return 1, nil
+			}
+
+			result, err := auctionrunner.ReplayRound(logger, clock, roundCapture, onlyA)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Results.SuccessfulLRPs).To(HaveLen(1))
+			Expect(result.Results.SuccessfulLRPs[0].Winner).To(Equal("A-cell"))
+			Expect(result.Diffs).To(ContainElement(auctionrunner.PlacementDiff{
+				Type:       auctionrunner.AuditTypeLRP,
+				Identifier: "pg-1.1",
+				Recorded:   "B-cell",
+				Replayed:   "A-cell",
+			}))
+		})
+	})
+})
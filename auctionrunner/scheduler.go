@@ -1,8 +1,10 @@
 package auctionrunner
 
 import (
+	"context"
 	"sort"
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/rep"
@@ -22,6 +24,54 @@ type Scheduler struct {
 	startingContainerWeight       float64
 	startingContainerCountMaximum int // <=0 means no limit
 	auctionType                   *AuctionType
+	commitRetryPasses             int
+	commitWorkPool                *workpool.WorkPool
+	commitTimeout                 time.Duration // <=0 means no timeout
+	metricEmitter                 auctiontypes.AuctionMetricEmitterDelegate
+	listener                      auctiontypes.AuctionListener
+	lrpScores                     map[string]float64
+	taskScores                    map[string]float64
+}
+
+type SchedulerOption func(*Scheduler)
+
+// WithCommitRetryPasses re-auctions work rejected by a cell during commit
+// against the remaining cells, up to passes additional times per Schedule.
+func WithCommitRetryPasses(passes int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.commitRetryPasses = passes
+	}
+}
+
+// WithCommitWorkPool commits work to cells on a dedicated pool instead of the
+// pool used for fetching cell states.
+func WithCommitWorkPool(workPool *workpool.WorkPool) SchedulerOption {
+	return func(s *Scheduler) {
+		s.commitWorkPool = workPool
+	}
+}
+
+// WithCommitTimeout cancels commits that cells have not answered within
+// timeout and reports their work as unknown. Commits still waiting for a
+// worker by then are not sent, and their work is treated as rejected.
+func WithCommitTimeout(timeout time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.commitTimeout = timeout
+	}
+}
+
+func WithMetricEmitter(metricEmitter auctiontypes.AuctionMetricEmitterDelegate) SchedulerOption {
+	return func(s *Scheduler) {
+		s.metricEmitter = metricEmitter
+	}
+}
+
+// WithAuctionListener streams the outcome of each auction to listener as soon
+// as it is known, in addition to returning it from Schedule.
+func WithAuctionListener(listener auctiontypes.AuctionListener) SchedulerOption {
+	return func(s *Scheduler) {
+		s.listener = listener
+	}
 }
 
 func NewScheduler(
@@ -32,8 +82,9 @@ func NewScheduler(
 	startingContainerWeight float64,
 	startingContainerCountMaximum int,
 	auctionType *AuctionType,
+	options ...SchedulerOption,
 ) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		workPool:                      workPool,
 		zones:                         zones,
 		clock:                         clock,
@@ -41,7 +92,14 @@ func NewScheduler(
 		startingContainerWeight:       startingContainerWeight,
 		startingContainerCountMaximum: startingContainerCountMaximum,
 		auctionType:                   auctionType, //CHANGE
+		commitWorkPool:                workPool,
+		lrpScores:                     map[string]float64{},
+		taskScores:                    map[string]float64{},
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
 /*
@@ -53,6 +111,26 @@ work in batches at the end, for better network performance.  Schedule returns
 AuctionResults, indicating the success or failure of each requested job.
 */
 func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auctiontypes.AuctionResults {
+	return s.ScheduleContext(context.Background(), auctionRequest)
+}
+
+// ScheduleContext is Schedule with cancellation. Once ctx is done no further
+// work is sent to cells: work that was never sent fails with
+// ErrorAuctionAborted, and commits still outstanding are reported as unknown.
+func (s *Scheduler) ScheduleContext(ctx context.Context, auctionRequest auctiontypes.AuctionRequest) auctiontypes.AuctionResults {
+	ctx, span := startSpan(ctx, "schedule", auctionGuidAttributes(auctionRequest.LRPs, auctionRequest.Tasks)...)
+	defer span.End()
+
+	if metricEmitter, ok := s.metricEmitter.(auctiontypes.CommitMetricEmitter); ok {
+		scheduleStartTime := s.clock.Now()
+		defer func() {
+			err := metricEmitter.ScheduleCompleted(s.clock.Since(scheduleStartTime))
+			if err != nil {
+				s.logger.Error("failed-sending-schedule-completed-metric", err)
+			}
+		}()
+	}
+
 	results := auctiontypes.AuctionResults{}
 
 	if len(s.zones) == 0 {
@@ -64,6 +142,7 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 		for i, _ := range results.FailedTasks {
 			results.FailedTasks[i].PlacementError = auctiontypes.ErrorCellCommunication.Error()
 		}
+		s.streamFailures(results, 0, 0)
 		return s.markResults(results)
 	}
 
@@ -84,41 +163,38 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 
 	lrpsBeforeTasks, lrpsAfterTasks := splitLRPS(auctionRequest.LRPs)
 
-	auctionLRP := func(lrpsToAuction []auctiontypes.LRPAuction) {
-		for i := range lrpsToAuction {
-			lrpAuction := &lrpsToAuction[i]
-			lrpStartAuctionLookup[lrpAuction.Identifier()] = lrpAuction
-
-			if s.exceededInflightContainerCreation(currentInflightContainerStarts) {
-				s.logger.Info(
-					"exceeded-max-inflight-container-creation",
-					lager.Data{
-						"max-inflight": s.startingContainerCountMaximum,
-						"lrp-guid":     lrpAuction.Identifier(),
-					},
-				)
-				lrpAuction.PlacementError = auctiontypes.ErrorExceededInflightCreation.Error()
-				results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
-				continue
-			}
-
-			successfulStart, err := s.scheduleLRPAuction(lrpAuction)
-			if err != nil {
-				lrpAuction.PlacementError = err.Error()
-				results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
-			} else {
-				successfulLRPs[successfulStart.Identifier()] = successfulStart
-				currentInflightContainerStarts++
-			}
+	auctionLRP := func(lrpAuction *auctiontypes.LRPAuction, excludedCells map[string]struct{}) {
+		if s.exceededInflightContainerCreation(currentInflightContainerStarts) {
+			s.logger.Info(
+				"exceeded-max-inflight-container-creation",
+				lager.Data{
+					"max-inflight": s.startingContainerCountMaximum,
+					"lrp-guid":     lrpAuction.Identifier(),
+				},
+			)
+			lrpAuction.PlacementError = auctiontypes.ErrorExceededInflightCreation.Error()
+			results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
+			return
 		}
-	}
 
-	auctionLRP(lrpsBeforeTasks)
+		zones := s.zonesExcluding(excludedCells)
+		if len(zones) == 0 {
+			lrpAuction.PlacementError = auctiontypes.ErrorRejectedByEveryCell.Error()
+			results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
+			return
+		}
 
-	for i := range auctionRequest.Tasks {
-		taskAuction := &auctionRequest.Tasks[i]
-		taskAuctionLookup[taskAuction.Identifier()] = taskAuction
+		successfulStart, err := s.scheduleLRPAuction(lrpAuction, zones)
+		if err != nil {
+			lrpAuction.PlacementError = err.Error()
+			results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
+		} else {
+			successfulLRPs[successfulStart.Identifier()] = successfulStart
+			currentInflightContainerStarts++
+		}
+	}
 
+	auctionTask := func(taskAuction *auctiontypes.TaskAuction, excludedCells map[string]struct{}) {
 		if s.exceededInflightContainerCreation(currentInflightContainerStarts) {
 			s.logger.Info(
 				"exceeded-max-inflight-container-creation",
@@ -129,10 +205,17 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 			)
 			taskAuction.PlacementError = auctiontypes.ErrorExceededInflightCreation.Error()
 			results.FailedTasks = append(results.FailedTasks, *taskAuction)
-			continue
+			return
+		}
+
+		zones := s.zonesExcluding(excludedCells)
+		if len(zones) == 0 {
+			taskAuction.PlacementError = auctiontypes.ErrorRejectedByEveryCell.Error()
+			results.FailedTasks = append(results.FailedTasks, *taskAuction)
+			return
 		}
 
-		successfulTask, err := s.scheduleTaskAuction(taskAuction, s.startingContainerWeight)
+		successfulTask, err := s.scheduleTaskAuction(taskAuction, zones)
 		if err != nil {
 			taskAuction.PlacementError = err.Error()
 			results.FailedTasks = append(results.FailedTasks, *taskAuction)
@@ -142,24 +225,215 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 		}
 	}
 
-	auctionLRP(lrpsAfterTasks)
+	for i := range lrpsBeforeTasks {
+		lrpStartAuctionLookup[lrpsBeforeTasks[i].Identifier()] = &lrpsBeforeTasks[i]
+		auctionLRP(&lrpsBeforeTasks[i], nil)
+	}
 
-	failedWorks := s.commitCells()
-	for _, failedWork := range failedWorks {
-		for _, failedStart := range failedWork.LRPs {
+	for i := range auctionRequest.Tasks {
+		taskAuctionLookup[auctionRequest.Tasks[i].Identifier()] = &auctionRequest.Tasks[i]
+		auctionTask(&auctionRequest.Tasks[i], nil)
+	}
+
+	for i := range lrpsAfterTasks {
+		lrpStartAuctionLookup[lrpsAfterTasks[i].Identifier()] = &lrpsAfterTasks[i]
+		auctionLRP(&lrpsAfterTasks[i], nil)
+	}
+
+	rejectingCells := map[string]map[string]struct{}{}
+	rejectedBy := func(identifier, cellGuid string) {
+		if rejectingCells[identifier] == nil {
+			rejectingCells[identifier] = map[string]struct{}{}
+		}
+		rejectingCells[identifier][cellGuid] = struct{}{}
+	}
+
+	// retry is whether work rejected in the current pass is re-auctioned
+	var retry bool
+	var streamCommit func(CommitOutcome)
+	if s.listener != nil {
+		streamCommit = func(outcome CommitOutcome) {
+			now := s.clock.Now()
+			for _, lrp := range outcome.Accepted.LRPs {
+				if placed, ok := successfulLRPs[lrp.Identifier()]; ok {
+					s.listener.LRPPlaced(markPlacedLRP(*placed, now))
+				}
+			}
+			for _, task := range outcome.Accepted.Tasks {
+				if placed, ok := successfulTasks[task.Identifier()]; ok {
+					s.listener.TaskPlaced(markPlacedTask(*placed, now))
+				}
+			}
+			if !retry {
+				// rejections are streamed as failures once the pass is over
+				return
+			}
+			for _, work := range []rep.Work{outcome.Rejected, outcome.Unsent} {
+				for _, lrp := range work.LRPs {
+					if rejected, ok := successfulLRPs[lrp.Identifier()]; ok {
+						s.listener.LRPCommitRejected(markAttemptedLRP(*rejected))
+					}
+				}
+				for _, task := range work.Tasks {
+					if rejected, ok := successfulTasks[task.Identifier()]; ok {
+						s.listener.TaskCommitRejected(markAttemptedTask(*rejected))
+					}
+				}
+			}
+		}
+	}
+	var streamedLRPFailures, streamedTaskFailures int
+
+	commitStartTime := s.clock.Now()
+	for pass := 0; ; pass++ {
+		retry = pass < s.commitRetryPasses
+		retryLRPs := map[string]bool{}
+		retryTasks := map[string]bool{}
+
+		// work a cell rejected is re-auctioned while passes remain. Unsent work
+		// timed out before it was committed and is handled the same way, but
+		// fails with its own placement error.
+		rejectLRP := func(cellGuid string, failedStart *rep.LRP, placementError string) {
 			identifier := failedStart.Identifier()
+			if _, ok := successfulLRPs[identifier]; !ok {
+				s.logger.Info("lrp-rejected-not-scheduled", lager.Data{"lrp-guid": identifier, "cell-guid": cellGuid})
+				return
+			}
 			delete(successfulLRPs, identifier)
+			// the cell did not start the LRP, so its room can go to the
+			// work re-auctioned below
+			s.cell(cellGuid).releaseLRP(failedStart)
+			rejectedBy(identifier, cellGuid)
+
+			if retry {
+				s.logger.Info("lrp-rejected-by-cell-retrying", lager.Data{"lrp-guid": identifier, "cell-guid": cellGuid, "pass": pass + 1})
+				retryLRPs[identifier] = true
+				currentInflightContainerStarts--
+				return
+			}
 
 			s.logger.Info("lrp-failed-to-be-placed", lager.Data{"lrp-guid": failedStart.Identifier()})
-			results.FailedLRPs = append(results.FailedLRPs, *lrpStartAuctionLookup[identifier])
+			failedLRP := lrpStartAuctionLookup[identifier]
+			if placementError != "" {
+				failedLRP.PlacementError = placementError
+			}
+			results.FailedLRPs = append(results.FailedLRPs, *failedLRP)
 		}
-
-		for _, failedTask := range failedWork.Tasks {
+		rejectTask := func(cellGuid string, failedTask *rep.Task, placementError string) {
 			identifier := failedTask.Identifier()
+			if _, ok := successfulTasks[identifier]; !ok {
+				s.logger.Info("task-rejected-not-scheduled", lager.Data{"task-guid": identifier, "cell-guid": cellGuid})
+				return
+			}
 			delete(successfulTasks, identifier)
+			s.cell(cellGuid).releaseTask(failedTask)
+			rejectedBy(identifier, cellGuid)
+
+			if retry {
+				s.logger.Info("task-rejected-by-cell-retrying", lager.Data{"task-guid": identifier, "cell-guid": cellGuid, "pass": pass + 1})
+				retryTasks[identifier] = true
+				currentInflightContainerStarts--
+				return
+			}
 
 			s.logger.Info("task-failed-to-be-placed", lager.Data{"task-guid": failedTask.Identifier()})
-			results.FailedTasks = append(results.FailedTasks, *taskAuctionLookup[identifier])
+			failedTaskAuction := taskAuctionLookup[identifier]
+			if placementError != "" {
+				failedTaskAuction.PlacementError = placementError
+			}
+			results.FailedTasks = append(results.FailedTasks, *failedTaskAuction)
+		}
+
+		streamedLRPFailures, streamedTaskFailures = s.streamFailures(results, streamedLRPFailures, streamedTaskFailures)
+
+		if ctx.Err() != nil {
+			s.logger.Info("auction-aborted", lager.Data{"error": ctx.Err().Error(), "pass": pass})
+			s.abortUncommitted(&results, successfulLRPs, successfulTasks)
+			break
+		}
+
+		outcomes := s.commitCells(ctx, streamCommit)
+		for cellGuid, outcome := range outcomes {
+			for _, unknownStart := range outcome.Unknown.LRPs {
+				identifier := unknownStart.Identifier()
+				unknownLRP, ok := successfulLRPs[identifier]
+				if !ok {
+					s.logger.Info("lrp-placement-unknown-not-scheduled", lager.Data{"lrp-guid": identifier, "cell-guid": cellGuid})
+					continue
+				}
+				delete(successfulLRPs, identifier)
+
+				s.logger.Info("lrp-placement-unknown", lager.Data{"lrp-guid": identifier, "cell-guid": cellGuid})
+				unknownLRP.PlacementError = auctiontypes.ErrorCommitOutcomeUnknown.Error()
+				results.UnknownLRPs = append(results.UnknownLRPs, *unknownLRP)
+				if s.listener != nil {
+					s.listener.LRPUnknown(markPlacedLRP(*unknownLRP, s.clock.Now()))
+				}
+			}
+
+			for _, unknownTask := range outcome.Unknown.Tasks {
+				identifier := unknownTask.Identifier()
+				unknownTaskAuction, ok := successfulTasks[identifier]
+				if !ok {
+					s.logger.Info("task-placement-unknown-not-scheduled", lager.Data{"task-guid": identifier, "cell-guid": cellGuid})
+					continue
+				}
+				delete(successfulTasks, identifier)
+
+				s.logger.Info("task-placement-unknown", lager.Data{"task-guid": identifier, "cell-guid": cellGuid})
+				unknownTaskAuction.PlacementError = auctiontypes.ErrorCommitOutcomeUnknown.Error()
+				results.UnknownTasks = append(results.UnknownTasks, *unknownTaskAuction)
+				if s.listener != nil {
+					s.listener.TaskUnknown(markPlacedTask(*unknownTaskAuction, s.clock.Now()))
+				}
+			}
+
+			unsentError := auctiontypes.ErrorCommitTimedOut.Error()
+			if ctx.Err() != nil {
+				unsentError = auctiontypes.ErrorAuctionAborted.Error()
+			}
+			for i := range outcome.Rejected.LRPs {
+				rejectLRP(cellGuid, &outcome.Rejected.LRPs[i], "")
+			}
+			for i := range outcome.Unsent.LRPs {
+				rejectLRP(cellGuid, &outcome.Unsent.LRPs[i], unsentError)
+			}
+			for i := range outcome.Rejected.Tasks {
+				rejectTask(cellGuid, &outcome.Rejected.Tasks[i], "")
+			}
+			for i := range outcome.Unsent.Tasks {
+				rejectTask(cellGuid, &outcome.Unsent.Tasks[i], unsentError)
+			}
+		}
+
+		if len(retryLRPs) == 0 && len(retryTasks) == 0 {
+			break
+		}
+
+		// re-auction in the original order so boulders are still placed before pebbles
+		for i := range lrpsBeforeTasks {
+			if identifier := lrpsBeforeTasks[i].Identifier(); retryLRPs[identifier] {
+				auctionLRP(&lrpsBeforeTasks[i], rejectingCells[identifier])
+			}
+		}
+		for i := range auctionRequest.Tasks {
+			if identifier := auctionRequest.Tasks[i].Identifier(); retryTasks[identifier] {
+				auctionTask(&auctionRequest.Tasks[i], rejectingCells[identifier])
+			}
+		}
+		for i := range lrpsAfterTasks {
+			if identifier := lrpsAfterTasks[i].Identifier(); retryLRPs[identifier] {
+				auctionLRP(&lrpsAfterTasks[i], rejectingCells[identifier])
+			}
+		}
+	}
+
+	s.streamFailures(results, streamedLRPFailures, streamedTaskFailures)
+
+	if metricEmitter, ok := s.metricEmitter.(auctiontypes.CommitMetricEmitter); ok {
+		err := metricEmitter.CommitCompleted(s.clock.Since(commitStartTime))
+		if err != nil {
+			s.logger.Error("failed-sending-commit-completed-metric", err)
 		}
 	}
 
@@ -174,10 +448,23 @@ func (s *Scheduler) Schedule(auctionRequest auctiontypes.AuctionRequest) auction
 	return s.markResults(results)
 }
 
+// WinningScores returns the bid of the winning cell for every LRP and task
+// the scheduler placed, keyed by identifier. Lower scores are better.
+func (s *Scheduler) WinningScores() (map[string]float64, map[string]float64) {
+	lrpScores := make(map[string]float64, len(s.lrpScores))
+	for identifier, score := range s.lrpScores {
+		lrpScores[identifier] = score
+	}
+	taskScores := make(map[string]float64, len(s.taskScores))
+	for identifier, score := range s.taskScores {
+		taskScores[identifier] = score
+	}
+	return lrpScores, taskScores
+}
+
 func (s *Scheduler) markResults(results auctiontypes.AuctionResults) auctiontypes.AuctionResults {
 	now := s.clock.Now()
 	for i := range results.FailedLRPs {
-
 		results.FailedLRPs[i].Attempts++
 	}
 	for i := range results.FailedTasks {
@@ -191,10 +478,54 @@ func (s *Scheduler) markResults(results auctiontypes.AuctionResults) auctiontype
 		results.SuccessfulTasks[i].Attempts++
 		results.SuccessfulTasks[i].WaitDuration = now.Sub(results.SuccessfulTasks[i].QueueTime)
 	}
+	for i := range results.UnknownLRPs {
+		results.UnknownLRPs[i].Attempts++
+		results.UnknownLRPs[i].WaitDuration = now.Sub(results.UnknownLRPs[i].QueueTime)
+	}
+	for i := range results.UnknownTasks {
+		results.UnknownTasks[i].Attempts++
+		results.UnknownTasks[i].WaitDuration = now.Sub(results.UnknownTasks[i].QueueTime)
+	}
 
 	return results
 }
 
+// streamFailures hands the listener the failures added to results after the
+// given number of LRP and task failures, returning the new totals.
+func (s *Scheduler) streamFailures(results auctiontypes.AuctionResults, lrps, tasks int) (int, int) {
+	if s.listener != nil {
+		for _, failed := range results.FailedLRPs[lrps:] {
+			s.listener.LRPFailed(markAttemptedLRP(failed))
+		}
+		for _, failed := range results.FailedTasks[tasks:] {
+			s.listener.TaskFailed(markAttemptedTask(failed))
+		}
+	}
+	return len(results.FailedLRPs), len(results.FailedTasks)
+}
+
+func markAttemptedLRP(lrp auctiontypes.LRPAuction) auctiontypes.LRPAuction {
+	lrp.Attempts++
+	return lrp
+}
+
+func markAttemptedTask(task auctiontypes.TaskAuction) auctiontypes.TaskAuction {
+	task.Attempts++
+	return task
+}
+
+func markPlacedLRP(lrp auctiontypes.LRPAuction, now time.Time) auctiontypes.LRPAuction {
+	lrp.Attempts++
+	lrp.WaitDuration = now.Sub(lrp.QueueTime)
+	return lrp
+}
+
+func markPlacedTask(task auctiontypes.TaskAuction, now time.Time) auctiontypes.TaskAuction {
+	task.Attempts++
+	task.WaitDuration = now.Sub(task.QueueTime)
+	return task
+}
+
 func splitLRPS(lrps []auctiontypes.LRPAuction) ([]auctiontypes.LRPAuction, []auctiontypes.LRPAuction) {
 	const pivot = 0
 
@@ -207,46 +538,198 @@ func splitLRPS(lrps []auctiontypes.LRPAuction) ([]auctiontypes.LRPAuction, []auc
 	return lrps[:0], lrps[0:]
 }
 
-func (s *Scheduler) commitCells() []rep.Work {
-	wg := &sync.WaitGroup{}
+// commitCells commits the reserved work on every cell. stream, when not nil,
+// is called with each cell's outcome as it arrives, on the calling goroutine.
+func (s *Scheduler) commitCells(ctx context.Context, stream func(CommitOutcome)) map[string]CommitOutcome {
+	outcomes := map[string]CommitOutcome{}
+	pendingWorks := map[string]rep.Work{}
+
+	// the work is taken from the cells here rather than in the pool so that a
+	// commit still queued when the timeout fires cannot pick up work reserved
+	// by a later pass
 	for _, cells := range s.zones {
-		wg.Add(len(cells))
+		for _, cell := range cells {
+			pendingWorks[cell.Guid] = cell.takeWorkToCommit()
+		}
 	}
 
-	lock := &sync.Mutex{}
-	failedWorks := []rep.Work{}
+	if len(pendingWorks) == 0 {
+		return outcomes
+	}
+
+	type cellOutcome struct {
+		cellGuid string
+		outcome  CommitOutcome
+	}
+	// buffered so that commits answering after the timeout never block
+	arrivals := make(chan cellOutcome, len(pendingWorks))
+
+	// a commit that has not started by the time the scheduler stops waiting
+	// is never sent, since its work may be re-auctioned
+	var commitsLock sync.Mutex
+	var stoppedWaiting bool
+	startedCommits := map[string]bool{}
 
 	for _, cells := range s.zones {
 		for _, cell := range cells {
 			cell := cell
-			s.workPool.Submit(func() {
-				defer wg.Done()
-				failedWork := cell.Commit()
-
-				lock.Lock()
-				failedWorks = append(failedWorks, failedWork)
-				lock.Unlock()
+			work := pendingWorks[cell.Guid]
+			hasWork := len(work.LRPs) > 0 || len(work.Tasks) > 0
+			commitCtx, cancel := s.commitContext(ctx)
+			deadline := s.clock.Now().Add(s.commitTimeout)
+			s.commitWorkPool.Submit(func() {
+				defer cancel()
+
+				commitsLock.Lock()
+				expired := s.commitTimeout > 0 && !s.clock.Now().Before(deadline)
+				if stoppedWaiting || expired || ctx.Err() != nil {
+					commitsLock.Unlock()
+					return
+				}
+				startedCommits[cell.Guid] = true
+				commitsLock.Unlock()
+
+				commitStartTime := s.clock.Now()
+				outcome := cell.commit(commitCtx, work)
+				if metricEmitter, ok := s.metricEmitter.(auctiontypes.CommitMetricEmitter); ok && hasWork {
+					metricEmitter.CellCommitCompleted(cell.Guid, s.clock.Since(commitStartTime))
+				}
+				arrivals <- cellOutcome{cellGuid: cell.Guid, outcome: outcome}
 			})
 		}
 	}
 
-	wg.Wait()
-	return failedWorks
+	var timeout <-chan time.Time
+	if s.commitTimeout > 0 {
+		timer := s.clock.NewTimer(s.commitTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	for len(pendingWorks) > 0 {
+		select {
+		case arrival := <-arrivals:
+			if stream != nil {
+				stream(arrival.outcome)
+			}
+			outcomes[arrival.cellGuid] = arrival.outcome
+			delete(pendingWorks, arrival.cellGuid)
+			continue
+		case <-timeout:
+			for guid := range pendingWorks {
+				s.logger.Info("commit-timed-out", lager.Data{"cell-guid": guid, "timeout": s.commitTimeout.String()})
+			}
+		case <-ctx.Done():
+			for guid := range pendingWorks {
+				s.logger.Info("commit-aborted", lager.Data{"cell-guid": guid, "error": ctx.Err().Error()})
+			}
+		}
+		break
+	}
+
+	commitsLock.Lock()
+	stoppedWaiting = true
+	for guid, work := range pendingWorks {
+		if startedCommits[guid] {
+			outcomes[guid] = CommitOutcome{Unknown: work}
+		} else {
+			outcomes[guid] = CommitOutcome{Unsent: work}
+		}
+	}
+	commitsLock.Unlock()
+
+	if stream != nil {
+		for guid := range pendingWorks {
+			stream(outcomes[guid])
+		}
+	}
+	return outcomes
+}
+
+// commitContext bounds a single commit by the commit timeout, if any.
+func (s *Scheduler) commitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.commitTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.commitTimeout)
+}
+
+// abortUncommitted fails the work reserved on cells but not yet sent to them.
+func (s *Scheduler) abortUncommitted(
+	results *auctiontypes.AuctionResults,
+	successfulLRPs map[string]*auctiontypes.LRPAuction,
+	successfulTasks map[string]*auctiontypes.TaskAuction,
+) {
+	for _, cells := range s.zones {
+		for _, cell := range cells {
+			workToCommit := cell.takeWorkToCommit()
+			for i := range workToCommit.LRPs {
+				identifier := workToCommit.LRPs[i].Identifier()
+				if lrpAuction, ok := successfulLRPs[identifier]; ok {
+					delete(successfulLRPs, identifier)
+					lrpAuction.PlacementError = auctiontypes.ErrorAuctionAborted.Error()
+					results.FailedLRPs = append(results.FailedLRPs, *lrpAuction)
+				}
+			}
+			for i := range workToCommit.Tasks {
+				identifier := workToCommit.Tasks[i].Identifier()
+				if taskAuction, ok := successfulTasks[identifier]; ok {
+					delete(successfulTasks, identifier)
+					taskAuction.PlacementError = auctiontypes.ErrorAuctionAborted.Error()
+					results.FailedTasks = append(results.FailedTasks, *taskAuction)
+				}
+			}
+		}
+	}
+}
+
+// cell returns the scheduler's cell with the given guid.
+func (s *Scheduler) cell(guid string) *Cell {
+	for _, zone := range s.zones {
+		for _, cell := range zone {
+			if cell.Guid == guid {
+				return cell
+			}
+		}
+	}
+	return nil
+}
+
+// zonesExcluding returns the scheduler's zones without the given cells,
+// dropping any zone left empty.
+func (s *Scheduler) zonesExcluding(cellGuids map[string]struct{}) map[string]Zone {
+	if len(cellGuids) == 0 {
+		return s.zones
+	}
+
+	zones := map[string]Zone{}
+	for name, zone := range s.zones {
+		filteredZone := Zone{}
+		for _, cell := range zone {
+			if _, excluded := cellGuids[cell.Guid]; !excluded {
+				filteredZone = append(filteredZone, cell)
+			}
+		}
+		if len(filteredZone) > 0 {
+			zones[name] = filteredZone
+		}
+	}
+	return zones
 }
 
-func (s *Scheduler) scheduleLRPAuction(lrpAuction *auctiontypes.LRPAuction) (*auctiontypes.LRPAuction, error) {
+func (s *Scheduler) scheduleLRPAuction(lrpAuction *auctiontypes.LRPAuction, zones map[string]Zone) (*auctiontypes.LRPAuction, error) {
 	var winnerCell *Cell
 
-	zones := accumulateZonesByInstances(s.zones, lrpAuction.ProcessGuid)
+	lrpZones := accumulateZonesByInstances(zones, lrpAuction.ProcessGuid)
 
-	filteredZones, err := applyLRPFilters(zones, lrpAuction, s.auctionType.AuctionFilters...)
+	filteredZones, err := applyLRPFilters(lrpZones, lrpAuction, s.auctionType.AuctionFilters...)
 	if err != nil {
 		return nil, err
 	}
 
 	filteredZones = sortZonesByInstances(filteredZones)
 
-	winnerCell, problems := s.runLRPAuction(filteredZones, lrpAuction)
+	winnerCell, winnerScore, problems := s.runLRPAuction(filteredZones, lrpAuction)
 
 	if winnerCell == nil {
 		return nil, &rep.InsufficientResourcesError{Problems: problems}
@@ -260,10 +743,11 @@ func (s *Scheduler) scheduleLRPAuction(lrpAuction *auctiontypes.LRPAuction) (*au
 
 	winningAuction := lrpAuction.Copy()
 	winningAuction.Winner = winnerCell.Guid
+	s.lrpScores[winningAuction.Identifier()] = winnerScore
 	return &winningAuction, nil
 }
 
-func (s *Scheduler) runLRPAuction(filteredZones []LrpByZone, lrpAuction *auctiontypes.LRPAuction) (*Cell, map[string]struct{}) {
+func (s *Scheduler) runLRPAuction(filteredZones []LrpByZone, lrpAuction *auctiontypes.LRPAuction) (*Cell, float64, map[string]struct{}) {
 	var winnerCell *Cell
 	winnerScore := 1e20
 
@@ -297,16 +781,16 @@ func (s *Scheduler) runLRPAuction(filteredZones []LrpByZone, lrpAuction *auction
 			break
 		}
 	}
-	return winnerCell, problems
+	return winnerCell, winnerScore, problems
 }
 
-func (s *Scheduler) scheduleTaskAuction(taskAuction *auctiontypes.TaskAuction, startingContainerWeight float64) (*auctiontypes.TaskAuction, error) {
-	filteredZones, zoneError := applyTaskFilters(s.zones, taskAuction, s.auctionType.AuctionTaskFilters...)
+func (s *Scheduler) scheduleTaskAuction(taskAuction *auctiontypes.TaskAuction, zones map[string]Zone) (*auctiontypes.TaskAuction, error) {
+	filteredZones, zoneError := applyTaskFilters(zones, taskAuction, s.auctionType.AuctionTaskFilters...)
 	if zoneError != nil {
 		return nil, zoneError
 	}
 
-	winnerCell, problems := s.runTaskAuction(filteredZones, taskAuction)
+	winnerCell, winnerScore, problems := s.runTaskAuction(filteredZones, taskAuction)
 
 	if winnerCell == nil {
 		return nil, &rep.InsufficientResourcesError{Problems: problems}
@@ -320,10 +804,11 @@ func (s *Scheduler) scheduleTaskAuction(taskAuction *auctiontypes.TaskAuction, s
 
 	winningAuction := taskAuction.Copy()
 	winningAuction.Winner = winnerCell.Guid
+	s.taskScores[winningAuction.Identifier()] = winnerScore
 	return &winningAuction, nil
 }
 
-func (s *Scheduler) runTaskAuction(filteredZones []Zone, taskAuction *auctiontypes.TaskAuction) (*Cell, map[string]struct{}) {
+func (s *Scheduler) runTaskAuction(filteredZones []Zone, taskAuction *auctiontypes.TaskAuction) (*Cell, float64, map[string]struct{}) {
 	var winnerCell *Cell
 	winnerScore := 1e20
 
@@ -343,7 +828,7 @@ func (s *Scheduler) runTaskAuction(filteredZones []Zone, taskAuction *auctiontyp
 			}
 		}
 	}
-	return winnerCell, problems
+	return winnerCell, winnerScore, problems
 }
 
 // removeNonApplicableProblems modifies the 'problems' map to remove any problems that didn't show up on err.
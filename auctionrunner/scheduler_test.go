@@ -1,6 +1,8 @@
 package auctionrunner_test
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -10,6 +12,7 @@ import (
 	"code.cloudfoundry.org/auction/auctionfashion"
 	"code.cloudfoundry.org/auction/auctionrunner"
 	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/repfakes"
 
@@ -175,6 +178,22 @@ var _ = Describe("Scheduler", func() {
 			}
 		})
 
+		Context("when reporting the winning scores", func() {
+			It("returns the bid of the winning cell for every placed auction", func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+				unplaceable := BuildLRPAuction("pg-5", "domain", 0, linuxRootFSURL, 1000, 10, 10, clock.Now(), nil, []string{})
+
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType)
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction, unplaceable}})
+				Expect(results.SuccessfulLRPs).To(HaveLen(1))
+
+				lrpScores, taskScores := s.WinningScores()
+				Expect(lrpScores).To(HaveLen(1))
+				Expect(lrpScores).To(HaveKeyWithValue("pg-4.1", BeNumerically(">", 0)))
+				Expect(taskScores).To(BeEmpty())
+			})
+		})
+
 		Context("when only one of many zones supports a specific RootFS", func() {
 			BeforeEach(func() {
 				clients["C-cell"] = &repfakes.FakeSimClient{}
@@ -468,6 +487,432 @@ var _ = Describe("Scheduler", func() {
 			})
 		})
 
+		Context("when the commit to the winning cell fails", func() {
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+				clients["B-cell"].PerformReturns(rep.Work{}, errors.New("timeout"))
+
+				clock.Increment(time.Minute)
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(1))
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+			})
+
+			It("does not re-auction the start auction", func() {
+				Expect(clients["A-cell"].PerformCallCount()).To(Equal(0))
+				Expect(clients["B-cell"].PerformCallCount()).To(Equal(1))
+			})
+
+			It("marks the start auction as unknown rather than succeeded or failed", func() {
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(BeEmpty())
+
+				setLRPWinner("B-cell", &startAuction)
+				startAuction.WaitDuration = time.Minute
+				startAuction.PlacementError = auctiontypes.ErrorCommitOutcomeUnknown.Error()
+				Expect(results.UnknownLRPs).To(ConsistOf(startAuction))
+			})
+		})
+
+		Context("when the winning cell does not answer the commit within the commit timeout", func() {
+			var (
+				blockPerform chan struct{}
+				resultsChan  chan auctiontypes.AuctionResults
+			)
+
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+
+				block := make(chan struct{})
+				blockPerform = block
+				clients["B-cell"].PerformStub = func(lager.Logger, rep.Work) (rep.Work, error) {
+					<-block
+					return rep.Work{}, nil
+				}
+
+				resultsChan = make(chan auctiontypes.AuctionResults, 1)
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitTimeout(time.Second))
+				go func(resultsChan chan<- auctiontypes.AuctionResults, startAuction auctiontypes.LRPAuction) {
+					resultsChan <- s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				}(resultsChan, startAuction)
+			})
+
+			AfterEach(func() {
+				close(blockPerform)
+			})
+
+			It("waits for the commit timeout", func() {
+				Eventually(clock.WatcherCount).Should(Equal(1))
+				Consistently(resultsChan).ShouldNot(Receive())
+			})
+
+			It("marks the start auction as unknown once the timeout elapses", func() {
+				Eventually(clock.WatcherCount).Should(Equal(1))
+				clock.Increment(time.Second)
+
+				var results auctiontypes.AuctionResults
+				Eventually(resultsChan).Should(Receive(&results))
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(BeEmpty())
+				Expect(results.UnknownLRPs).To(HaveLen(1))
+				Expect(results.UnknownLRPs[0].Identifier()).To(Equal(startAuction.Identifier()))
+				Expect(results.UnknownLRPs[0].Winner).To(Equal("B-cell"))
+			})
+		})
+
+		Context("when the winning cell accepts a context and there is a commit timeout", func() {
+			var contextClient *contextSimClient
+
+			BeforeEach(func() {
+				contextClient = newContextSimClient()
+				zones = map[string]auctionrunner.Zone{
+					"C-zone": {auctionrunner.NewCell(logger, "C-cell", contextClient, BuildCellState("C-zone", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}))},
+				}
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitTimeout(time.Minute))
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+			})
+
+			It("bounds the commit by the timeout and cancels it once answered", func() {
+				Expect(results.SuccessfulLRPs).To(HaveLen(1))
+
+				var ctx context.Context
+				Expect(contextClient.contexts).To(Receive(&ctx))
+				deadline, ok := ctx.Deadline()
+				Expect(ok).To(BeTrue())
+				Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), 10*time.Second))
+				Eventually(ctx.Err).Should(Equal(context.Canceled))
+			})
+		})
+
+		Context("when the auction is aborted before committing", func() {
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType)
+				results = s.ScheduleContext(ctx, auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+			})
+
+			It("does not commit to any cell", func() {
+				Expect(clients["A-cell"].PerformCallCount()).To(Equal(0))
+				Expect(clients["B-cell"].PerformCallCount()).To(Equal(0))
+			})
+
+			It("marks the start auction as aborted", func() {
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(HaveLen(1))
+				Expect(results.FailedLRPs[0].Identifier()).To(Equal(startAuction.Identifier()))
+				Expect(results.FailedLRPs[0].PlacementError).To(Equal(auctiontypes.ErrorAuctionAborted.Error()))
+			})
+		})
+
+		Context("when the auction is aborted while the winning cell is committing", func() {
+			var (
+				blockPerform chan struct{}
+				cancel       context.CancelFunc
+				resultsChan  chan auctiontypes.AuctionResults
+			)
+
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+
+				block := make(chan struct{})
+				blockPerform = block
+				clients["B-cell"].PerformStub = func(lager.Logger, rep.Work) (rep.Work, error) {
+					<-block
+					return rep.Work{}, nil
+				}
+
+				var ctx context.Context
+				ctx, cancel = context.WithCancel(context.Background())
+
+				resultsChan = make(chan auctiontypes.AuctionResults, 1)
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType)
+				go func(resultsChan chan<- auctiontypes.AuctionResults, startAuction auctiontypes.LRPAuction) {
+					resultsChan <- s.ScheduleContext(ctx, auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				}(resultsChan, startAuction)
+			})
+
+			AfterEach(func() {
+				close(blockPerform)
+			})
+
+			It("marks the start auction as unknown", func() {
+				Eventually(clients["B-cell"].PerformCallCount).Should(Equal(1))
+				cancel()
+
+				var results auctiontypes.AuctionResults
+				Eventually(resultsChan).Should(Receive(&results))
+				Expect(results.SuccessfulLRPs).To(BeEmpty())
+				Expect(results.FailedLRPs).To(BeEmpty())
+				Expect(results.UnknownLRPs).To(HaveLen(1))
+				Expect(results.UnknownLRPs[0].Winner).To(Equal("B-cell"))
+			})
+		})
+
+		Context("when a metric emitter is provided", func() {
+			var metricEmitter *fakeMetricEmitter
+
+			BeforeEach(func() {
+				metricEmitter = newFakeMetricEmitter()
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithMetricEmitter(metricEmitter))
+				results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+			})
+
+			It("reports the commit duration", func() {
+				Expect(metricEmitter.CommitCompletedCallCount()).To(Equal(1))
+			})
+
+			It("reports the schedule duration", func() {
+				Expect(metricEmitter.ScheduleCompletedCallCount()).To(Equal(1))
+			})
+
+			It("reports the commit duration of each cell that was given work", func() {
+				Expect(metricEmitter.CellCommitCompletedCallCount()).To(Equal(1))
+				cellGuid, _ := metricEmitter.CellCommitCompletedArgsForCall(0)
+				Expect(cellGuid).To(Equal("B-cell"))
+			})
+		})
+
+		Context("when an auction listener is provided", func() {
+			var (
+				listener         *fakes.FakeAuctionListener
+				failuresAtCommit int
+			)
+
+			BeforeEach(func() {
+				listener = new(fakes.FakeAuctionListener)
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+				clients["B-cell"].PerformStub = func(lager.Logger, rep.Work) (rep.Work, error) {
+					failuresAtCommit = listener.LRPFailedCallCount()
+					return rep.Work{}, nil
+				}
+			})
+
+			Context("when auctions are placed or fail to be placed", func() {
+				var hugeAuction auctiontypes.LRPAuction
+
+				BeforeEach(func() {
+					hugeAuction = BuildLRPAuction("pg-5", "domain", 0, linuxRootFSURL, 1000, 1000, 10, clock.Now(), nil, []string{})
+
+					clock.Increment(time.Minute)
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithAuctionListener(listener))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction, hugeAuction}})
+				})
+
+				It("streams the placed auction as it would appear in the results", func() {
+					Expect(listener.LRPPlacedCallCount()).To(Equal(1))
+					Expect(listener.LRPPlacedArgsForCall(0)).To(Equal(results.SuccessfulLRPs[0]))
+					Expect(listener.LRPPlacedArgsForCall(0).Winner).To(Equal("B-cell"))
+					Expect(listener.LRPPlacedArgsForCall(0).WaitDuration).To(Equal(time.Minute))
+				})
+
+				It("streams placement failures before committing", func() {
+					Expect(failuresAtCommit).To(Equal(1))
+					Expect(listener.LRPFailedCallCount()).To(Equal(1))
+					Expect(listener.LRPFailedArgsForCall(0)).To(Equal(results.FailedLRPs[0]))
+					Expect(listener.LRPFailedArgsForCall(0).PlacementError).To(ContainSubstring("insufficient resources"))
+				})
+			})
+
+			Context("when the winning cell rejects the start auction", func() {
+				BeforeEach(func() {
+					clients["B-cell"].PerformStub = nil
+					clients["B-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType,
+						auctionrunner.WithAuctionListener(listener),
+						auctionrunner.WithCommitRetryPasses(1),
+					)
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("streams the rejection and the eventual placement", func() {
+					Expect(listener.LRPCommitRejectedCallCount()).To(Equal(1))
+					Expect(listener.LRPCommitRejectedArgsForCall(0).Winner).To(Equal("B-cell"))
+
+					Expect(listener.LRPPlacedCallCount()).To(Equal(1))
+					Expect(listener.LRPPlacedArgsForCall(0).Winner).To(Equal("A-cell"))
+					Expect(listener.LRPFailedCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when every cell rejects the start auction", func() {
+				BeforeEach(func() {
+					clients["A-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+					clients["B-cell"].PerformStub = nil
+					clients["B-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType,
+						auctionrunner.WithAuctionListener(listener),
+						auctionrunner.WithCommitRetryPasses(1),
+					)
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("streams the retried rejection and a single failure", func() {
+					Expect(listener.LRPCommitRejectedCallCount()).To(Equal(1))
+					Expect(listener.LRPCommitRejectedArgsForCall(0).Winner).To(Equal("B-cell"))
+
+					Expect(listener.LRPFailedCallCount()).To(Equal(1))
+					Expect(listener.LRPFailedArgsForCall(0)).To(Equal(results.FailedLRPs[0]))
+					Expect(listener.LRPPlacedCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the winning cell rejects the start auction without commit retries", func() {
+				BeforeEach(func() {
+					clients["B-cell"].PerformStub = nil
+					clients["B-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithAuctionListener(listener))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("streams the rejection only as a failure", func() {
+					Expect(listener.LRPCommitRejectedCallCount()).To(Equal(0))
+					Expect(listener.LRPFailedCallCount()).To(Equal(1))
+					Expect(listener.LRPFailedArgsForCall(0)).To(Equal(results.FailedLRPs[0]))
+				})
+			})
+
+			Context("when the outcome of the commit is unknown", func() {
+				BeforeEach(func() {
+					clients["B-cell"].PerformStub = nil
+					clients["B-cell"].PerformReturns(rep.Work{}, errors.New("boom"))
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithAuctionListener(listener))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("streams the auction as unknown as it would appear in the results", func() {
+					Expect(results.UnknownLRPs).To(HaveLen(1))
+					Expect(listener.LRPUnknownCallCount()).To(Equal(1))
+					Expect(listener.LRPUnknownArgsForCall(0)).To(Equal(results.UnknownLRPs[0]))
+
+					Expect(listener.LRPPlacedCallCount()).To(Equal(0))
+					Expect(listener.LRPFailedCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when there are no cells", func() {
+				BeforeEach(func() {
+					s := auctionrunner.NewScheduler(workPool, map[string]auctionrunner.Zone{}, clock, logger, 0.0, 0, auctionType, auctionrunner.WithAuctionListener(listener))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("streams every auction as failed", func() {
+					Expect(listener.LRPFailedCallCount()).To(Equal(1))
+					Expect(listener.LRPFailedArgsForCall(0)).To(Equal(results.FailedLRPs[0]))
+				})
+			})
+		})
+
+		Context("when commit retries are enabled", func() {
+			BeforeEach(func() {
+				startAuction = BuildLRPAuction("pg-4", "domain", 1, linuxRootFSURL, 10, 10, 10, clock.Now(), nil, []string{})
+			})
+
+			Context("and the winning cell rejects the start auction", func() {
+				BeforeEach(func() {
+					clients["B-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+
+					clock.Increment(time.Minute)
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(1))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("re-auctions the start auction on the remaining cells", func() {
+					Expect(clients["B-cell"].PerformCallCount()).To(Equal(1))
+					Expect(clients["A-cell"].PerformCallCount()).To(Equal(1))
+
+					_, startsToA := clients["A-cell"].PerformArgsForCall(0)
+					Expect(startsToA.LRPs).To(ConsistOf(startAuction.LRP))
+				})
+
+				It("marks the start auction as succeeded on the new winner", func() {
+					setLRPWinner("A-cell", &startAuction)
+					startAuction.WaitDuration = time.Minute
+					Expect(results.SuccessfulLRPs).To(ConsistOf(startAuction))
+					Expect(results.FailedLRPs).To(BeEmpty())
+				})
+			})
+
+			Context("and every cell rejects the start auction", func() {
+				BeforeEach(func() {
+					clients["A-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+					clients["B-cell"].PerformReturns(rep.Work{LRPs: []rep.LRP{startAuction.LRP}}, nil)
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(5))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction}})
+				})
+
+				It("does not offer the start auction to a cell that already rejected it", func() {
+					Expect(clients["A-cell"].PerformCallCount()).To(Equal(1))
+					Expect(clients["B-cell"].PerformCallCount()).To(Equal(1))
+				})
+
+				It("marks the start auction as failed", func() {
+					Expect(results.SuccessfulLRPs).To(BeEmpty())
+					Expect(results.FailedLRPs).To(HaveLen(1))
+					Expect(results.FailedLRPs[0].Identifier()).To(Equal(startAuction.Identifier()))
+					Expect(results.FailedLRPs[0].Attempts).To(Equal(1))
+				})
+
+				It("reports the start auction as rejected by a cell", func() {
+					Expect(results.FailedLRPs).To(HaveLen(1))
+					Expect(results.FailedLRPs[0].PlacementError).To(Equal(auctiontypes.ErrorRejectedByEveryCell.Error()))
+					Expect(auctiontypes.PlacementFailureReason(results.FailedLRPs[0].PlacementError)).To(Equal("rejected-by-cell"))
+				})
+			})
+
+			Context("and each winning cell rejects its start auction once", func() {
+				var otherAuction auctiontypes.LRPAuction
+
+				rejectFirstPerform := func() func(lager.Logger, rep.Work) (rep.Work, error) {
+					rejectFirst := make(chan struct{}, 1)
+					rejectFirst <- struct{}{}
+					return func(_ lager.Logger, work rep.Work) (rep.Work, error) {
+						select {
+						case <-rejectFirst:
+							return work, nil
+						default:
+							return rep.Work{}, nil
+						}
+					}
+				}
+
+				BeforeEach(func() {
+					startAuction = BuildLRPAuction("pg-5", "domain", 0, linuxRootFSURL, 50, 10, 10, clock.Now(), nil, []string{})
+					otherAuction = BuildLRPAuction("pg-6", "domain", 0, linuxRootFSURL, 50, 10, 10, clock.Now(), nil, []string{})
+					clients["A-cell"].PerformStub = rejectFirstPerform()
+					clients["B-cell"].PerformStub = rejectFirstPerform()
+
+					s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(1))
+					results = s.Schedule(auctiontypes.AuctionRequest{LRPs: []auctiontypes.LRPAuction{startAuction, otherAuction}})
+				})
+
+				It("frees the room reserved on the rejecting cells for the re-auctioned work", func() {
+					Expect(results.FailedLRPs).To(BeEmpty())
+					Expect(results.SuccessfulLRPs).To(HaveLen(2))
+
+					winners := map[string]string{}
+					for _, lrp := range results.SuccessfulLRPs {
+						winners[lrp.Identifier()] = lrp.Winner
+					}
+					Expect(winners).To(Equal(map[string]string{
+						startAuction.Identifier(): "A-cell",
+						otherAuction.Identifier(): "B-cell",
+					}))
+				})
+			})
+		})
+
 		Context("when the startingContainerCountMaximum is set", func() {
 
 			var (
@@ -809,6 +1254,58 @@ var _ = Describe("Scheduler", func() {
 			})
 		})
 
+		Context("when the commit of the task fails", func() {
+			BeforeEach(func() {
+				clients["B-cell"].PerformReturns(rep.Work{}, errors.New("timeout"))
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType)
+				results = s.Schedule(auctiontypes.AuctionRequest{Tasks: []auctiontypes.TaskAuction{taskAuction}})
+			})
+
+			It("marks the task auction as unknown", func() {
+				Expect(results.SuccessfulTasks).To(BeEmpty())
+				Expect(results.FailedTasks).To(BeEmpty())
+
+				Expect(results.UnknownTasks).To(HaveLen(1))
+				unknownTask := results.UnknownTasks[0]
+				Expect(unknownTask.Winner).To(Equal("B-cell"))
+				Expect(unknownTask.Attempts).To(Equal(1))
+				Expect(unknownTask.PlacementError).To(Equal(auctiontypes.ErrorCommitOutcomeUnknown.Error()))
+			})
+		})
+
+		Context("when the cell rejects the task and commit retries are enabled", func() {
+			BeforeEach(func() {
+				clients["B-cell"].PerformReturns(rep.Work{Tasks: []rep.Task{taskAuction.Task}}, nil)
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(1))
+				results = s.Schedule(auctiontypes.AuctionRequest{Tasks: []auctiontypes.TaskAuction{taskAuction}})
+			})
+
+			It("places the task on another cell", func() {
+				Expect(clients["A-cell"].PerformCallCount()).To(Equal(1))
+				_, tasksToA := clients["A-cell"].PerformArgsForCall(0)
+				Expect(tasksToA.Tasks).To(ConsistOf(taskAuction.Task))
+
+				Expect(results.FailedTasks).To(BeEmpty())
+				Expect(results.SuccessfulTasks).To(HaveLen(1))
+				Expect(results.SuccessfulTasks[0].Winner).To(Equal("A-cell"))
+			})
+		})
+
+		Context("when every cell rejects the task and commit retries are enabled", func() {
+			BeforeEach(func() {
+				clients["A-cell"].PerformReturns(rep.Work{Tasks: []rep.Task{taskAuction.Task}}, nil)
+				clients["B-cell"].PerformReturns(rep.Work{Tasks: []rep.Task{taskAuction.Task}}, nil)
+				s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType, auctionrunner.WithCommitRetryPasses(5))
+				results = s.Schedule(auctiontypes.AuctionRequest{Tasks: []auctiontypes.TaskAuction{taskAuction}})
+			})
+
+			It("reports the task as rejected by every cell", func() {
+				Expect(results.SuccessfulTasks).To(BeEmpty())
+				Expect(results.FailedTasks).To(HaveLen(1))
+				Expect(results.FailedTasks[0].PlacementError).To(Equal(auctiontypes.ErrorRejectedByEveryCell.Error()))
+			})
+		})
+
 		Context("when there is no room", func() {
 			var requestedDisk int32
 
@@ -920,6 +1417,112 @@ var _ = Describe("Scheduler", func() {
 		})
 	})
 
+	Context("when the commit pool is saturated and commits time out before a retry pass", func() {
+		var (
+			commitPool  *workpool.WorkPool
+			performs    chan rep.Work
+			release     chan struct{}
+			cellClients []*repfakes.FakeSimClient
+			auctions    []auctiontypes.LRPAuction
+			resultsChan chan auctiontypes.AuctionResults
+		)
+
+		BeforeEach(func() {
+			var err error
+			commitPool, err = workpool.NewWorkPool(1)
+			Expect(err).NotTo(HaveOccurred())
+
+			performs = make(chan rep.Work, 10)
+			release = make(chan struct{})
+			rejectFirst := make(chan struct{}, 1)
+			rejectFirst <- struct{}{}
+
+			// whichever cell commits first rejects its work, and every later
+			// commit holds the only worker until released
+			perform := func(_ lager.Logger, work rep.Work) (rep.Work, error) {
+				performs <- work
+				select {
+				case <-rejectFirst:
+					return work, nil
+				default:
+				}
+				<-release
+				return rep.Work{}, nil
+			}
+
+			cellClients = nil
+			for _, name := range []string{"A", "B", "C"} {
+				client := &repfakes.FakeSimClient{}
+				client.PerformStub = perform
+				cellClients = append(cellClients, client)
+				zones[name+"-zone"] = auctionrunner.Zone{
+					auctionrunner.NewCell(logger, name+"-cell", client, BuildCellState(name+"-zone", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{})),
+				}
+			}
+
+			auctions = []auctiontypes.LRPAuction{
+				BuildLRPAuction("pg-1", "domain", 0, linuxRootFSURL, 40, 40, 10, clock.Now(), nil, []string{}),
+				BuildLRPAuction("pg-2", "domain", 0, linuxRootFSURL, 40, 40, 10, clock.Now(), nil, []string{}),
+				BuildLRPAuction("pg-3", "domain", 0, linuxRootFSURL, 40, 40, 10, clock.Now(), nil, []string{}),
+			}
+
+			s := auctionrunner.NewScheduler(workPool, zones, clock, logger, 0.0, 0, auctionType,
+				auctionrunner.WithCommitWorkPool(commitPool),
+				auctionrunner.WithCommitTimeout(time.Second),
+				auctionrunner.WithCommitRetryPasses(1),
+			)
+			resultsChan = make(chan auctiontypes.AuctionResults, 1)
+			go func() {
+				resultsChan <- s.Schedule(auctiontypes.AuctionRequest{LRPs: auctions})
+			}()
+		})
+
+		AfterEach(func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+			commitPool.Stop()
+		})
+
+		It("reports every auction once and never sends the queued work", func() {
+			By("timing out while the last cell's commit is still queued")
+			Eventually(performs).Should(Receive())
+			Eventually(performs).Should(Receive())
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			clock.Increment(time.Second)
+
+			By("timing out the retry pass while its commits are still queued")
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			clock.Increment(time.Second)
+
+			var results auctiontypes.AuctionResults
+			Eventually(resultsChan).Should(Receive(&results))
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(results.UnknownLRPs).To(HaveLen(1))
+			Expect(results.FailedLRPs).To(HaveLen(2))
+			for _, lrp := range results.FailedLRPs {
+				Expect(lrp.PlacementError).To(Equal(auctiontypes.ErrorCommitTimedOut.Error()))
+			}
+			reported := []string{results.UnknownLRPs[0].Identifier()}
+			for _, lrp := range results.FailedLRPs {
+				reported = append(reported, lrp.Identifier())
+			}
+			Expect(reported).To(ConsistOf(auctions[0].Identifier(), auctions[1].Identifier(), auctions[2].Identifier()))
+
+			By("not sending the queued work once the worker is released")
+			close(release)
+			Consistently(performs).ShouldNot(Receive())
+
+			performed := 0
+			for _, client := range cellClients {
+				performed += client.PerformCallCount()
+			}
+			Expect(performed).To(Equal(2))
+		})
+	})
+
 	Describe("a comprehensive scenario", func() {
 		BeforeEach(func() {
 			clients["A-cell"] = &repfakes.FakeSimClient{}
@@ -1,15 +1,61 @@
 package auctionrunner_test
 
 import (
+	"context"
 	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/auctioneer"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
 	. "github.com/onsi/gomega"
 )
 
+// fakeMetricEmitter implements AuctionMetricEmitterDelegate along with every
+// optional metric emitter.
+type fakeMetricEmitter struct {
+	*fakes.FakeAuctionMetricEmitterDelegate
+	*fakes.FakeCommitMetricEmitter
+	*fakes.FakeRoundMetricEmitter
+	*fakes.FakeQueueMetricEmitter
+}
+
+func newFakeMetricEmitter() *fakeMetricEmitter {
+	return &fakeMetricEmitter{
+		FakeAuctionMetricEmitterDelegate: &fakes.FakeAuctionMetricEmitterDelegate{},
+		FakeCommitMetricEmitter:          &fakes.FakeCommitMetricEmitter{},
+		FakeRoundMetricEmitter:           &fakes.FakeRoundMetricEmitter{},
+		FakeQueueMetricEmitter:           &fakes.FakeQueueMetricEmitter{},
+	}
+}
+
+// contextSimClient is a FakeSimClient that also implements
+// auctionrunner.ContextClient, recording the contexts it was handed.
+type contextSimClient struct {
+	*repfakes.FakeSimClient
+	contexts chan context.Context
+}
+
+func newContextSimClient() *contextSimClient {
+	return &contextSimClient{
+		FakeSimClient: &repfakes.FakeSimClient{},
+		contexts:      make(chan context.Context, 10),
+	}
+}
+
+func (c *contextSimClient) StateContext(ctx context.Context, logger lager.Logger) (rep.CellState, error) {
+	c.contexts <- ctx
+	return c.State(logger)
+}
+
+func (c *contextSimClient) PerformContext(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error) {
+	c.contexts <- ctx
+	return c.Perform(logger, work)
+}
+
 func BuildLRPStartRequest(
 	processGuid, domain string,
 	indices []int,
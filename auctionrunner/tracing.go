@@ -0,0 +1,53 @@
+package auctionrunner
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/rep"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...auctiontypes.Attribute) {}
+func (noopSpan) RecordError(error)                       {}
+func (noopSpan) End()                                    {}
+
+// startSpan starts a span on the tracer carried by ctx, if there is one.
+func startSpan(ctx context.Context, name string, attributes ...auctiontypes.Attribute) (context.Context, auctiontypes.Span) {
+	tracer := auctiontypes.TracerFromContext(ctx)
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name, attributes...)
+}
+
+func auctionGuidAttributes(lrps []auctiontypes.LRPAuction, tasks []auctiontypes.TaskAuction) []auctiontypes.Attribute {
+	lrpGuids := make([]string, 0, len(lrps))
+	for i := range lrps {
+		lrpGuids = append(lrpGuids, lrps[i].Identifier())
+	}
+	taskGuids := make([]string, 0, len(tasks))
+	for i := range tasks {
+		taskGuids = append(taskGuids, tasks[i].Identifier())
+	}
+	return []auctiontypes.Attribute{
+		auctiontypes.NewAttribute("lrp-guids", lrpGuids),
+		auctiontypes.NewAttribute("task-guids", taskGuids),
+	}
+}
+
+func workGuidAttributes(work rep.Work) []auctiontypes.Attribute {
+	lrpGuids := make([]string, 0, len(work.LRPs))
+	for i := range work.LRPs {
+		lrpGuids = append(lrpGuids, work.LRPs[i].Identifier())
+	}
+	taskGuids := make([]string, 0, len(work.Tasks))
+	for i := range work.Tasks {
+		taskGuids = append(taskGuids, work.Tasks[i].Identifier())
+	}
+	return []auctiontypes.Attribute{
+		auctiontypes.NewAttribute("lrp-guids", lrpGuids),
+		auctiontypes.NewAttribute("task-guids", taskGuids),
+	}
+}
@@ -1,6 +1,7 @@
 package auctionrunner
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
@@ -10,12 +11,22 @@ import (
 )
 
 func FetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, clients map[string]rep.Client, metricEmitter auctiontypes.AuctionMetricEmitterDelegate) map[string]Zone {
+	return FetchStateAndBuildZonesContext(context.Background(), logger, workPool, clients, metricEmitter)
+}
+
+// FetchStateAndBuildZonesContext stops waiting for cell states once ctx is
+// done, building zones from the cells that have answered so far.
+func FetchStateAndBuildZonesContext(ctx context.Context, logger lager.Logger, workPool *workpool.WorkPool, clients map[string]rep.Client, metricEmitter auctiontypes.AuctionMetricEmitterDelegate) map[string]Zone {
 	var zones map[string]Zone
 	for i := 0; ; i++ {
-		zones = fetchStateAndBuildZones(logger, workPool, clients, metricEmitter)
+		zones = fetchStateAndBuildZones(ctx, logger, workPool, clients, metricEmitter)
 		if len(zones) > 0 {
 			break
 		}
+		if ctx.Err() != nil {
+			logger.Info("failed-to-communicate-to-cells-aborted", lager.Data{"error": ctx.Err().Error()})
+			break
+		}
 		if i == 3 {
 			logger.Info("failed-to-communicate-to-cells-abort")
 			break
@@ -25,18 +36,23 @@ func FetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, c
 	return zones
 }
 
-func fetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, clients map[string]rep.Client, metricEmitter auctiontypes.AuctionMetricEmitterDelegate) map[string]Zone {
+func fetchStateAndBuildZones(ctx context.Context, logger lager.Logger, workPool *workpool.WorkPool, clients map[string]rep.Client, metricEmitter auctiontypes.AuctionMetricEmitterDelegate) map[string]Zone {
 	wg := &sync.WaitGroup{}
 	zones := map[string]Zone{}
 	lock := &sync.Mutex{}
+	abandoned := false
 
 	wg.Add(len(clients))
 	for guid, client := range clients {
 		guid, client := guid, client
 		workPool.Submit(func() {
 			defer wg.Done()
-			state, err := client.State(logger)
+			ctx, span := startSpan(ctx, "fetch-cell-state", auctiontypes.NewAttribute("cell-guid", guid))
+			defer span.End()
+
+			state, err := fetchState(ctx, logger, client)
 			if err != nil {
+				span.RecordError(err)
 				metricEmitter.FailedCellStateRequest()
 				logger.Error("failed-to-get-state", err, lager.Data{"cell-guid": guid})
 				return
@@ -48,12 +64,61 @@ func fetchStateAndBuildZones(logger lager.Logger, workPool *workpool.WorkPool, c
 
 			cell := NewCell(logger, guid, client, state)
 			lock.Lock()
-			zones[state.Zone] = append(zones[state.Zone], cell)
+			if !abandoned {
+				zones[state.Zone] = append(zones[state.Zone], cell)
+			}
 			lock.Unlock()
 		})
 	}
 
-	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Info("stopped-waiting-for-cell-states", lager.Data{"error": ctx.Err().Error()})
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	abandoned = true
 
 	return zones
 }
+
+func fetchState(ctx context.Context, logger lager.Logger, client rep.Client) (rep.CellState, error) {
+	if contextClient, ok := client.(ContextClient); ok {
+		return contextClient.StateContext(ctx, logger)
+	}
+	return client.State(logger)
+}
+
+// ApplyInflightResults accounts work placed by a round whose commit overlapped
+// with the fetching of zones. Work already reported in a cell's state is not
+// counted twice.
+func ApplyInflightResults(zones map[string]Zone, results auctiontypes.AuctionResults) {
+	cells := map[string]*Cell{}
+	for _, zone := range zones {
+		for _, cell := range zone {
+			cells[cell.Guid] = cell
+		}
+	}
+
+	lrps := append(append([]auctiontypes.LRPAuction{}, results.SuccessfulLRPs...), results.UnknownLRPs...)
+	for i := range lrps {
+		if cell, ok := cells[lrps[i].Winner]; ok {
+			cell.addInflightLRP(&lrps[i].LRP)
+		}
+	}
+
+	tasks := append(append([]auctiontypes.TaskAuction{}, results.SuccessfulTasks...), results.UnknownTasks...)
+	for i := range tasks {
+		if cell, ok := cells[tasks[i].Winner]; ok {
+			cell.addInflightTask(&tasks[i].Task)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package auctionrunner_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
 
 	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
@@ -137,4 +139,118 @@ var _ = Describe("ZoneBuilder", func() {
 			}
 		})
 	})
+
+	Context("when the context is done", func() {
+		var blockState chan struct{}
+
+		BeforeEach(func() {
+			blockState = make(chan struct{})
+			repA.StateStub = func(lager.Logger) (rep.CellState, error) {
+				<-blockState
+				return BuildCellState("the-zone", 100, 200, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil
+			}
+		})
+
+		AfterEach(func() {
+			close(blockState)
+		})
+
+		It("returns the cells that answered without waiting for the rest", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			zonesChan := make(chan map[string]auctionrunner.Zone, 1)
+			go func() {
+				zonesChan <- auctionrunner.FetchStateAndBuildZonesContext(ctx, logger, workPool, clients, metricEmitter)
+			}()
+
+			Eventually(repB.StateCallCount).Should(Equal(1))
+			Eventually(repC.StateCallCount).Should(Equal(1))
+			Consistently(zonesChan).ShouldNot(Receive())
+
+			cancel()
+
+			var zones map[string]auctionrunner.Zone
+			Eventually(zonesChan).Should(Receive(&zones))
+			Expect(zones["the-zone"]).To(HaveLen(1))
+			Expect(zones["other-zone"]).To(HaveLen(1))
+		})
+	})
+
+	Context("when a client accepts a context", func() {
+		It("passes the context to the client", func() {
+			contextClient := newContextSimClient()
+			contextClient.StateReturns(BuildCellState("the-zone", 100, 200, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}), nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			zones := auctionrunner.FetchStateAndBuildZonesContext(ctx, logger, workPool, map[string]rep.Client{"D": contextClient}, metricEmitter)
+			Expect(zones["the-zone"]).To(HaveLen(1))
+			Expect(contextClient.contexts).To(Receive(Equal(ctx)))
+		})
+	})
+})
+
+var _ = Describe("ApplyInflightResults", func() {
+	var (
+		zones      map[string]auctionrunner.Zone
+		cellA      *auctionrunner.Cell
+		cellB      *auctionrunner.Cell
+		placedLRP  auctiontypes.LRPAuction
+		placedTask auctiontypes.TaskAuction
+	)
+
+	BeforeEach(func() {
+		cellA = auctionrunner.NewCell(logger, "A", nil, BuildCellState("the-zone", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, nil, []string{}, []string{}, []string{}))
+		cellB = auctionrunner.NewCell(logger, "B", nil, BuildCellState("other-zone", 100, 100, 100, false, 0, linuxOnlyRootFSProviders, []rep.LRP{
+			*BuildLRP("pg-1", "domain", 1, linuxRootFSURL, 10, 10, 10, []string{}),
+		}, []string{}, []string{}, []string{}))
+		zones = map[string]auctionrunner.Zone{
+			"the-zone":   {cellA},
+			"other-zone": {cellB},
+		}
+
+		placedLRP = BuildLRPAuction("pg-1", "domain", 0, linuxRootFSURL, 10, 10, 10, time.Now(), nil, []string{})
+		placedLRP.Winner = "A"
+		placedTask = BuildTaskAuction(BuildTask("tg-1", "domain", linuxRootFSURL, 20, 20, 10, []string{}, []string{}), time.Now())
+		placedTask.Winner = "A"
+	})
+
+	It("reserves work the fetched states do not know about yet", func() {
+		auctionrunner.ApplyInflightResults(zones, auctiontypes.AuctionResults{
+			SuccessfulLRPs: []auctiontypes.LRPAuction{placedLRP},
+			UnknownTasks:   []auctiontypes.TaskAuction{placedTask},
+		})
+
+		Expect(cellA.State.LRPs).To(ConsistOf(placedLRP.LRP))
+		Expect(cellA.State.Tasks).To(ConsistOf(placedTask.Task))
+		Expect(cellA.State.AvailableResources.MemoryMB).To(BeEquivalentTo(70))
+		Expect(cellA.StartingContainerCount()).To(Equal(2))
+	})
+
+	It("does not reserve work already reported by the cell", func() {
+		alreadyStarted := BuildLRPAuction("pg-1", "domain", 1, linuxRootFSURL, 10, 10, 10, time.Now(), nil, []string{})
+		alreadyStarted.Winner = "B"
+
+		auctionrunner.ApplyInflightResults(zones, auctiontypes.AuctionResults{
+			SuccessfulLRPs: []auctiontypes.LRPAuction{alreadyStarted},
+		})
+
+		Expect(cellB.State.LRPs).To(HaveLen(1))
+		Expect(cellB.State.AvailableResources.MemoryMB).To(BeEquivalentTo(90))
+	})
+
+	It("ignores failed work and cells that are no longer present", func() {
+		failedLRP := placedLRP
+		failedLRP.Winner = ""
+		goneLRP := placedLRP
+		goneLRP.Winner = "C"
+
+		auctionrunner.ApplyInflightResults(zones, auctiontypes.AuctionResults{
+			SuccessfulLRPs: []auctiontypes.LRPAuction{goneLRP},
+			FailedLRPs:     []auctiontypes.LRPAuction{failedLRP},
+		})
+
+		Expect(cellA.State.LRPs).To(BeEmpty())
+		Expect(cellB.State.LRPs).To(HaveLen(1))
+	})
 })
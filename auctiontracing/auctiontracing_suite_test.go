@@ -0,0 +1,13 @@
+package auctiontracing_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAuctiontracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Auctiontracing Suite")
+}
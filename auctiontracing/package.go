@@ -0,0 +1 @@
+package auctiontracing // import "code.cloudfoundry.org/auction/auctiontracing"
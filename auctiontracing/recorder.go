@@ -0,0 +1,130 @@
+package auctiontracing
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+// RecordedSpan is a copy of a span held by a Recorder. Spans are numbered
+// from 1 in the order they were started; root spans have a ParentID of 0.
+type RecordedSpan struct {
+	ID         int
+	ParentID   int
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+}
+
+// Recorder is an auctiontypes.Tracer that keeps every span in memory.
+type Recorder struct {
+	lock  *sync.Mutex
+	spans []*RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{lock: &sync.Mutex{}}
+}
+
+type spanKey struct{}
+
+func (r *Recorder) Start(ctx context.Context, name string, attributes ...auctiontypes.Attribute) (context.Context, auctiontypes.Span) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	recorded := &RecordedSpan{
+		ID:         len(r.spans) + 1,
+		Name:       name,
+		Attributes: map[string]interface{}{},
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok && parent.recorder == r {
+		recorded.ParentID = parent.id
+	}
+	for _, attribute := range attributes {
+		recorded.Attributes[attribute.Key] = attribute.Value
+	}
+	r.spans = append(r.spans, recorded)
+
+	s := &span{recorder: r, id: recorded.ID}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Spans returns every span started so far.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, recorded := range r.spans {
+		spans = append(spans, recorded.copy())
+	}
+	return spans
+}
+
+// SpansNamed returns the spans started with the given name.
+func (r *Recorder) SpansNamed(name string) []RecordedSpan {
+	spans := []RecordedSpan{}
+	for _, recorded := range r.Spans() {
+		if recorded.Name == name {
+			spans = append(spans, recorded)
+		}
+	}
+	return spans
+}
+
+// Children returns the spans started directly beneath the span with the
+// given ID.
+func (r *Recorder) Children(id int) []RecordedSpan {
+	spans := []RecordedSpan{}
+	for _, recorded := range r.Spans() {
+		if recorded.ParentID == id {
+			spans = append(spans, recorded)
+		}
+	}
+	return spans
+}
+
+func (r *Recorder) update(id int, update func(*RecordedSpan)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	update(r.spans[id-1])
+}
+
+func (s RecordedSpan) copy() RecordedSpan {
+	attributes := make(map[string]interface{}, len(s.Attributes))
+	for key, value := range s.Attributes {
+		attributes[key] = value
+	}
+	s.Attributes = attributes
+	s.Errors = append([]error(nil), s.Errors...)
+	return s
+}
+
+type span struct {
+	recorder *Recorder
+	id       int
+}
+
+func (s *span) SetAttributes(attributes ...auctiontypes.Attribute) {
+	s.recorder.update(s.id, func(recorded *RecordedSpan) {
+		for _, attribute := range attributes {
+			recorded.Attributes[attribute.Key] = attribute.Value
+		}
+	})
+}
+
+func (s *span) RecordError(err error) {
+	s.recorder.update(s.id, func(recorded *RecordedSpan) {
+		recorded.Errors = append(recorded.Errors, err)
+	})
+}
+
+func (s *span) End() {
+	s.recorder.update(s.id, func(recorded *RecordedSpan) {
+		recorded.Ended = true
+	})
+}
+
+var _ auctiontypes.Tracer = (*Recorder)(nil)
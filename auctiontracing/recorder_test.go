@@ -0,0 +1,77 @@
+package auctiontracing_test
+
+import (
+	"context"
+	"errors"
+
+	"code.cloudfoundry.org/auction/auctiontracing"
+	"code.cloudfoundry.org/auction/auctiontypes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+	var recorder *auctiontracing.Recorder
+
+	BeforeEach(func() {
+		recorder = auctiontracing.NewRecorder()
+	})
+
+	It("records spans with their attributes", func() {
+		_, span := recorder.Start(context.Background(), "round", auctiontypes.NewAttribute("lrp-guids", []string{"pg-1.0"}))
+		span.SetAttributes(auctiontypes.NewAttribute("cells", 3))
+
+		spans := recorder.Spans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].ID).To(Equal(1))
+		Expect(spans[0].ParentID).To(BeZero())
+		Expect(spans[0].Name).To(Equal("round"))
+		Expect(spans[0].Attributes).To(Equal(map[string]interface{}{
+			"lrp-guids": []string{"pg-1.0"},
+			"cells":     3,
+		}))
+		Expect(spans[0].Ended).To(BeFalse())
+	})
+
+	It("records errors and the end of a span", func() {
+		_, span := recorder.Start(context.Background(), "round")
+		span.RecordError(errors.New("boom"))
+		span.End()
+
+		spans := recorder.Spans()
+		Expect(spans[0].Errors).To(ConsistOf(MatchError("boom")))
+		Expect(spans[0].Ended).To(BeTrue())
+	})
+
+	It("parents spans started from a span's context", func() {
+		ctx, _ := recorder.Start(context.Background(), "round")
+		childCtx, _ := recorder.Start(ctx, "schedule")
+		recorder.Start(childCtx, "commit")
+		recorder.Start(ctx, "notify-delegate")
+
+		Expect(recorder.Children(1)).To(HaveLen(2))
+		Expect(recorder.Children(1)[0].Name).To(Equal("schedule"))
+		Expect(recorder.Children(1)[1].Name).To(Equal("notify-delegate"))
+		Expect(recorder.Children(2)).To(HaveLen(1))
+		Expect(recorder.Children(2)[0].Name).To(Equal("commit"))
+		Expect(recorder.Children(3)).To(BeEmpty())
+	})
+
+	It("ignores spans started by another recorder", func() {
+		ctx, _ := auctiontracing.NewRecorder().Start(context.Background(), "round")
+		recorder.Start(ctx, "schedule")
+
+		Expect(recorder.Spans()[0].ParentID).To(BeZero())
+	})
+
+	It("returns copies of the recorded spans", func() {
+		_, span := recorder.Start(context.Background(), "round")
+		spans := recorder.Spans()
+		spans[0].Attributes["changed"] = true
+
+		span.SetAttributes(auctiontypes.NewAttribute("cells", 3))
+		Expect(recorder.Spans()[0].Attributes).To(Equal(map[string]interface{}{"cells": 3}))
+		Expect(spans[0].Attributes).NotTo(HaveKey("cells"))
+	})
+})
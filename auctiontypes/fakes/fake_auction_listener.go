@@ -0,0 +1,237 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+type FakeAuctionListener struct {
+	LRPPlacedStub        func(auctiontypes.LRPAuction)
+	lRPPlacedMutex       sync.RWMutex
+	lRPPlacedArgsForCall []struct {
+		arg1 auctiontypes.LRPAuction
+	}
+	TaskPlacedStub        func(auctiontypes.TaskAuction)
+	taskPlacedMutex       sync.RWMutex
+	taskPlacedArgsForCall []struct {
+		arg1 auctiontypes.TaskAuction
+	}
+	LRPFailedStub        func(auctiontypes.LRPAuction)
+	lRPFailedMutex       sync.RWMutex
+	lRPFailedArgsForCall []struct {
+		arg1 auctiontypes.LRPAuction
+	}
+	TaskFailedStub        func(auctiontypes.TaskAuction)
+	taskFailedMutex       sync.RWMutex
+	taskFailedArgsForCall []struct {
+		arg1 auctiontypes.TaskAuction
+	}
+	LRPUnknownStub        func(auctiontypes.LRPAuction)
+	lRPUnknownMutex       sync.RWMutex
+	lRPUnknownArgsForCall []struct {
+		arg1 auctiontypes.LRPAuction
+	}
+	TaskUnknownStub        func(auctiontypes.TaskAuction)
+	taskUnknownMutex       sync.RWMutex
+	taskUnknownArgsForCall []struct {
+		arg1 auctiontypes.TaskAuction
+	}
+	LRPCommitRejectedStub        func(auctiontypes.LRPAuction)
+	lRPCommitRejectedMutex       sync.RWMutex
+	lRPCommitRejectedArgsForCall []struct {
+		arg1 auctiontypes.LRPAuction
+	}
+	TaskCommitRejectedStub        func(auctiontypes.TaskAuction)
+	taskCommitRejectedMutex       sync.RWMutex
+	taskCommitRejectedArgsForCall []struct {
+		arg1 auctiontypes.TaskAuction
+	}
+}
+
+func (fake *FakeAuctionListener) LRPPlaced(arg1 auctiontypes.LRPAuction) {
+	fake.lRPPlacedMutex.Lock()
+	fake.lRPPlacedArgsForCall = append(fake.lRPPlacedArgsForCall, struct {
+		arg1 auctiontypes.LRPAuction
+	}{arg1})
+	fake.lRPPlacedMutex.Unlock()
+	if fake.LRPPlacedStub != nil {
+		fake.LRPPlacedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) LRPPlacedCallCount() int {
+	fake.lRPPlacedMutex.RLock()
+	defer fake.lRPPlacedMutex.RUnlock()
+	return len(fake.lRPPlacedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) LRPPlacedArgsForCall(i int) auctiontypes.LRPAuction {
+	fake.lRPPlacedMutex.RLock()
+	defer fake.lRPPlacedMutex.RUnlock()
+	return fake.lRPPlacedArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) TaskPlaced(arg1 auctiontypes.TaskAuction) {
+	fake.taskPlacedMutex.Lock()
+	fake.taskPlacedArgsForCall = append(fake.taskPlacedArgsForCall, struct {
+		arg1 auctiontypes.TaskAuction
+	}{arg1})
+	fake.taskPlacedMutex.Unlock()
+	if fake.TaskPlacedStub != nil {
+		fake.TaskPlacedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) TaskPlacedCallCount() int {
+	fake.taskPlacedMutex.RLock()
+	defer fake.taskPlacedMutex.RUnlock()
+	return len(fake.taskPlacedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) TaskPlacedArgsForCall(i int) auctiontypes.TaskAuction {
+	fake.taskPlacedMutex.RLock()
+	defer fake.taskPlacedMutex.RUnlock()
+	return fake.taskPlacedArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) LRPFailed(arg1 auctiontypes.LRPAuction) {
+	fake.lRPFailedMutex.Lock()
+	fake.lRPFailedArgsForCall = append(fake.lRPFailedArgsForCall, struct {
+		arg1 auctiontypes.LRPAuction
+	}{arg1})
+	fake.lRPFailedMutex.Unlock()
+	if fake.LRPFailedStub != nil {
+		fake.LRPFailedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) LRPFailedCallCount() int {
+	fake.lRPFailedMutex.RLock()
+	defer fake.lRPFailedMutex.RUnlock()
+	return len(fake.lRPFailedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) LRPFailedArgsForCall(i int) auctiontypes.LRPAuction {
+	fake.lRPFailedMutex.RLock()
+	defer fake.lRPFailedMutex.RUnlock()
+	return fake.lRPFailedArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) TaskFailed(arg1 auctiontypes.TaskAuction) {
+	fake.taskFailedMutex.Lock()
+	fake.taskFailedArgsForCall = append(fake.taskFailedArgsForCall, struct {
+		arg1 auctiontypes.TaskAuction
+	}{arg1})
+	fake.taskFailedMutex.Unlock()
+	if fake.TaskFailedStub != nil {
+		fake.TaskFailedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) TaskFailedCallCount() int {
+	fake.taskFailedMutex.RLock()
+	defer fake.taskFailedMutex.RUnlock()
+	return len(fake.taskFailedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) TaskFailedArgsForCall(i int) auctiontypes.TaskAuction {
+	fake.taskFailedMutex.RLock()
+	defer fake.taskFailedMutex.RUnlock()
+	return fake.taskFailedArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) LRPUnknown(arg1 auctiontypes.LRPAuction) {
+	fake.lRPUnknownMutex.Lock()
+	fake.lRPUnknownArgsForCall = append(fake.lRPUnknownArgsForCall, struct {
+		arg1 auctiontypes.LRPAuction
+	}{arg1})
+	fake.lRPUnknownMutex.Unlock()
+	if fake.LRPUnknownStub != nil {
+		fake.LRPUnknownStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) LRPUnknownCallCount() int {
+	fake.lRPUnknownMutex.RLock()
+	defer fake.lRPUnknownMutex.RUnlock()
+	return len(fake.lRPUnknownArgsForCall)
+}
+
+func (fake *FakeAuctionListener) LRPUnknownArgsForCall(i int) auctiontypes.LRPAuction {
+	fake.lRPUnknownMutex.RLock()
+	defer fake.lRPUnknownMutex.RUnlock()
+	return fake.lRPUnknownArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) TaskUnknown(arg1 auctiontypes.TaskAuction) {
+	fake.taskUnknownMutex.Lock()
+	fake.taskUnknownArgsForCall = append(fake.taskUnknownArgsForCall, struct {
+		arg1 auctiontypes.TaskAuction
+	}{arg1})
+	fake.taskUnknownMutex.Unlock()
+	if fake.TaskUnknownStub != nil {
+		fake.TaskUnknownStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) TaskUnknownCallCount() int {
+	fake.taskUnknownMutex.RLock()
+	defer fake.taskUnknownMutex.RUnlock()
+	return len(fake.taskUnknownArgsForCall)
+}
+
+func (fake *FakeAuctionListener) TaskUnknownArgsForCall(i int) auctiontypes.TaskAuction {
+	fake.taskUnknownMutex.RLock()
+	defer fake.taskUnknownMutex.RUnlock()
+	return fake.taskUnknownArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) LRPCommitRejected(arg1 auctiontypes.LRPAuction) {
+	fake.lRPCommitRejectedMutex.Lock()
+	fake.lRPCommitRejectedArgsForCall = append(fake.lRPCommitRejectedArgsForCall, struct {
+		arg1 auctiontypes.LRPAuction
+	}{arg1})
+	fake.lRPCommitRejectedMutex.Unlock()
+	if fake.LRPCommitRejectedStub != nil {
+		fake.LRPCommitRejectedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) LRPCommitRejectedCallCount() int {
+	fake.lRPCommitRejectedMutex.RLock()
+	defer fake.lRPCommitRejectedMutex.RUnlock()
+	return len(fake.lRPCommitRejectedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) LRPCommitRejectedArgsForCall(i int) auctiontypes.LRPAuction {
+	fake.lRPCommitRejectedMutex.RLock()
+	defer fake.lRPCommitRejectedMutex.RUnlock()
+	return fake.lRPCommitRejectedArgsForCall[i].arg1
+}
+
+func (fake *FakeAuctionListener) TaskCommitRejected(arg1 auctiontypes.TaskAuction) {
+	fake.taskCommitRejectedMutex.Lock()
+	fake.taskCommitRejectedArgsForCall = append(fake.taskCommitRejectedArgsForCall, struct {
+		arg1 auctiontypes.TaskAuction
+	}{arg1})
+	fake.taskCommitRejectedMutex.Unlock()
+	if fake.TaskCommitRejectedStub != nil {
+		fake.TaskCommitRejectedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionListener) TaskCommitRejectedCallCount() int {
+	fake.taskCommitRejectedMutex.RLock()
+	defer fake.taskCommitRejectedMutex.RUnlock()
+	return len(fake.taskCommitRejectedArgsForCall)
+}
+
+func (fake *FakeAuctionListener) TaskCommitRejectedArgsForCall(i int) auctiontypes.TaskAuction {
+	fake.taskCommitRejectedMutex.RLock()
+	defer fake.taskCommitRejectedMutex.RUnlock()
+	return fake.taskCommitRejectedArgsForCall[i].arg1
+}
+
+var _ auctiontypes.AuctionListener = new(FakeAuctionListener)
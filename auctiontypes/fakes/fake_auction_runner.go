@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"context"
 	"os"
 	"sync"
 
@@ -19,16 +20,48 @@ type FakeAuctionRunner struct {
 	runReturns struct {
 		result1 error
 	}
-	ScheduleLRPsForAuctionsStub        func([]auctioneer.LRPStartRequest)
+	RunContextStub        func(ctx context.Context, ready chan<- struct{}) error
+	runContextMutex       sync.RWMutex
+	runContextArgsForCall []struct {
+		ctx   context.Context
+		ready chan<- struct{}
+	}
+	runContextReturns struct {
+		result1 error
+	}
+	ScheduleLRPsForAuctionsStub        func([]auctioneer.LRPStartRequest) error
 	scheduleLRPsForAuctionsMutex       sync.RWMutex
 	scheduleLRPsForAuctionsArgsForCall []struct {
 		arg1 []auctioneer.LRPStartRequest
 	}
-	ScheduleTasksForAuctionsStub        func([]auctioneer.TaskStartRequest)
+	scheduleLRPsForAuctionsReturns struct {
+		result1 error
+	}
+	ScheduleTasksForAuctionsStub        func([]auctioneer.TaskStartRequest) error
 	scheduleTasksForAuctionsMutex       sync.RWMutex
 	scheduleTasksForAuctionsArgsForCall []struct {
 		arg1 []auctioneer.TaskStartRequest
 	}
+	scheduleTasksForAuctionsReturns struct {
+		result1 error
+	}
+	CancelLRPAuctionsStub        func(processGuid string, indices []int)
+	cancelLRPAuctionsMutex       sync.RWMutex
+	cancelLRPAuctionsArgsForCall []struct {
+		processGuid string
+		indices     []int
+	}
+	CancelTaskAuctionsStub        func(taskGuids []string)
+	cancelTaskAuctionsMutex       sync.RWMutex
+	cancelTaskAuctionsArgsForCall []struct {
+		taskGuids []string
+	}
+	PendingAuctionsStub        func() auctiontypes.PendingAuctions
+	pendingAuctionsMutex       sync.RWMutex
+	pendingAuctionsArgsForCall []struct{}
+	pendingAuctionsReturns     struct {
+		result1 auctiontypes.PendingAuctions
+	}
 }
 
 func (fake *FakeAuctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
@@ -64,14 +97,49 @@ func (fake *FakeAuctionRunner) RunReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeAuctionRunner) ScheduleLRPsForAuctions(arg1 []auctioneer.LRPStartRequest) {
+func (fake *FakeAuctionRunner) RunContext(ctx context.Context, ready chan<- struct{}) error {
+	fake.runContextMutex.Lock()
+	fake.runContextArgsForCall = append(fake.runContextArgsForCall, struct {
+		ctx   context.Context
+		ready chan<- struct{}
+	}{ctx, ready})
+	fake.runContextMutex.Unlock()
+	if fake.RunContextStub != nil {
+		return fake.RunContextStub(ctx, ready)
+	} else {
+		return fake.runContextReturns.result1
+	}
+}
+
+func (fake *FakeAuctionRunner) RunContextCallCount() int {
+	fake.runContextMutex.RLock()
+	defer fake.runContextMutex.RUnlock()
+	return len(fake.runContextArgsForCall)
+}
+
+func (fake *FakeAuctionRunner) RunContextArgsForCall(i int) (context.Context, chan<- struct{}) {
+	fake.runContextMutex.RLock()
+	defer fake.runContextMutex.RUnlock()
+	return fake.runContextArgsForCall[i].ctx, fake.runContextArgsForCall[i].ready
+}
+
+func (fake *FakeAuctionRunner) RunContextReturns(result1 error) {
+	fake.RunContextStub = nil
+	fake.runContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuctionRunner) ScheduleLRPsForAuctions(arg1 []auctioneer.LRPStartRequest) error {
 	fake.scheduleLRPsForAuctionsMutex.Lock()
 	fake.scheduleLRPsForAuctionsArgsForCall = append(fake.scheduleLRPsForAuctionsArgsForCall, struct {
 		arg1 []auctioneer.LRPStartRequest
 	}{arg1})
 	fake.scheduleLRPsForAuctionsMutex.Unlock()
 	if fake.ScheduleLRPsForAuctionsStub != nil {
-		fake.ScheduleLRPsForAuctionsStub(arg1)
+		return fake.ScheduleLRPsForAuctionsStub(arg1)
+	} else {
+		return fake.scheduleLRPsForAuctionsReturns.result1
 	}
 }
 
@@ -87,14 +155,23 @@ func (fake *FakeAuctionRunner) ScheduleLRPsForAuctionsArgsForCall(i int) []aucti
 	return fake.scheduleLRPsForAuctionsArgsForCall[i].arg1
 }
 
-func (fake *FakeAuctionRunner) ScheduleTasksForAuctions(arg1 []auctioneer.TaskStartRequest) {
+func (fake *FakeAuctionRunner) ScheduleLRPsForAuctionsReturns(result1 error) {
+	fake.ScheduleLRPsForAuctionsStub = nil
+	fake.scheduleLRPsForAuctionsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuctionRunner) ScheduleTasksForAuctions(arg1 []auctioneer.TaskStartRequest) error {
 	fake.scheduleTasksForAuctionsMutex.Lock()
 	fake.scheduleTasksForAuctionsArgsForCall = append(fake.scheduleTasksForAuctionsArgsForCall, struct {
 		arg1 []auctioneer.TaskStartRequest
 	}{arg1})
 	fake.scheduleTasksForAuctionsMutex.Unlock()
 	if fake.ScheduleTasksForAuctionsStub != nil {
-		fake.ScheduleTasksForAuctionsStub(arg1)
+		return fake.ScheduleTasksForAuctionsStub(arg1)
+	} else {
+		return fake.scheduleTasksForAuctionsReturns.result1
 	}
 }
 
@@ -110,4 +187,82 @@ func (fake *FakeAuctionRunner) ScheduleTasksForAuctionsArgsForCall(i int) []auct
 	return fake.scheduleTasksForAuctionsArgsForCall[i].arg1
 }
 
+func (fake *FakeAuctionRunner) ScheduleTasksForAuctionsReturns(result1 error) {
+	fake.ScheduleTasksForAuctionsStub = nil
+	fake.scheduleTasksForAuctionsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuctionRunner) CancelLRPAuctions(processGuid string, indices []int) {
+	fake.cancelLRPAuctionsMutex.Lock()
+	fake.cancelLRPAuctionsArgsForCall = append(fake.cancelLRPAuctionsArgsForCall, struct {
+		processGuid string
+		indices     []int
+	}{processGuid, indices})
+	fake.cancelLRPAuctionsMutex.Unlock()
+	if fake.CancelLRPAuctionsStub != nil {
+		fake.CancelLRPAuctionsStub(processGuid, indices)
+	}
+}
+
+func (fake *FakeAuctionRunner) CancelLRPAuctionsCallCount() int {
+	fake.cancelLRPAuctionsMutex.RLock()
+	defer fake.cancelLRPAuctionsMutex.RUnlock()
+	return len(fake.cancelLRPAuctionsArgsForCall)
+}
+
+func (fake *FakeAuctionRunner) CancelLRPAuctionsArgsForCall(i int) (string, []int) {
+	fake.cancelLRPAuctionsMutex.RLock()
+	defer fake.cancelLRPAuctionsMutex.RUnlock()
+	return fake.cancelLRPAuctionsArgsForCall[i].processGuid, fake.cancelLRPAuctionsArgsForCall[i].indices
+}
+
+func (fake *FakeAuctionRunner) CancelTaskAuctions(taskGuids []string) {
+	fake.cancelTaskAuctionsMutex.Lock()
+	fake.cancelTaskAuctionsArgsForCall = append(fake.cancelTaskAuctionsArgsForCall, struct {
+		taskGuids []string
+	}{taskGuids})
+	fake.cancelTaskAuctionsMutex.Unlock()
+	if fake.CancelTaskAuctionsStub != nil {
+		fake.CancelTaskAuctionsStub(taskGuids)
+	}
+}
+
+func (fake *FakeAuctionRunner) CancelTaskAuctionsCallCount() int {
+	fake.cancelTaskAuctionsMutex.RLock()
+	defer fake.cancelTaskAuctionsMutex.RUnlock()
+	return len(fake.cancelTaskAuctionsArgsForCall)
+}
+
+func (fake *FakeAuctionRunner) CancelTaskAuctionsArgsForCall(i int) []string {
+	fake.cancelTaskAuctionsMutex.RLock()
+	defer fake.cancelTaskAuctionsMutex.RUnlock()
+	return fake.cancelTaskAuctionsArgsForCall[i].taskGuids
+}
+
+func (fake *FakeAuctionRunner) PendingAuctions() auctiontypes.PendingAuctions {
+	fake.pendingAuctionsMutex.Lock()
+	fake.pendingAuctionsArgsForCall = append(fake.pendingAuctionsArgsForCall, struct{}{})
+	fake.pendingAuctionsMutex.Unlock()
+	if fake.PendingAuctionsStub != nil {
+		return fake.PendingAuctionsStub()
+	} else {
+		return fake.pendingAuctionsReturns.result1
+	}
+}
+
+func (fake *FakeAuctionRunner) PendingAuctionsCallCount() int {
+	fake.pendingAuctionsMutex.RLock()
+	defer fake.pendingAuctionsMutex.RUnlock()
+	return len(fake.pendingAuctionsArgsForCall)
+}
+
+func (fake *FakeAuctionRunner) PendingAuctionsReturns(result1 auctiontypes.PendingAuctions) {
+	fake.PendingAuctionsStub = nil
+	fake.pendingAuctionsReturns = struct {
+		result1 auctiontypes.PendingAuctions
+	}{result1}
+}
+
 var _ auctiontypes.AuctionRunner = new(FakeAuctionRunner)
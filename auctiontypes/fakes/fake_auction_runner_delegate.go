@@ -0,0 +1,74 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeAuctionRunnerDelegate struct {
+	FetchCellRepsStub        func() (map[string]rep.Client, error)
+	fetchCellRepsMutex       sync.RWMutex
+	fetchCellRepsArgsForCall []struct{}
+	fetchCellRepsReturns     struct {
+		result1 map[string]rep.Client
+		result2 error
+	}
+	AuctionCompletedStub        func(auctiontypes.AuctionResults)
+	auctionCompletedMutex       sync.RWMutex
+	auctionCompletedArgsForCall []struct {
+		arg1 auctiontypes.AuctionResults
+	}
+}
+
+func (fake *FakeAuctionRunnerDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	fake.fetchCellRepsMutex.Lock()
+	fake.fetchCellRepsArgsForCall = append(fake.fetchCellRepsArgsForCall, struct{}{})
+	fake.fetchCellRepsMutex.Unlock()
+	if fake.FetchCellRepsStub != nil {
+		return fake.FetchCellRepsStub()
+	} else {
+		return fake.fetchCellRepsReturns.result1, fake.fetchCellRepsReturns.result2
+	}
+}
+
+func (fake *FakeAuctionRunnerDelegate) FetchCellRepsCallCount() int {
+	fake.fetchCellRepsMutex.RLock()
+	defer fake.fetchCellRepsMutex.RUnlock()
+	return len(fake.fetchCellRepsArgsForCall)
+}
+
+func (fake *FakeAuctionRunnerDelegate) FetchCellRepsReturns(result1 map[string]rep.Client, result2 error) {
+	fake.FetchCellRepsStub = nil
+	fake.fetchCellRepsReturns = struct {
+		result1 map[string]rep.Client
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuctionRunnerDelegate) AuctionCompleted(arg1 auctiontypes.AuctionResults) {
+	fake.auctionCompletedMutex.Lock()
+	fake.auctionCompletedArgsForCall = append(fake.auctionCompletedArgsForCall, struct {
+		arg1 auctiontypes.AuctionResults
+	}{arg1})
+	fake.auctionCompletedMutex.Unlock()
+	if fake.AuctionCompletedStub != nil {
+		fake.AuctionCompletedStub(arg1)
+	}
+}
+
+func (fake *FakeAuctionRunnerDelegate) AuctionCompletedCallCount() int {
+	fake.auctionCompletedMutex.RLock()
+	defer fake.auctionCompletedMutex.RUnlock()
+	return len(fake.auctionCompletedArgsForCall)
+}
+
+func (fake *FakeAuctionRunnerDelegate) AuctionCompletedArgsForCall(i int) auctiontypes.AuctionResults {
+	fake.auctionCompletedMutex.RLock()
+	defer fake.auctionCompletedMutex.RUnlock()
+	return fake.auctionCompletedArgsForCall[i].arg1
+}
+
+var _ auctiontypes.AuctionRunnerDelegate = new(FakeAuctionRunnerDelegate)
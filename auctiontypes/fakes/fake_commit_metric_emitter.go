@@ -0,0 +1,154 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+type FakeCommitMetricEmitter struct {
+	ScheduleCompletedStub        func(time.Duration) error
+	scheduleCompletedMutex       sync.RWMutex
+	scheduleCompletedArgsForCall []struct {
+		arg1 time.Duration
+	}
+	scheduleCompletedReturns struct {
+		result1 error
+	}
+	CommitCompletedStub        func(time.Duration) error
+	commitCompletedMutex       sync.RWMutex
+	commitCompletedArgsForCall []struct {
+		arg1 time.Duration
+	}
+	commitCompletedReturns struct {
+		result1 error
+	}
+	CellCommitCompletedStub        func(string, time.Duration)
+	cellCommitCompletedMutex       sync.RWMutex
+	cellCommitCompletedArgsForCall []struct {
+		arg1 string
+		arg2 time.Duration
+	}
+	UnknownCommitOutcomesStub        func(int, int)
+	unknownCommitOutcomesMutex       sync.RWMutex
+	unknownCommitOutcomesArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+}
+
+func (fake *FakeCommitMetricEmitter) ScheduleCompleted(arg1 time.Duration) error {
+	fake.scheduleCompletedMutex.Lock()
+	fake.scheduleCompletedArgsForCall = append(fake.scheduleCompletedArgsForCall, struct {
+		arg1 time.Duration
+	}{arg1})
+	fake.scheduleCompletedMutex.Unlock()
+	if fake.ScheduleCompletedStub != nil {
+		return fake.ScheduleCompletedStub(arg1)
+	} else {
+		return fake.scheduleCompletedReturns.result1
+	}
+}
+
+func (fake *FakeCommitMetricEmitter) ScheduleCompletedCallCount() int {
+	fake.scheduleCompletedMutex.RLock()
+	defer fake.scheduleCompletedMutex.RUnlock()
+	return len(fake.scheduleCompletedArgsForCall)
+}
+
+func (fake *FakeCommitMetricEmitter) ScheduleCompletedArgsForCall(i int) time.Duration {
+	fake.scheduleCompletedMutex.RLock()
+	defer fake.scheduleCompletedMutex.RUnlock()
+	return fake.scheduleCompletedArgsForCall[i].arg1
+}
+
+func (fake *FakeCommitMetricEmitter) ScheduleCompletedReturns(result1 error) {
+	fake.ScheduleCompletedStub = nil
+	fake.scheduleCompletedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCommitMetricEmitter) CommitCompleted(arg1 time.Duration) error {
+	fake.commitCompletedMutex.Lock()
+	fake.commitCompletedArgsForCall = append(fake.commitCompletedArgsForCall, struct {
+		arg1 time.Duration
+	}{arg1})
+	fake.commitCompletedMutex.Unlock()
+	if fake.CommitCompletedStub != nil {
+		return fake.CommitCompletedStub(arg1)
+	} else {
+		return fake.commitCompletedReturns.result1
+	}
+}
+
+func (fake *FakeCommitMetricEmitter) CommitCompletedCallCount() int {
+	fake.commitCompletedMutex.RLock()
+	defer fake.commitCompletedMutex.RUnlock()
+	return len(fake.commitCompletedArgsForCall)
+}
+
+func (fake *FakeCommitMetricEmitter) CommitCompletedArgsForCall(i int) time.Duration {
+	fake.commitCompletedMutex.RLock()
+	defer fake.commitCompletedMutex.RUnlock()
+	return fake.commitCompletedArgsForCall[i].arg1
+}
+
+func (fake *FakeCommitMetricEmitter) CommitCompletedReturns(result1 error) {
+	fake.CommitCompletedStub = nil
+	fake.commitCompletedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCommitMetricEmitter) CellCommitCompleted(arg1 string, arg2 time.Duration) {
+	fake.cellCommitCompletedMutex.Lock()
+	fake.cellCommitCompletedArgsForCall = append(fake.cellCommitCompletedArgsForCall, struct {
+		arg1 string
+		arg2 time.Duration
+	}{arg1, arg2})
+	fake.cellCommitCompletedMutex.Unlock()
+	if fake.CellCommitCompletedStub != nil {
+		fake.CellCommitCompletedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeCommitMetricEmitter) CellCommitCompletedCallCount() int {
+	fake.cellCommitCompletedMutex.RLock()
+	defer fake.cellCommitCompletedMutex.RUnlock()
+	return len(fake.cellCommitCompletedArgsForCall)
+}
+
+func (fake *FakeCommitMetricEmitter) CellCommitCompletedArgsForCall(i int) (string, time.Duration) {
+	fake.cellCommitCompletedMutex.RLock()
+	defer fake.cellCommitCompletedMutex.RUnlock()
+	return fake.cellCommitCompletedArgsForCall[i].arg1, fake.cellCommitCompletedArgsForCall[i].arg2
+}
+
+func (fake *FakeCommitMetricEmitter) UnknownCommitOutcomes(arg1 int, arg2 int) {
+	fake.unknownCommitOutcomesMutex.Lock()
+	fake.unknownCommitOutcomesArgsForCall = append(fake.unknownCommitOutcomesArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.unknownCommitOutcomesMutex.Unlock()
+	if fake.UnknownCommitOutcomesStub != nil {
+		fake.UnknownCommitOutcomesStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeCommitMetricEmitter) UnknownCommitOutcomesCallCount() int {
+	fake.unknownCommitOutcomesMutex.RLock()
+	defer fake.unknownCommitOutcomesMutex.RUnlock()
+	return len(fake.unknownCommitOutcomesArgsForCall)
+}
+
+func (fake *FakeCommitMetricEmitter) UnknownCommitOutcomesArgsForCall(i int) (int, int) {
+	fake.unknownCommitOutcomesMutex.RLock()
+	defer fake.unknownCommitOutcomesMutex.RUnlock()
+	return fake.unknownCommitOutcomesArgsForCall[i].arg1, fake.unknownCommitOutcomesArgsForCall[i].arg2
+}
+
+var _ auctiontypes.CommitMetricEmitter = new(FakeCommitMetricEmitter)
@@ -0,0 +1,102 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+type FakeQueueMetricEmitter struct {
+	QueueDepthStub        func(int, int)
+	queueDepthMutex       sync.RWMutex
+	queueDepthArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	OldestQueuedAuctionAgeStub        func(time.Duration)
+	oldestQueuedAuctionAgeMutex       sync.RWMutex
+	oldestQueuedAuctionAgeArgsForCall []struct {
+		arg1 time.Duration
+	}
+	AuctionsDeduplicatedStub        func(int, int)
+	auctionsDeduplicatedMutex       sync.RWMutex
+	auctionsDeduplicatedArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+}
+
+func (fake *FakeQueueMetricEmitter) QueueDepth(arg1 int, arg2 int) {
+	fake.queueDepthMutex.Lock()
+	fake.queueDepthArgsForCall = append(fake.queueDepthArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.queueDepthMutex.Unlock()
+	if fake.QueueDepthStub != nil {
+		fake.QueueDepthStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeQueueMetricEmitter) QueueDepthCallCount() int {
+	fake.queueDepthMutex.RLock()
+	defer fake.queueDepthMutex.RUnlock()
+	return len(fake.queueDepthArgsForCall)
+}
+
+func (fake *FakeQueueMetricEmitter) QueueDepthArgsForCall(i int) (int, int) {
+	fake.queueDepthMutex.RLock()
+	defer fake.queueDepthMutex.RUnlock()
+	return fake.queueDepthArgsForCall[i].arg1, fake.queueDepthArgsForCall[i].arg2
+}
+
+func (fake *FakeQueueMetricEmitter) OldestQueuedAuctionAge(arg1 time.Duration) {
+	fake.oldestQueuedAuctionAgeMutex.Lock()
+	fake.oldestQueuedAuctionAgeArgsForCall = append(fake.oldestQueuedAuctionAgeArgsForCall, struct {
+		arg1 time.Duration
+	}{arg1})
+	fake.oldestQueuedAuctionAgeMutex.Unlock()
+	if fake.OldestQueuedAuctionAgeStub != nil {
+		fake.OldestQueuedAuctionAgeStub(arg1)
+	}
+}
+
+func (fake *FakeQueueMetricEmitter) OldestQueuedAuctionAgeCallCount() int {
+	fake.oldestQueuedAuctionAgeMutex.RLock()
+	defer fake.oldestQueuedAuctionAgeMutex.RUnlock()
+	return len(fake.oldestQueuedAuctionAgeArgsForCall)
+}
+
+func (fake *FakeQueueMetricEmitter) OldestQueuedAuctionAgeArgsForCall(i int) time.Duration {
+	fake.oldestQueuedAuctionAgeMutex.RLock()
+	defer fake.oldestQueuedAuctionAgeMutex.RUnlock()
+	return fake.oldestQueuedAuctionAgeArgsForCall[i].arg1
+}
+
+func (fake *FakeQueueMetricEmitter) AuctionsDeduplicated(arg1 int, arg2 int) {
+	fake.auctionsDeduplicatedMutex.Lock()
+	fake.auctionsDeduplicatedArgsForCall = append(fake.auctionsDeduplicatedArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.auctionsDeduplicatedMutex.Unlock()
+	if fake.AuctionsDeduplicatedStub != nil {
+		fake.AuctionsDeduplicatedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeQueueMetricEmitter) AuctionsDeduplicatedCallCount() int {
+	fake.auctionsDeduplicatedMutex.RLock()
+	defer fake.auctionsDeduplicatedMutex.RUnlock()
+	return len(fake.auctionsDeduplicatedArgsForCall)
+}
+
+func (fake *FakeQueueMetricEmitter) AuctionsDeduplicatedArgsForCall(i int) (int, int) {
+	fake.auctionsDeduplicatedMutex.RLock()
+	defer fake.auctionsDeduplicatedMutex.RUnlock()
+	return fake.auctionsDeduplicatedArgsForCall[i].arg1, fake.auctionsDeduplicatedArgsForCall[i].arg2
+}
+
+var _ auctiontypes.QueueMetricEmitter = new(FakeQueueMetricEmitter)
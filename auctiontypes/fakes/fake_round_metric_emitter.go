@@ -0,0 +1,190 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+)
+
+type FakeRoundMetricEmitter struct {
+	CellStatesFetchedStub        func(int)
+	cellStatesFetchedMutex       sync.RWMutex
+	cellStatesFetchedArgsForCall []struct {
+		arg1 int
+	}
+	BatchSizeStub        func(int, int)
+	batchSizeMutex       sync.RWMutex
+	batchSizeArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	QueueWaitTimesStub        func([]time.Duration)
+	queueWaitTimesMutex       sync.RWMutex
+	queueWaitTimesArgsForCall []struct {
+		arg1 []time.Duration
+	}
+	PlacementFailuresStub        func(string, int, int)
+	placementFailuresMutex       sync.RWMutex
+	placementFailuresArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 int
+	}
+	InflightLimitRejectionsStub        func(int, int)
+	inflightLimitRejectionsMutex       sync.RWMutex
+	inflightLimitRejectionsArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	ZoneSkewStub        func(int)
+	zoneSkewMutex       sync.RWMutex
+	zoneSkewArgsForCall []struct {
+		arg1 int
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) CellStatesFetched(arg1 int) {
+	fake.cellStatesFetchedMutex.Lock()
+	fake.cellStatesFetchedArgsForCall = append(fake.cellStatesFetchedArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.cellStatesFetchedMutex.Unlock()
+	if fake.CellStatesFetchedStub != nil {
+		fake.CellStatesFetchedStub(arg1)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) CellStatesFetchedCallCount() int {
+	fake.cellStatesFetchedMutex.RLock()
+	defer fake.cellStatesFetchedMutex.RUnlock()
+	return len(fake.cellStatesFetchedArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) CellStatesFetchedArgsForCall(i int) int {
+	fake.cellStatesFetchedMutex.RLock()
+	defer fake.cellStatesFetchedMutex.RUnlock()
+	return fake.cellStatesFetchedArgsForCall[i].arg1
+}
+
+func (fake *FakeRoundMetricEmitter) BatchSize(arg1 int, arg2 int) {
+	fake.batchSizeMutex.Lock()
+	fake.batchSizeArgsForCall = append(fake.batchSizeArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.batchSizeMutex.Unlock()
+	if fake.BatchSizeStub != nil {
+		fake.BatchSizeStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) BatchSizeCallCount() int {
+	fake.batchSizeMutex.RLock()
+	defer fake.batchSizeMutex.RUnlock()
+	return len(fake.batchSizeArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) BatchSizeArgsForCall(i int) (int, int) {
+	fake.batchSizeMutex.RLock()
+	defer fake.batchSizeMutex.RUnlock()
+	return fake.batchSizeArgsForCall[i].arg1, fake.batchSizeArgsForCall[i].arg2
+}
+
+func (fake *FakeRoundMetricEmitter) QueueWaitTimes(arg1 []time.Duration) {
+	fake.queueWaitTimesMutex.Lock()
+	fake.queueWaitTimesArgsForCall = append(fake.queueWaitTimesArgsForCall, struct {
+		arg1 []time.Duration
+	}{arg1})
+	fake.queueWaitTimesMutex.Unlock()
+	if fake.QueueWaitTimesStub != nil {
+		fake.QueueWaitTimesStub(arg1)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) QueueWaitTimesCallCount() int {
+	fake.queueWaitTimesMutex.RLock()
+	defer fake.queueWaitTimesMutex.RUnlock()
+	return len(fake.queueWaitTimesArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) QueueWaitTimesArgsForCall(i int) []time.Duration {
+	fake.queueWaitTimesMutex.RLock()
+	defer fake.queueWaitTimesMutex.RUnlock()
+	return fake.queueWaitTimesArgsForCall[i].arg1
+}
+
+func (fake *FakeRoundMetricEmitter) PlacementFailures(arg1 string, arg2 int, arg3 int) {
+	fake.placementFailuresMutex.Lock()
+	fake.placementFailuresArgsForCall = append(fake.placementFailuresArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 int
+	}{arg1, arg2, arg3})
+	fake.placementFailuresMutex.Unlock()
+	if fake.PlacementFailuresStub != nil {
+		fake.PlacementFailuresStub(arg1, arg2, arg3)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) PlacementFailuresCallCount() int {
+	fake.placementFailuresMutex.RLock()
+	defer fake.placementFailuresMutex.RUnlock()
+	return len(fake.placementFailuresArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) PlacementFailuresArgsForCall(i int) (string, int, int) {
+	fake.placementFailuresMutex.RLock()
+	defer fake.placementFailuresMutex.RUnlock()
+	return fake.placementFailuresArgsForCall[i].arg1, fake.placementFailuresArgsForCall[i].arg2, fake.placementFailuresArgsForCall[i].arg3
+}
+
+func (fake *FakeRoundMetricEmitter) InflightLimitRejections(arg1 int, arg2 int) {
+	fake.inflightLimitRejectionsMutex.Lock()
+	fake.inflightLimitRejectionsArgsForCall = append(fake.inflightLimitRejectionsArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	fake.inflightLimitRejectionsMutex.Unlock()
+	if fake.InflightLimitRejectionsStub != nil {
+		fake.InflightLimitRejectionsStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) InflightLimitRejectionsCallCount() int {
+	fake.inflightLimitRejectionsMutex.RLock()
+	defer fake.inflightLimitRejectionsMutex.RUnlock()
+	return len(fake.inflightLimitRejectionsArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) InflightLimitRejectionsArgsForCall(i int) (int, int) {
+	fake.inflightLimitRejectionsMutex.RLock()
+	defer fake.inflightLimitRejectionsMutex.RUnlock()
+	return fake.inflightLimitRejectionsArgsForCall[i].arg1, fake.inflightLimitRejectionsArgsForCall[i].arg2
+}
+
+func (fake *FakeRoundMetricEmitter) ZoneSkew(arg1 int) {
+	fake.zoneSkewMutex.Lock()
+	fake.zoneSkewArgsForCall = append(fake.zoneSkewArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.zoneSkewMutex.Unlock()
+	if fake.ZoneSkewStub != nil {
+		fake.ZoneSkewStub(arg1)
+	}
+}
+
+func (fake *FakeRoundMetricEmitter) ZoneSkewCallCount() int {
+	fake.zoneSkewMutex.RLock()
+	defer fake.zoneSkewMutex.RUnlock()
+	return len(fake.zoneSkewArgsForCall)
+}
+
+func (fake *FakeRoundMetricEmitter) ZoneSkewArgsForCall(i int) int {
+	fake.zoneSkewMutex.RLock()
+	defer fake.zoneSkewMutex.RUnlock()
+	return fake.zoneSkewArgsForCall[i].arg1
+}
+
+var _ auctiontypes.RoundMetricEmitter = new(FakeRoundMetricEmitter)
@@ -0,0 +1,38 @@
+package auctiontypes
+
+import "context"
+
+// Tracer starts spans for the phases of an auction round. Implementations
+// keep track of the current span on the returned context, so that spans
+// started from it become its children.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func NewAttribute(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type tracerKey struct{}
+
+// ContextWithTracer returns a copy of ctx that traces through tracer.
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// TracerFromContext returns the tracer set by ContextWithTracer, or nil.
+func TracerFromContext(ctx context.Context) Tracer {
+	tracer, _ := ctx.Value(tracerKey{}).(Tracer)
+	return tracer
+}
@@ -1,6 +1,7 @@
 package auctiontypes
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"time"
@@ -43,19 +44,66 @@ func (e PlacementTagMismatchError) Error() string {
 var ErrorNothingToStop = errors.New("nothing to stop")
 var ErrorCellCommunication = errors.New("unable to communicate to compatible cells")
 var ErrorExceededInflightCreation = errors.New("waiting to start instance: reached in-flight start limit")
+var ErrorRejectedByEveryCell = errors.New("rejected by every compatible cell")
+var ErrorCommitOutcomeUnknown = errors.New("unable to confirm placement with cell")
+var ErrorCommitTimedOut = errors.New("timed out waiting to commit placement to cell")
+var ErrorAuctionCancelled = errors.New("auction cancelled before placement")
+var ErrorAuctionQueueFull = errors.New("auction queue is full")
+var ErrorAuctionAborted = errors.New("auction aborted before placement")
+var ErrorAuctioneerShuttingDown = errors.New("auctioneer shutting down")
 
 //go:generate counterfeiter -o fakes/fake_auction_runner.go . AuctionRunner
 type AuctionRunner interface {
 	ifrit.Runner
-	ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest)
-	ScheduleTasksForAuctions([]auctioneer.TaskStartRequest)
+	RunContext(ctx context.Context, ready chan<- struct{}) error
+	ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest) error
+	ScheduleTasksForAuctions([]auctioneer.TaskStartRequest) error
+	CancelLRPAuctions(processGuid string, indices []int)
+	CancelTaskAuctions(taskGuids []string)
+	PendingAuctions() PendingAuctions
 }
 
+//go:generate counterfeiter -o fakes/fake_auction_runner_delegate.go . AuctionRunnerDelegate
 type AuctionRunnerDelegate interface {
 	FetchCellReps() (map[string]rep.Client, error)
 	AuctionCompleted(AuctionResults)
 }
 
+// ContextAuctionRunnerDelegate is an AuctionRunnerDelegate whose cell lookup
+// can be cancelled. The runner prefers FetchCellRepsContext when available.
+type ContextAuctionRunnerDelegate interface {
+	AuctionRunnerDelegate
+	FetchCellRepsContext(ctx context.Context) (map[string]rep.Client, error)
+}
+
+//go:generate counterfeiter -o fakes/fake_auction_listener.go . AuctionListener
+
+// AuctionListener is told about each auction's outcome as soon as it is
+// known, rather than once the whole round has committed. Every auction that
+// is not cancelled ends with exactly one of Placed, Failed or Unknown;
+// cancelled auctions are only reported through AuctionCompleted, in
+// CancelledLRPs and CancelledTasks. CommitRejected is not an outcome: the
+// rejected auction is re-auctioned in the same round. Placed and
+// commit-rejected auctions are reported as each cell answers its commit.
+type AuctionListener interface {
+	LRPPlaced(LRPAuction)
+	TaskPlaced(TaskAuction)
+	LRPFailed(LRPAuction)
+	TaskFailed(TaskAuction)
+	LRPUnknown(LRPAuction)
+	TaskUnknown(TaskAuction)
+	LRPCommitRejected(LRPAuction)
+	TaskCommitRejected(TaskAuction)
+}
+
+// StreamingAuctionRunnerDelegate is an AuctionRunnerDelegate that also
+// listens to individual outcomes. AuctionCompleted still receives the
+// aggregate results of every round.
+type StreamingAuctionRunnerDelegate interface {
+	AuctionRunnerDelegate
+	AuctionListener
+}
+
 //go:generate counterfeiter -o fakes/fake_metric_emitter.go . AuctionMetricEmitterDelegate
 type AuctionMetricEmitterDelegate interface {
 	FetchStatesCompleted(time.Duration) error
@@ -63,6 +111,71 @@ type AuctionMetricEmitterDelegate interface {
 	AuctionCompleted(AuctionResults)
 }
 
+// The metric emitters below are optional. The auction runner emits their
+// metrics only when its AuctionMetricEmitterDelegate also implements them.
+
+//go:generate counterfeiter -o fakes/fake_commit_metric_emitter.go . CommitMetricEmitter
+type CommitMetricEmitter interface {
+	// ScheduleCompleted covers a whole Schedule call, placement and commit.
+	ScheduleCompleted(time.Duration) error
+	CommitCompleted(time.Duration) error
+	CellCommitCompleted(cellGuid string, duration time.Duration)
+	UnknownCommitOutcomes(lrps int, tasks int)
+}
+
+//go:generate counterfeiter -o fakes/fake_round_metric_emitter.go . RoundMetricEmitter
+type RoundMetricEmitter interface {
+	CellStatesFetched(cells int)
+	BatchSize(lrps int, tasks int)
+	// QueueWaitTimes holds how long each auction of a round spent queued.
+	QueueWaitTimes([]time.Duration)
+	// PlacementFailures is called once per round for every reason returned by
+	// PlacementFailureReason.
+	PlacementFailures(reason string, lrps int, tasks int)
+	InflightLimitRejections(lrps int, tasks int)
+	// ZoneSkew is the largest difference in instance counts between two zones
+	// among the processes placed in a round.
+	ZoneSkew(instances int)
+}
+
+//go:generate counterfeiter -o fakes/fake_queue_metric_emitter.go . QueueMetricEmitter
+type QueueMetricEmitter interface {
+	QueueDepth(lrps int, tasks int)
+	OldestQueuedAuctionAge(time.Duration)
+	AuctionsDeduplicated(lrps int, tasks int)
+}
+
+// PlacementFailureReason maps a PlacementError onto a small, fixed set of
+// reasons suitable for labelling metrics.
+func PlacementFailureReason(placementError string) string {
+	switch {
+	case placementError == "", placementError == ErrorRejectedByEveryCell.Error():
+		return "rejected-by-cell"
+	case placementError == ErrorCellMismatch.Error():
+		return "cell-mismatch"
+	case placementError == ErrorVolumeDriverMismatch.Error():
+		return "volume-driver-mismatch"
+	case strings.HasPrefix(placementError, "found no compatible cell with"):
+		return "placement-tag-mismatch"
+	case strings.HasPrefix(placementError, "insufficient resources"):
+		return "insufficient-resources"
+	case placementError == ErrorCellCommunication.Error():
+		return "cell-communication"
+	case placementError == ErrorExceededInflightCreation.Error():
+		return "inflight-limit"
+	case placementError == ErrorCommitTimedOut.Error():
+		return "commit-timed-out"
+	case placementError == ErrorAuctionQueueFull.Error():
+		return "queue-full"
+	case placementError == ErrorAuctionAborted.Error():
+		return "aborted"
+	case placementError == ErrorAuctioneerShuttingDown.Error():
+		return "shutting-down"
+	default:
+		return "other"
+	}
+}
+
 type AuctionRequest struct {
 	LRPs  []LRPAuction
 	Tasks []TaskAuction
@@ -73,6 +186,22 @@ type AuctionResults struct {
 	SuccessfulTasks []TaskAuction
 	FailedLRPs      []LRPAuction
 	FailedTasks     []TaskAuction
+
+	// Unknown work was sent to its Winner, but the commit failed in transit so
+	// the cell may or may not have started it.
+	UnknownLRPs  []LRPAuction
+	UnknownTasks []TaskAuction
+
+	// Cancelled work was withdrawn from the queue before it was auctioned.
+	CancelledLRPs  []LRPAuction
+	CancelledTasks []TaskAuction
+}
+
+// PendingAuctions is a point-in-time copy of the auctions waiting for the
+// next round.
+type PendingAuctions struct {
+	LRPs  []LRPAuction
+	Tasks []TaskAuction
 }
 
 // LRPStart and Task Auctions
@@ -72,4 +72,19 @@ var _ = Describe("Types", func() {
 			Expect(err.Error()).To(Equal("found no compatible cell for required rootfs"))
 		})
 	})
+
+	Describe("PlacementFailureReason", func() {
+		It("groups placement errors into a fixed set of reasons", func() {
+			Expect(auctiontypes.PlacementFailureReason("")).To(Equal("rejected-by-cell"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorRejectedByEveryCell.Error())).To(Equal("rejected-by-cell"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorCellMismatch.Error())).To(Equal("cell-mismatch"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorVolumeDriverMismatch.Error())).To(Equal("volume-driver-mismatch"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.NewPlacementTagMismatchError([]string{"a", "b"}).Error())).To(Equal("placement-tag-mismatch"))
+			Expect(auctiontypes.PlacementFailureReason("insufficient resources: memory")).To(Equal("insufficient-resources"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorExceededInflightCreation.Error())).To(Equal("inflight-limit"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorAuctioneerShuttingDown.Error())).To(Equal("shutting-down"))
+			Expect(auctiontypes.PlacementFailureReason(auctiontypes.ErrorCommitTimedOut.Error())).To(Equal("commit-timed-out"))
+			Expect(auctiontypes.PlacementFailureReason("something else")).To(Equal("other"))
+		})
+	})
 })
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+var auction = flag.String("auction", "default", "auction type to replay with: default or bestfit")
+var startingContainerWeight = flag.Float64("startingContainerWeight", -1, "override the captured starting container weight")
+var verbose = flag.Bool("verbose", false, "log while scheduling")
+
+var auctionTypes = map[string]auctionrunner.AuctionTypeFunc{
+	"default": auctionfashion.DefaultAuction,
+	"bestfit": auctionfashion.BestFit,
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] capture.json...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	auctionTypeFunc, ok := auctionTypes[*auction]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown auction type %q\n", *auction)
+		os.Exit(2)
+	}
+	auctionType := auctionfashion.NewAuctionType(auctionTypeFunc)
+
+	logger := lager.NewLogger("replay")
+	if *verbose {
+		logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.INFO))
+	}
+
+	differences := 0
+	for _, path := range flag.Args() {
+		capture, err := auctionrunner.LoadRoundCapture(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load %s: %s\n", path, err)
+			os.Exit(1)
+		}
+		if *startingContainerWeight >= 0 {
+			capture.StartingContainerWeight = *startingContainerWeight
+		}
+
+		result, err := auctionrunner.ReplayRound(logger, clock.NewClock(), capture, auctionType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to replay %s: %s\n", path, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s: %d cells, %d lrps, %d tasks, %d differences\n",
+			path, len(capture.Cells), len(capture.Request.LRPs), len(capture.Request.Tasks), len(result.Diffs))
+		for _, diff := range result.Diffs {
+			fmt.Printf("  %s %s: recorded %q, replayed %q\n", diff.Type, diff.Identifier, diff.Recorded, diff.Replayed)
+		}
+		differences += len(result.Diffs)
+	}
+
+	if differences > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1 @@
+package main // import "code.cloudfoundry.org/auction/replay"
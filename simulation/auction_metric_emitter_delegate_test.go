@@ -1,21 +1,141 @@
 package simulation_test
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 )
 
-type auctionMetricEmitterDelegate struct{}
+// auctionMetricEmitterDelegate records the metrics emitted by the runner so
+// simulations can report on them.
+type auctionMetricEmitterDelegate struct {
+	lock *sync.Mutex
 
-func NewAuctionMetricEmitterDelegate() auctionMetricEmitterDelegate {
-	return auctionMetricEmitterDelegate{}
+	rounds                  int
+	scheduleDuration        time.Duration
+	cellCommits             int
+	cellCommitDuration      time.Duration
+	slowestCellCommit       time.Duration
+	failuresByReason        map[string]int
+	inflightLimitRejections int
+	batchedAuctions         int
+	largestBatch            int
+	queueWaitTimes          []time.Duration
+	largestZoneSkew         int
 }
 
-func (_ auctionMetricEmitterDelegate) FetchStatesCompleted(_ time.Duration) error {
+func NewAuctionMetricEmitterDelegate() *auctionMetricEmitterDelegate {
+	return &auctionMetricEmitterDelegate{
+		lock:             &sync.Mutex{},
+		failuresByReason: map[string]int{},
+	}
+}
+
+func (_ *auctionMetricEmitterDelegate) FetchStatesCompleted(_ time.Duration) error {
+	return nil
+}
+
+func (_ *auctionMetricEmitterDelegate) FailedCellStateRequest() {}
+
+func (_ *auctionMetricEmitterDelegate) CellStatesFetched(_ int) {}
+
+func (m *auctionMetricEmitterDelegate) ScheduleCompleted(duration time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.rounds++
+	m.scheduleDuration += duration
 	return nil
 }
 
-func (_ auctionMetricEmitterDelegate) FailedCellStateRequest() {}
+func (_ *auctionMetricEmitterDelegate) CommitCompleted(_ time.Duration) error {
+	return nil
+}
+
+func (m *auctionMetricEmitterDelegate) CellCommitCompleted(_ string, duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.cellCommits++
+	m.cellCommitDuration += duration
+	if duration > m.slowestCellCommit {
+		m.slowestCellCommit = duration
+	}
+}
+
+func (_ *auctionMetricEmitterDelegate) UnknownCommitOutcomes(_ int, _ int) {}
+
+func (m *auctionMetricEmitterDelegate) PlacementFailures(reason string, lrps int, tasks int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.failuresByReason[reason] += lrps + tasks
+}
+
+func (m *auctionMetricEmitterDelegate) InflightLimitRejections(lrps int, tasks int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.inflightLimitRejections += lrps + tasks
+}
+
+func (m *auctionMetricEmitterDelegate) BatchSize(lrps int, tasks int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.batchedAuctions += lrps + tasks
+	if lrps+tasks > m.largestBatch {
+		m.largestBatch = lrps + tasks
+	}
+}
+
+func (m *auctionMetricEmitterDelegate) QueueWaitTimes(waitTimes []time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queueWaitTimes = append(m.queueWaitTimes, waitTimes...)
+}
+
+func (m *auctionMetricEmitterDelegate) ZoneSkew(instances int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if instances > m.largestZoneSkew {
+		m.largestZoneSkew = instances
+	}
+}
+
+func (_ *auctionMetricEmitterDelegate) QueueDepth(_ int, _ int) {}
 
-func (_ auctionMetricEmitterDelegate) AuctionCompleted(_ auctiontypes.AuctionResults) {}
+func (_ *auctionMetricEmitterDelegate) OldestQueuedAuctionAge(_ time.Duration) {}
+
+func (_ *auctionMetricEmitterDelegate) AuctionsDeduplicated(_ int, _ int) {}
+
+func (_ *auctionMetricEmitterDelegate) AuctionCompleted(_ auctiontypes.AuctionResults) {}
+
+func (m *auctionMetricEmitterDelegate) PrintSummary() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.rounds == 0 {
+		return
+	}
+
+	var totalWait, longestWait time.Duration
+	for _, wait := range m.queueWaitTimes {
+		totalWait += wait
+		if wait > longestWait {
+			longestWait = wait
+		}
+	}
+	var meanWait, meanCellCommit time.Duration
+	if len(m.queueWaitTimes) > 0 {
+		meanWait = totalWait / time.Duration(len(m.queueWaitTimes))
+	}
+	if m.cellCommits > 0 {
+		meanCellCommit = m.cellCommitDuration / time.Duration(m.cellCommits)
+	}
+
+	fmt.Printf("Rounds: %d, mean schedule: %s, largest batch: %d (%d auctions)\n", m.rounds, m.scheduleDuration/time.Duration(m.rounds), m.largestBatch, m.batchedAuctions)
+	fmt.Printf("Queue wait: mean %s, longest %s\n", meanWait, longestWait)
+	fmt.Printf("Cell commits: %d, mean %s, slowest %s\n", m.cellCommits, meanCellCommit, m.slowestCellCommit)
+	fmt.Printf("Largest zone skew: %d, in-flight limit rejections: %d\n", m.largestZoneSkew, m.inflightLimitRejections)
+	for reason, count := range m.failuresByReason {
+		fmt.Printf("Failures (%s): %d\n", reason, count)
+	}
+}
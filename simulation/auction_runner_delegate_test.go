@@ -9,7 +9,7 @@ import (
 
 type auctionRunnerDelegate struct {
 	cells       map[string]rep.Client
-	cellLimit   int
+	cellGuids   []string
 	workResults auctiontypes.AuctionResults
 	lock        *sync.Mutex
 }
@@ -21,19 +21,34 @@ func NewAuctionRunnerDelegate(cells map[string]rep.SimClient) *auctionRunnerDele
 	}
 	return &auctionRunnerDelegate{
 		cells:     typecastCells,
-		cellLimit: len(typecastCells),
+		cellGuids: cellsInPools(standardPool),
 		lock:      &sync.Mutex{},
 	}
 }
 
+// SetCellLimit limits the auction to the first limit cells of the fleet.
 func (a *auctionRunnerDelegate) SetCellLimit(limit int) {
-	a.cellLimit = limit
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.cellGuids = []string{}
+	for i := 0; i < limit; i++ {
+		a.cellGuids = append(a.cellGuids, cellGuid(i))
+	}
+}
+
+// SetCellPools limits the auction to the cells of the given pools.
+func (a *auctionRunnerDelegate) SetCellPools(pools ...string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.cellGuids = cellsInPools(pools...)
 }
 
 func (a *auctionRunnerDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
 	subset := map[string]rep.Client{}
-	for i := 0; i < a.cellLimit; i++ {
-		subset[cellGuid(i)] = a.cells[cellGuid(i)]
+	for _, guid := range a.cellGuids {
+		subset[guid] = a.cells[guid]
 	}
 	return subset, nil
 }
@@ -45,6 +60,10 @@ func (a *auctionRunnerDelegate) AuctionCompleted(work auctiontypes.AuctionResult
 	a.workResults.FailedTasks = append(a.workResults.FailedTasks, work.FailedTasks...)
 	a.workResults.SuccessfulLRPs = append(a.workResults.SuccessfulLRPs, work.SuccessfulLRPs...)
 	a.workResults.SuccessfulTasks = append(a.workResults.SuccessfulTasks, work.SuccessfulTasks...)
+	a.workResults.UnknownLRPs = append(a.workResults.UnknownLRPs, work.UnknownLRPs...)
+	a.workResults.UnknownTasks = append(a.workResults.UnknownTasks, work.UnknownTasks...)
+	a.workResults.CancelledLRPs = append(a.workResults.CancelledLRPs, work.CancelledLRPs...)
+	a.workResults.CancelledTasks = append(a.workResults.CancelledTasks, work.CancelledTasks...)
 }
 
 func (a *auctionRunnerDelegate) ResultSize() int {
@@ -54,7 +73,11 @@ func (a *auctionRunnerDelegate) ResultSize() int {
 	return len(a.workResults.FailedLRPs) +
 		len(a.workResults.FailedTasks) +
 		len(a.workResults.SuccessfulLRPs) +
-		len(a.workResults.SuccessfulTasks)
+		len(a.workResults.SuccessfulTasks) +
+		len(a.workResults.UnknownLRPs) +
+		len(a.workResults.UnknownTasks) +
+		len(a.workResults.CancelledLRPs) +
+		len(a.workResults.CancelledTasks)
 }
 
 func (a *auctionRunnerDelegate) Results() auctiontypes.AuctionResults {
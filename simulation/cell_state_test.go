@@ -0,0 +1,99 @@
+package simulation_test
+
+import (
+	"code.cloudfoundry.org/auction/simulation/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cell state", func() {
+	BeforeEach(func() {
+		util.ResetGuids()
+	})
+
+	Describe("optional placement tags", func() {
+		BeforeEach(func() {
+			runnerDelegate.SetCellPools(standardPool, largePool)
+		})
+
+		It("places work with the optional tag only on cells offering it, alongside untagged work", func() {
+			tagged := fleetLRPStarts(100, linuxStack, 1, nil, []string{ssdTag})
+			untagged := fleetLRPStarts(300, linuxStack, 1, nil, nil)
+			results := auctionOnFleet(append(tagged, untagged...), nil)
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+
+			taggedPools := map[string]int{}
+			for _, lrp := range results.SuccessfulLRPs {
+				if len(lrp.PlacementTags) > 0 {
+					taggedPools[poolOfCell(lrp.Winner)]++
+				}
+			}
+			Expect(taggedPools).To(Equal(map[string]int{largePool: 100}))
+			Expect(winningPools(results)[standardPool]).To(BeNumerically(">", 0))
+		})
+	})
+
+	Describe("evacuation", func() {
+		It("never places work on cells that are evacuating", func() {
+			runnerDelegate.SetCellPools(standardPool, drainingPool)
+
+			results := auctionOnFleet(fleetLRPStarts(300, linuxStack, 1, nil, nil), fleetTaskStarts(100, linuxStack))
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(results.FailedTasks).To(BeEmpty())
+			Expect(winningPools(results)).To(Equal(map[string]int{standardPool: 400}))
+		})
+
+		It("fails work when every compatible cell is evacuating", func() {
+			runnerDelegate.SetCellPools(drainingPool)
+
+			results := auctionOnFleet(fleetLRPStarts(50, linuxStack, 1, nil, nil), nil)
+
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(results.FailedLRPs).To(HaveLen(50))
+		})
+
+		Context("when cells start and stop evacuating at runtime", func() {
+			var evacuating []string
+
+			BeforeEach(func() {
+				runnerDelegate.SetCellPools(standardPool)
+				evacuating = []string{}
+				for i := 0; i < numCells/2; i++ {
+					evacuating = append(evacuating, cellGuid(i))
+					setEvacuating(cellGuid(i), true)
+				}
+			})
+
+			winnersAmong := func(guids []string, offset int) int {
+				inSet := map[string]bool{}
+				for _, guid := range guids {
+					inSet[guid] = true
+				}
+
+				count := 0
+				for _, lrp := range runnerDelegate.Results().SuccessfulLRPs[offset:] {
+					if inSet[lrp.Winner] {
+						count++
+					}
+				}
+				return count
+			}
+
+			It("skips them while they evacuate and uses them again once they stop", func() {
+				results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+				Expect(results.SuccessfulLRPs).To(HaveLen(200))
+				Expect(winnersAmong(evacuating, 0)).To(BeZero())
+
+				for _, guid := range evacuating {
+					setEvacuating(guid, false)
+				}
+
+				results = auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+				Expect(results.SuccessfulLRPs).To(HaveLen(400))
+				Expect(winnersAmong(evacuating, 200)).To(BeNumerically(">", 100))
+			})
+		})
+	})
+})
@@ -0,0 +1,172 @@
+package simulation_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/clock"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fault injection", func() {
+	var all, healthy, faulty []string
+
+	injectFaults := func(guids []string, faults simulationrep.Faults) {
+		for _, guid := range guids {
+			setFaults(guid, faults)
+		}
+	}
+
+	restartRunner := func(options ...auctionrunner.RunnerOption) {
+		runnerProcess.Signal(os.Interrupt)
+		Eventually(runnerProcess.Wait(), 20).Should(Receive())
+
+		runner = auctionrunner.New(
+			logger,
+			runnerDelegate,
+			metricEmitterDelegate,
+			clock.NewClock(),
+			workPool,
+			0.25,
+			defaultMaxContainerStartCount,
+			auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+			options...,
+		)
+		runnerProcess = ifrit.Invoke(runner)
+	}
+
+	winnersAmong := func(guids []string, winners []string) int {
+		inSet := map[string]bool{}
+		for _, guid := range guids {
+			inSet[guid] = true
+		}
+
+		count := 0
+		for _, winner := range winners {
+			if inSet[winner] {
+				count++
+			}
+		}
+		return count
+	}
+
+	lrpWinners := func(results []auctiontypes.LRPAuction) []string {
+		winners := []string{}
+		for _, lrp := range results {
+			winners = append(winners, lrp.Winner)
+		}
+		return winners
+	}
+
+	BeforeEach(func() {
+		util.ResetGuids()
+		runnerDelegate.SetCellPools(standardPool)
+
+		all, healthy, faulty = []string{}, []string{}, []string{}
+		for i := 0; i < numCells; i++ {
+			all = append(all, cellGuid(i))
+			if i%2 == 0 {
+				healthy = append(healthy, cellGuid(i))
+			} else {
+				faulty = append(faulty, cellGuid(i))
+			}
+		}
+	})
+
+	Describe("fetching state", func() {
+		It("places work only on the cells that answer", func() {
+			injectFaults(faulty[:25], simulationrep.Faults{StateErrorRate: 1})
+			injectFaults(faulty[25:], simulationrep.Faults{StateTimeoutRate: 1, Hang: 100 * time.Millisecond})
+
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), fleetTaskStarts(50, linuxStack))
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(results.FailedTasks).To(BeEmpty())
+			Expect(winnersAmong(faulty, lrpWinners(results.SuccessfulLRPs))).To(BeZero())
+			Expect(winnersAmong(healthy, lrpWinners(results.SuccessfulLRPs))).To(Equal(200))
+		})
+
+		It("still places work when every cell is slow to answer", func() {
+			injectFaults(all, simulationrep.Faults{
+				StateLatency:   simulationrep.Latency{Distribution: simulationrep.NormalDistribution, Mean: 5 * time.Millisecond, StdDev: 2 * time.Millisecond},
+				PerformLatency: simulationrep.Latency{Distribution: simulationrep.UniformDistribution, Min: time.Millisecond, Max: 10 * time.Millisecond},
+			})
+
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+
+			Expect(results.SuccessfulLRPs).To(HaveLen(200))
+		})
+	})
+
+	Describe("committing work", func() {
+		It("reports work sent to cells that fail the commit as unknown", func() {
+			injectFaults(all, simulationrep.Faults{PerformErrorRate: 1})
+
+			results := auctionOnFleet(fleetLRPStarts(100, linuxStack, 1, nil, nil), nil)
+
+			Expect(results.UnknownLRPs).To(HaveLen(100))
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+		})
+
+		It("reports work sent to cells that time out as unknown, although the cells hold it", func() {
+			injectFaults(all, simulationrep.Faults{PerformTimeoutRate: 1, Hang: 100 * time.Millisecond})
+
+			results := auctionOnFleet(fleetLRPStarts(100, linuxStack, 1, nil, nil), nil)
+			Expect(results.UnknownLRPs).To(HaveLen(100))
+
+			injectFaults(all, simulationrep.Faults{})
+			held := 0
+			for _, guid := range all {
+				state, err := cells[guid].State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				held += len(state.LRPs)
+			}
+			Expect(held).To(Equal(100))
+		})
+
+		It("fails the work cells reject when the scheduler does not retry", func() {
+			injectFaults(all, simulationrep.Faults{RejectionRate: 0.5})
+
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+
+			Expect(results.UnknownLRPs).To(BeEmpty())
+			Expect(results.FailedLRPs).NotTo(BeEmpty())
+			Expect(results.SuccessfulLRPs).NotTo(BeEmpty())
+		})
+
+		It("places the work cells reject elsewhere when the scheduler retries", func() {
+			restartRunner(auctionrunner.WithSchedulerOptions(auctionrunner.WithCommitRetryPasses(10)))
+			injectFaults(all, simulationrep.Faults{RejectionRate: 0.3})
+
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+
+			Expect(results.SuccessfulLRPs).To(HaveLen(200))
+		})
+	})
+
+	Describe("flapping cells", func() {
+		It("keeps auctioning while cells come and go, losing track only of work sent to them", func() {
+			injectFaults(faulty, simulationrep.Faults{
+				Flapping: &simulationrep.Flapping{Up: 20 * time.Millisecond, Down: 20 * time.Millisecond},
+			})
+
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, nil, nil), nil)
+			for i := 0; i < 4; i++ {
+				time.Sleep(10 * time.Millisecond)
+				results = auctionOnFleet(fleetLRPStarts(50, linuxStack, 1, nil, nil), nil)
+			}
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(winnersAmong(healthy, lrpWinners(results.SuccessfulLRPs))).To(BeNumerically(">", 0))
+			Expect(winnersAmong(healthy, lrpWinners(results.UnknownLRPs))).To(BeZero())
+		})
+	})
+})
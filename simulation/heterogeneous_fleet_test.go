@@ -0,0 +1,170 @@
+package simulation_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func poolOfCell(guid string) string {
+	for _, cell := range fleet {
+		if cell.Guid == guid {
+			return cell.Pool
+		}
+	}
+	return ""
+}
+
+func fleetLRPStarts(count int, stack string, memoryMB int32, volumeDrivers, placementTags []string) []auctioneer.LRPStartRequest {
+	starts := []auctioneer.LRPStartRequest{}
+	for i := 0; i < count; i++ {
+		starts = append(starts, auctioneer.NewLRPStartRequest(
+			util.NewGrayscaleGuid("HET"),
+			"auction",
+			[]int{0},
+			rep.NewResource(memoryMB, 1, 10),
+			rep.NewPlacementConstraint(models.PreloadedRootFS(stack), placementTags, volumeDrivers),
+		))
+	}
+	return starts
+}
+
+func fleetTaskStarts(count int, stack string) []auctioneer.TaskStartRequest {
+	starts := []auctioneer.TaskStartRequest{}
+	for i := 0; i < count; i++ {
+		starts = append(starts, auctioneer.NewTaskStartRequest(rep.NewTask(
+			util.NewGuid("HET-TASK"),
+			"auction",
+			rep.NewResource(1, 1, 10),
+			rep.NewPlacementConstraint(models.PreloadedRootFS(stack), []string{}, []string{}),
+		)))
+	}
+	return starts
+}
+
+// auctionOnFleet schedules the work and waits for every auction of it to
+// complete, returning the results collected so far.
+func auctionOnFleet(lrps []auctioneer.LRPStartRequest, tasks []auctioneer.TaskStartRequest) auctiontypes.AuctionResults {
+	expected := runnerDelegate.ResultSize() + len(lrps) + len(tasks)
+	if len(lrps) > 0 {
+		Expect(runner.ScheduleLRPsForAuctions(lrps)).To(Succeed())
+	}
+	if len(tasks) > 0 {
+		Expect(runner.ScheduleTasksForAuctions(tasks)).To(Succeed())
+	}
+	Eventually(runnerDelegate.ResultSize, time.Minute, 100*time.Millisecond).Should(Equal(expected))
+	return runnerDelegate.Results()
+}
+
+func winningPools(results auctiontypes.AuctionResults) map[string]int {
+	pools := map[string]int{}
+	for _, lrp := range results.SuccessfulLRPs {
+		pools[poolOfCell(lrp.Winner)]++
+	}
+	for _, task := range results.SuccessfulTasks {
+		pools[poolOfCell(task.Winner)]++
+	}
+	return pools
+}
+
+func placementErrors(results auctiontypes.AuctionResults) map[string]int {
+	errors := map[string]int{}
+	for _, lrp := range results.FailedLRPs {
+		errors[lrp.PlacementError]++
+	}
+	for _, task := range results.FailedTasks {
+		errors[task.PlacementError]++
+	}
+	return errors
+}
+
+var _ = Describe("Heterogeneous fleets", func() {
+	BeforeEach(func() {
+		util.ResetGuids()
+		runnerDelegate.SetCellPools(standardPool, windowsPool, largePool, isolatedPool)
+	})
+
+	Describe("stacks", func() {
+		It("places windows work only on cells serving the windows stack", func() {
+			results := auctionOnFleet(fleetLRPStarts(200, windowsStack, 1, nil, nil), fleetTaskStarts(50, windowsStack))
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+			Expect(results.FailedTasks).To(BeEmpty())
+			pools := winningPools(results)
+			Expect(pools[windowsPool] + pools[largePool]).To(Equal(250))
+			Expect(pools[windowsPool]).To(BeNumerically(">", 0))
+			Expect(pools[largePool]).To(BeNumerically(">", 0))
+		})
+
+		It("fails work for a stack no cell serves with a cell mismatch", func() {
+			results := auctionOnFleet(fleetLRPStarts(500, "plan9", 1, nil, nil), fleetTaskStarts(100, "plan9"))
+
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(results.SuccessfulTasks).To(BeEmpty())
+			Expect(placementErrors(results)).To(Equal(map[string]int{
+				auctiontypes.ErrorCellMismatch.Error(): 600,
+			}))
+		})
+	})
+
+	Describe("volume drivers", func() {
+		It("fails work needing a driver no cell has with a volume driver mismatch", func() {
+			results := auctionOnFleet(fleetLRPStarts(500, linuxStack, 1, []string{"missing-driver"}, nil), nil)
+
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(placementErrors(results)).To(Equal(map[string]int{
+				auctiontypes.ErrorVolumeDriverMismatch.Error(): 500,
+			}))
+		})
+
+		It("fails work needing a driver only the isolation segment has without its tag", func() {
+			results := auctionOnFleet(fleetLRPStarts(200, linuxStack, 1, []string{isolatedDriver}, nil), nil)
+
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(placementErrors(results)).To(Equal(map[string]int{
+				auctiontypes.NewPlacementTagMismatchError(nil).Error(): 200,
+			}))
+		})
+	})
+
+	Describe("placement tags", func() {
+		It("confines tagged work to the isolation segment and keeps untagged work out of it", func() {
+			tagged := fleetLRPStarts(200, linuxStack, 1, []string{isolatedDriver}, []string{isolationTag})
+			untagged := fleetLRPStarts(300, linuxStack, 1, nil, nil)
+			results := auctionOnFleet(append(tagged, untagged...), nil)
+
+			Expect(results.FailedLRPs).To(BeEmpty())
+			pools := winningPools(results)
+			Expect(pools[isolatedPool]).To(Equal(200))
+			Expect(pools[windowsPool]).To(BeZero())
+			Expect(pools[standardPool] + pools[largePool]).To(Equal(300))
+		})
+
+		It("fails work with a tag no cell has with a placement tag mismatch", func() {
+			results := auctionOnFleet(fleetLRPStarts(500, linuxStack, 1, nil, []string{"missing-tag"}), nil)
+
+			Expect(results.SuccessfulLRPs).To(BeEmpty())
+			Expect(placementErrors(results)).To(Equal(map[string]int{
+				auctiontypes.NewPlacementTagMismatchError([]string{"missing-tag"}).Error(): 500,
+			}))
+		})
+	})
+
+	Describe("resources", func() {
+		It("places work too large for most cells on the large cells until they are full", func() {
+			results := auctionOnFleet(fleetLRPStarts(30, linuxStack, 200, nil, nil), nil)
+
+			Expect(winningPools(results)).To(Equal(map[string]int{largePool: 20}))
+			Expect(results.FailedLRPs).To(HaveLen(10))
+			for _, lrp := range results.FailedLRPs {
+				Expect(lrp.PlacementError).To(ContainSubstring("insufficient resources"))
+			}
+		})
+	})
+})
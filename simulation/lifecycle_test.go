@@ -0,0 +1,166 @@
+package simulation_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Container lifecycle", func() {
+	const lifecycleCells = 10
+
+	var (
+		lifecycleReps     map[string]rep.SimClient
+		lifecycleDelegate *auctionRunnerDelegate
+		lifecycleRunner   auctiontypes.AuctionRunner
+		lifecycleProcess  ifrit.Process
+	)
+
+	startRunner := func(lifecycle *simulationrep.Lifecycle) {
+		s := scenario.Scenario{Pools: []scenario.Pool{{
+			Name: "lifecycle", Count: lifecycleCells, Zones: defaultZones, Stack: linuxStack,
+			MemoryMB: 100, DiskMB: 100, Containers: 10,
+			Lifecycle: lifecycle,
+		}}}
+		lifecycleReps = scenario.BuildReps(s.Cells())
+
+		lifecycleDelegate = NewAuctionRunnerDelegate(lifecycleReps)
+		lifecycleDelegate.SetCellLimit(lifecycleCells)
+
+		lifecycleRunner = auctionrunner.New(
+			logger,
+			lifecycleDelegate,
+			NewAuctionMetricEmitterDelegate(),
+			clock.NewClock(),
+			workPool,
+			0.25,
+			defaultMaxContainerStartCount,
+			auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+		)
+		lifecycleProcess = ifrit.Invoke(lifecycleRunner)
+	}
+
+	// auctionWave schedules the work and waits for every auction of it to
+	// complete, returning the results collected so far.
+	auctionWave := func(lrps []auctioneer.LRPStartRequest, tasks []auctioneer.TaskStartRequest) auctiontypes.AuctionResults {
+		expected := lifecycleDelegate.ResultSize() + len(lrps) + len(tasks)
+		if len(lrps) > 0 {
+			Expect(lifecycleRunner.ScheduleLRPsForAuctions(lrps)).To(Succeed())
+		}
+		if len(tasks) > 0 {
+			Expect(lifecycleRunner.ScheduleTasksForAuctions(tasks)).To(Succeed())
+		}
+		Eventually(lifecycleDelegate.ResultSize, time.Minute, 10*time.Millisecond).Should(Equal(expected))
+		return lifecycleDelegate.Results()
+	}
+
+	states := func() []rep.CellState {
+		cellStates := []rep.CellState{}
+		for _, cell := range lifecycleReps {
+			state, err := cell.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			cellStates = append(cellStates, state)
+		}
+		return cellStates
+	}
+
+	BeforeEach(func() {
+		util.ResetGuids()
+	})
+
+	AfterEach(func() {
+		lifecycleProcess.Signal(os.Interrupt)
+		Eventually(lifecycleProcess.Wait(), 20).Should(Receive())
+	})
+
+	It("keeps placing tasks beyond the fleet's capacity as earlier ones complete", func() {
+		startRunner(&simulationrep.Lifecycle{
+			StartDelay:   simulationrep.Latency{Mean: 10 * time.Millisecond},
+			TaskDuration: simulationrep.Latency{Mean: 50 * time.Millisecond},
+		})
+
+		completedTasks := func() int {
+			completed := 0
+			for _, cell := range lifecycleReps {
+				completed += cell.(*simulationrep.SimulationRep).CompletedTasks()
+			}
+			return completed
+		}
+
+		for wave := 0; wave < 5; wave++ {
+			// two waves do not fit the fleet, so the previous one must be done
+			Eventually(completedTasks, 20*time.Second, 10*time.Millisecond).Should(Equal(wave * 80))
+			auctionWave(nil, fleetTaskStarts(80, linuxStack))
+		}
+
+		results := lifecycleDelegate.Results()
+		Expect(results.FailedTasks).To(BeEmpty())
+		Expect(results.SuccessfulTasks).To(HaveLen(400))
+		Eventually(completedTasks, 20*time.Second, 10*time.Millisecond).Should(Equal(400))
+	})
+
+	It("fails tasks beyond the fleet's capacity when they never complete", func() {
+		startRunner(nil)
+
+		for wave := 0; wave < 2; wave++ {
+			auctionWave(nil, fleetTaskStarts(80, linuxStack))
+		}
+
+		results := lifecycleDelegate.Results()
+		Expect(results.SuccessfulTasks).To(HaveLen(lifecycleCells * 10))
+		Expect(results.FailedTasks).To(HaveLen(160 - lifecycleCells*10))
+	})
+
+	It("stops counting containers as starting once they run", func() {
+		startRunner(&simulationrep.Lifecycle{
+			StartDelay: simulationrep.Latency{Distribution: simulationrep.UniformDistribution, Min: 200 * time.Millisecond, Max: 300 * time.Millisecond},
+		})
+
+		auctionWave(fleetLRPStarts(50, linuxStack, 1, nil, nil), nil)
+
+		starting := func() int {
+			count := 0
+			for _, state := range states() {
+				count += state.StartingContainerCount
+			}
+			return count
+		}
+		Expect(starting()).To(BeNumerically(">", 0))
+		Eventually(starting).Should(BeZero())
+	})
+
+	It("frees the resources of LRPs that crash so that their replacements can be placed", func() {
+		startRunner(&simulationrep.Lifecycle{
+			CrashRate:   1,
+			TimeToCrash: simulationrep.Latency{Mean: 20 * time.Millisecond},
+		})
+
+		for wave := 0; wave < 3; wave++ {
+			auctionWave(fleetLRPStarts(lifecycleCells*10, linuxStack, 1, nil, nil), nil)
+			Eventually(func() int {
+				lrps := 0
+				for _, state := range states() {
+					lrps += len(state.LRPs)
+				}
+				return lrps
+			}).Should(BeZero())
+		}
+
+		results := lifecycleDelegate.Results()
+		Expect(results.FailedLRPs).To(BeEmpty())
+		Expect(results.SuccessfulLRPs).To(HaveLen(3 * lifecycleCells * 10))
+	})
+})
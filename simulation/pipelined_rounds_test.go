@@ -0,0 +1,145 @@
+package simulation_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/workpool"
+	"github.com/tedsuo/ifrit"
+)
+
+const (
+	pipelinedRounds       = 40
+	pipelinedLRPsPerRound = 10
+	pipelinedCellLatency  = 50 * time.Millisecond
+)
+
+// slowRep adds a fixed latency to State and Perform, standing in for the
+// network round trip to a remote cell.
+type slowRep struct {
+	rep.SimClient
+	clock   clock.Clock
+	latency time.Duration
+}
+
+func (r *slowRep) State(logger lager.Logger) (rep.CellState, error) {
+	r.clock.Sleep(r.latency)
+	return r.SimClient.State(logger)
+}
+
+func (r *slowRep) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+	r.clock.Sleep(r.latency)
+	return r.SimClient.Perform(logger, work)
+}
+
+// BenchmarkPipelinedRounds places a backlog of work on slow cells with and
+// without pipelined rounds. All of the work is queued up front and each round
+// takes only a slice of it, so the runner rather than the arrival rate limits
+// throughput. Time is simulated with a fake clock, so the reported throughput
+// and wait are in simulated time:
+//
+//	go test ./simulation -run '^$' -bench PipelinedRounds
+func BenchmarkPipelinedRounds(b *testing.B) {
+	b.Run("sequential", func(b *testing.B) {
+		benchmarkBacklog(b)
+	})
+	b.Run("pipelined", func(b *testing.B) {
+		benchmarkBacklog(b, auctionrunner.WithPipelinedRounds())
+	})
+}
+
+func benchmarkBacklog(b *testing.B, options ...auctionrunner.RunnerOption) {
+	var totalDuration, totalWait time.Duration
+	for i := 0; i < b.N; i++ {
+		duration, meanWait := runBacklog(b, options...)
+		totalDuration += duration
+		totalWait += meanWait
+	}
+
+	b.ReportMetric(float64(b.N*pipelinedRounds*pipelinedLRPsPerRound)/totalDuration.Seconds(), "auctions/s")
+	b.ReportMetric(totalWait.Seconds()*1000/float64(b.N), "ms-mean-wait")
+}
+
+// runBacklog returns how long placing the backlog took and the mean wait of the
+// placed auctions, both in simulated time.
+func runBacklog(b *testing.B, options ...auctionrunner.RunnerOption) (time.Duration, time.Duration) {
+	fakeClock := fakeclock.NewFakeClock(time.Now())
+
+	slowCells := map[string]rep.SimClient{}
+	for guid, cell := range scenario.BuildReps(scenario.Scenario{Pools: fleetPools[:1]}.Cells()) {
+		slowCells[guid] = &slowRep{SimClient: cell, clock: fakeClock, latency: pipelinedCellLatency}
+	}
+	delegate := NewAuctionRunnerDelegate(slowCells)
+
+	pool, err := workpool.NewWorkPool(500)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Stop()
+
+	options = append(options, auctionrunner.WithBatchOptions(auctionrunner.WithMaxBatchSize(pipelinedLRPsPerRound)))
+	pipelineRunner := auctionrunner.New(
+		lager.NewLogger("pipelined-rounds"),
+		delegate,
+		NewAuctionMetricEmitterDelegate(),
+		fakeClock,
+		pool,
+		0.25,
+		defaultMaxContainerStartCount,
+		auctionfashion.NewAuctionType(auctionfashion.DefaultAuction),
+		options...,
+	)
+	process := ifrit.Invoke(pipelineRunner)
+	defer func() {
+		process.Signal(os.Interrupt)
+		<-process.Wait()
+	}()
+
+	linuxRootFSURL := models.PreloadedRootFS(linuxStack)
+	lrpStarts := []auctioneer.LRPStartRequest{}
+	for i := 0; i < pipelinedRounds*pipelinedLRPsPerRound; i++ {
+		lrpStarts = append(lrpStarts, auctioneer.NewLRPStartRequest(util.NewGrayscaleGuid("PPP"), "auction", []int{0}, rep.NewResource(1, 1, 10), rep.NewPlacementConstraint(linuxRootFSURL, []string{}, []string{})))
+	}
+
+	startTime := fakeClock.Now()
+	err = pipelineRunner.ScheduleLRPsForAuctions(lrpStarts)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// time only passes while a cell is answering, one step at a time, and
+	// each step gives the runner a moment to start waiting on the clock too
+	deadline := time.Now().Add(time.Minute)
+	for delegate.ResultSize() < len(lrpStarts) {
+		if time.Now().After(deadline) {
+			b.Fatalf("placed %d of %d auctions", delegate.ResultSize(), len(lrpStarts))
+		}
+		if fakeClock.WatcherCount() > 0 {
+			fakeClock.Increment(time.Millisecond)
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+	duration := fakeClock.Since(startTime)
+
+	results := delegate.Results()
+	if len(results.SuccessfulLRPs) != len(lrpStarts) {
+		b.Fatalf("placed %d of %d auctions", len(results.SuccessfulLRPs), len(lrpStarts))
+	}
+
+	var totalWait time.Duration
+	for _, lrp := range results.SuccessfulLRPs {
+		totalWait += lrp.WaitDuration
+	}
+	return duration, totalWait / time.Duration(len(results.SuccessfulLRPs))
+}
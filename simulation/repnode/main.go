@@ -1,12 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"strings"
 
 	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
 	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
@@ -15,6 +20,7 @@ import (
 	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
 	"github.com/tedsuo/rata"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var memoryMB = flag.Int("memoryMB", 100, "total available memory in MB")
@@ -22,8 +28,15 @@ var diskMB = flag.Int("diskMB", 100, "total available disk in MB")
 var containers = flag.Int("containers", 100, "total available containers")
 var repGuid = flag.String("repGuid", "", "rep-guid")
 var httpAddr = flag.String("httpAddr", "", "http server addres")
-var stack = flag.String("stack", "", "stack")
+var stack = flag.String("stack", "", "stack, or comma separated stacks")
 var zone = flag.String("zone", "Z0", "availability zone")
+var volumeDrivers = flag.String("volumeDrivers", "", "comma separated volume drivers")
+var placementTags = flag.String("placementTags", "", "comma separated placement tags")
+var optionalPlacementTags = flag.String("optionalPlacementTags", "", "comma separated optional placement tags")
+var evacuating = flag.Bool("evacuating", false, "report the cell as evacuating")
+var faults = flag.String("faults", "", "YAML or JSON describing the faults to inject")
+var lifecycle = flag.String("lifecycle", "", "YAML or JSON describing how containers start, complete and crash")
+var preloadedWork = flag.String("preloadedWork", "", "JSON of the work the rep starts out running, started with its faults suspended")
 
 func main() {
 	lagerflags.AddFlags(flag.CommandLine)
@@ -37,19 +50,82 @@ func main() {
 		panic("need http addr")
 	}
 
-	simulationRep := simulationrep.New(*stack, *zone, rep.Resources{
+	injectedFaults := simulationrep.Faults{}
+	err := yaml.UnmarshalStrict([]byte(*faults), &injectedFaults)
+	if err == nil {
+		err = injectedFaults.Validate()
+	}
+	if err != nil {
+		log.Fatalln("invalid faults:", err)
+	}
+
+	stacks := splitList(*stack)
+	if len(stacks) == 0 {
+		stacks = []string{""}
+	}
+
+	options := []simulationrep.Option{
+		simulationrep.WithRootFSProviders(rep.RootFSProviders{
+			models.PreloadedRootFSScheme: rep.NewFixedSetRootFSProvider(stacks...),
+		}),
+		simulationrep.WithPlacementTags(splitList(*placementTags)...),
+		simulationrep.WithOptionalPlacementTags(splitList(*optionalPlacementTags)...),
+		simulationrep.WithEvacuating(*evacuating),
+		simulationrep.WithFaults(injectedFaults),
+	}
+
+	if *lifecycle != "" {
+		containerLifecycle := simulationrep.Lifecycle{}
+		err = yaml.UnmarshalStrict([]byte(*lifecycle), &containerLifecycle)
+		if err == nil {
+			err = containerLifecycle.Validate()
+		}
+		if err != nil {
+			log.Fatalln("invalid lifecycle:", err)
+		}
+		options = append(options, simulationrep.WithLifecycle(containerLifecycle))
+	}
+
+	simulationRep := simulationrep.New(stacks[0], *zone, rep.Resources{
 		MemoryMB:   int32(*memoryMB),
 		DiskMB:     int32(*diskMB),
 		Containers: *containers,
-	}, []string{})
+	}, splitList(*volumeDrivers), options...).(*simulationrep.SimulationRep)
 
 	logger, _ := lagerflags.New("repnode-http")
 
+	if *preloadedWork != "" {
+		work := rep.Work{}
+		err = json.Unmarshal([]byte(*preloadedWork), &work)
+		if err != nil {
+			log.Fatalln("invalid preloaded work:", err)
+		}
+
+		simulationRep.SetFaults(simulationrep.Faults{})
+		failed, err := simulationRep.Perform(logger, work)
+		simulationRep.SetFaults(injectedFaults)
+		if err != nil {
+			log.Fatalln("failed to start preloaded work:", err)
+		}
+		if len(failed.LRPs) > 0 || len(failed.Tasks) > 0 {
+			log.Fatalln("cannot fit the preloaded work")
+		}
+	}
+
 	fakeExecutorClient := new(executorfakes.FakeClient)
+	fakeExecutorClient.StopContainerStub = deleteContainer(simulationRep)
+	fakeExecutorClient.DeleteContainerStub = deleteContainer(simulationRep)
 	fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
+	fakeEvacuatable.EvacuateStub = func() {
+		simulationRep.SetEvacuating(true)
+	}
 
 	handlers := rephandlers.New(simulationRep, fakeExecutorClient, fakeEvacuatable, logger.Session(*repGuid))
-	router, err := rata.NewRouter(rep.Routes, handlers)
+	for route, handler := range simulationrep.NewHandlers(simulationRep, logger.Session(*repGuid)) {
+		handlers[route] = handler
+	}
+	routes := append(rata.Routes{}, rep.Routes...)
+	router, err := rata.NewRouter(append(routes, simulationrep.Routes...), handlers)
 	if err != nil {
 		log.Fatalln("failed to make router:", err)
 	}
@@ -62,3 +138,22 @@ func main() {
 		println("EXITED WITH ERROR: ", err.Error())
 	}
 }
+
+// deleteContainer lets the rep handlers, which stop LRP instances and cancel
+// tasks through the executor, reach the simulated containers.
+func deleteContainer(simulationRep *simulationrep.SimulationRep) func(lager.Logger, string) error {
+	return func(logger lager.Logger, guid string) error {
+		err := simulationRep.DeleteContainer(logger, guid)
+		if err == simulationrep.ErrContainerNotFound {
+			return executor.ErrContainerNotFound
+		}
+		return err
+	}
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
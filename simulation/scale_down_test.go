@@ -0,0 +1,58 @@
+package simulation_test
+
+import (
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/bbs/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scaling down", func() {
+	const scaleDownCells = 10
+
+	BeforeEach(func() {
+		util.ResetGuids()
+		runnerDelegate.SetCellLimit(scaleDownCells)
+	})
+
+	It("places new work in the room freed by stopped LRPs and cancelled tasks", func() {
+		results := auctionOnFleet(fleetLRPStarts(scaleDownCells*9, linuxStack, 10, nil, nil), fleetTaskStarts(scaleDownCells*10, linuxStack))
+		Expect(results.SuccessfulLRPs).To(HaveLen(scaleDownCells * 9))
+		Expect(results.SuccessfulTasks).To(HaveLen(scaleDownCells * 10))
+
+		results = auctionOnFleet(fleetLRPStarts(20, linuxStack, 10, nil, nil), nil)
+		Expect(results.FailedLRPs).To(HaveLen(20))
+
+		for _, lrp := range results.SuccessfulLRPs[:30] {
+			instanceKey := models.NewActualLRPInstanceKey(simulationrep.InstanceGuid(lrp.ActualLRPKey), lrp.Winner)
+			Expect(cells[lrp.Winner].StopLRPInstance(logger, lrp.ActualLRPKey, instanceKey)).To(Succeed())
+		}
+		for _, task := range results.SuccessfulTasks {
+			Expect(cells[task.Winner].CancelTask(logger, task.TaskGuid)).To(Succeed())
+		}
+
+		results = auctionOnFleet(fleetLRPStarts(30, linuxStack, 10, nil, nil), nil)
+		Expect(results.SuccessfulLRPs).To(HaveLen(scaleDownCells*9 + 30))
+		Expect(results.FailedLRPs).To(HaveLen(20))
+
+		lrps, tasks := 0, 0
+		for i := 0; i < scaleDownCells; i++ {
+			state, err := cells[cellGuid(i)].State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			lrps += len(state.LRPs)
+			tasks += len(state.Tasks)
+		}
+		Expect(lrps).To(Equal(scaleDownCells * 9))
+		Expect(tasks).To(BeZero())
+	})
+
+	It("fails to stop an LRP that is not running on the cell", func() {
+		results := auctionOnFleet(fleetLRPStarts(1, linuxStack, 1, nil, nil), nil)
+		lrp := results.SuccessfulLRPs[0]
+		instanceKey := models.NewActualLRPInstanceKey(simulationrep.InstanceGuid(lrp.ActualLRPKey), lrp.Winner)
+
+		Expect(cells[lrp.Winner].StopLRPInstance(logger, lrp.ActualLRPKey, instanceKey)).To(Succeed())
+		Expect(cells[lrp.Winner].StopLRPInstance(logger, lrp.ActualLRPKey, instanceKey)).NotTo(Succeed())
+	})
+})
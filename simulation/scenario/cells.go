@@ -0,0 +1,145 @@
+package scenario
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// PreloadedDomain is the domain of the LRPs a cell starts out running. They
+// do not count as starting containers.
+const PreloadedDomain = "preloaded"
+
+// Cell is a single cell of a pool. Stack is the first of its Stacks.
+type Cell struct {
+	Guid          string
+	Pool          string
+	Zone          string
+	Stack         string
+	Stacks        []string
+	Resources     rep.Resources
+	VolumeDrivers []string
+	PlacementTags []string
+	LRPs          []ExistingLRPs
+
+	OptionalPlacementTags []string
+	Evacuating            bool
+	Faults                simulationrep.Faults
+	Lifecycle             *simulationrep.Lifecycle
+}
+
+func CellGuid(index int) string {
+	return fmt.Sprintf("REP-%d", index+1)
+}
+
+// Cells expands the pools into cells named REP-1 to REP-n, in pool order.
+func (s Scenario) Cells() []Cell {
+	cells := []Cell{}
+	for _, pool := range s.Pools {
+		for i := 0; i < pool.Count; i++ {
+			cells = append(cells, Cell{
+				Guid:          CellGuid(len(cells)),
+				Pool:          pool.Name,
+				Zone:          pool.Zones[i%len(pool.Zones)],
+				Stack:         pool.Stack,
+				Stacks:        append([]string{pool.Stack}, pool.Stacks...),
+				Resources:     rep.NewResources(pool.MemoryMB, pool.DiskMB, pool.Containers),
+				VolumeDrivers: pool.VolumeDrivers,
+				PlacementTags: pool.PlacementTags,
+				LRPs:          pool.LRPs,
+
+				OptionalPlacementTags: pool.OptionalPlacementTags,
+				Evacuating:            pool.Evacuating,
+				Faults:                pool.Faults,
+				Lifecycle:             pool.Lifecycle,
+			})
+		}
+	}
+	return cells
+}
+
+// RootFSProviders serves the preloaded rootfs of each of the cell's stacks.
+func (c Cell) RootFSProviders() rep.RootFSProviders {
+	return rep.RootFSProviders{
+		models.PreloadedRootFSScheme: rep.NewFixedSetRootFSProvider(c.Stacks...),
+	}
+}
+
+// BuildReps builds an in-process SimulationRep for every cell.
+func BuildReps(cells []Cell) map[string]rep.SimClient {
+	reps := map[string]rep.SimClient{}
+	for _, cell := range cells {
+		options := []simulationrep.Option{
+			simulationrep.WithRootFSProviders(cell.RootFSProviders()),
+			simulationrep.WithPlacementTags(cell.PlacementTags...),
+			simulationrep.WithOptionalPlacementTags(cell.OptionalPlacementTags...),
+			simulationrep.WithEvacuating(cell.Evacuating),
+			simulationrep.WithFaults(cell.Faults),
+		}
+		if cell.Lifecycle != nil {
+			options = append(options, simulationrep.WithLifecycle(*cell.Lifecycle))
+		}
+
+		reps[cell.Guid] = simulationrep.New(cell.Stack, cell.Zone, cell.Resources, cell.VolumeDrivers, options...)
+	}
+	return reps
+}
+
+// PreloadedWork builds the cell's pre-existing LRPs.
+func (c Cell) PreloadedWork() rep.Work {
+	rootFS := models.PreloadedRootFS(c.Stack)
+
+	work := rep.Work{}
+	for _, lrps := range c.LRPs {
+		for i := 0; i < lrps.Count; i++ {
+			work.LRPs = append(work.LRPs, rep.NewLRP(
+				models.NewActualLRPKey(util.NewGrayscaleGuid("PRELOADED"), 0, PreloadedDomain),
+				rep.NewResource(lrps.MemoryMB, lrps.DiskMB, lrps.MaxPids),
+				rep.NewPlacementConstraint(rootFS, c.PlacementTags, []string{}),
+			))
+		}
+	}
+	return work
+}
+
+// faultInjector is implemented by reps whose faults can be changed, such as
+// the in-process SimulationRep.
+type faultInjector interface {
+	Faults() simulationrep.Faults
+	SetFaults(simulationrep.Faults)
+}
+
+// Seed starts every cell's pre-existing LRPs on its rep. It fails if a cell
+// cannot fit them. Faults are suspended while seeding reps that allow it.
+func Seed(logger lager.Logger, cells []Cell, reps map[string]rep.SimClient) error {
+	for _, cell := range cells {
+		work := cell.PreloadedWork()
+		if len(work.LRPs) == 0 {
+			continue
+		}
+
+		client, ok := reps[cell.Guid]
+		if !ok {
+			return fmt.Errorf("no rep for cell %s", cell.Guid)
+		}
+
+		if injector, ok := client.(faultInjector); ok {
+			faults := injector.Faults()
+			injector.SetFaults(simulationrep.Faults{})
+			defer injector.SetFaults(faults)
+		}
+
+		failed, err := client.Perform(logger, work)
+		if err != nil {
+			return fmt.Errorf("failed to seed cell %s: %s", cell.Guid, err)
+		}
+		if len(failed.LRPs) > 0 {
+			return fmt.Errorf("cell %s of pool %s cannot fit %d of its pre-existing lrps", cell.Guid, cell.Pool, len(failed.LRPs))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package scenario_test
+
+import (
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cells", func() {
+	var (
+		s      scenario.Scenario
+		logger *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		var err error
+		s, err = scenario.Parse([]byte(yamlScenario))
+		Expect(err).NotTo(HaveOccurred())
+		logger = lagertest.NewTestLogger("scenario")
+	})
+
+	It("expands the pools into cells spread across each pool's zones", func() {
+		cells := s.Cells()
+		Expect(cells).To(HaveLen(5))
+
+		guids := []string{}
+		zones := []string{}
+		for _, cell := range cells {
+			guids = append(guids, cell.Guid)
+			zones = append(zones, cell.Zone)
+		}
+		Expect(guids).To(Equal([]string{"REP-1", "REP-2", "REP-3", "REP-4", "REP-5"}))
+		Expect(zones).To(Equal([]string{"Z0", "Z1", "Z0", "Z2", "Z2"}))
+
+		Expect(cells[0].Pool).To(Equal("small"))
+		Expect(cells[0].Stacks).To(Equal([]string{"linux"}))
+		Expect(cells[0].Resources).To(Equal(rep.NewResources(100, 100, 100)))
+		Expect(cells[4].Pool).To(Equal("isolated"))
+		Expect(cells[4].Stack).To(Equal("windows"))
+		Expect(cells[4].Stacks).To(Equal([]string{"windows", "windows2016"}))
+		Expect(cells[4].Resources).To(Equal(rep.NewResources(200, 300, 50)))
+	})
+
+	It("builds reps reporting the cell's configuration", func() {
+		cells := s.Cells()
+		reps := scenario.BuildReps(cells)
+		Expect(reps).To(HaveLen(5))
+
+		state, err := reps["REP-5"].State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Zone).To(Equal("Z2"))
+		Expect(state.TotalResources).To(Equal(rep.NewResources(200, 300, 50)))
+		Expect(state.VolumeDrivers).To(Equal([]string{"my-driver"}))
+		Expect(state.PlacementTags).To(Equal([]string{"segment"}))
+		Expect(state.OptionalPlacementTags).To(Equal([]string{"ssd"}))
+		Expect(state.Evacuating).To(BeTrue())
+		Expect(state.MatchRootFS(models.PreloadedRootFS("windows"))).To(BeTrue())
+		Expect(state.MatchRootFS(models.PreloadedRootFS("windows2016"))).To(BeTrue())
+		Expect(state.MatchRootFS(models.PreloadedRootFS("linux"))).To(BeFalse())
+	})
+
+	It("builds reps with the pool's lifecycle", func() {
+		s.Pools[0].Lifecycle = &simulationrep.Lifecycle{}
+		cells := s.Cells()
+		reps := scenario.BuildReps(cells)
+
+		work := rep.Work{Tasks: []rep.Task{
+			rep.NewTask("tg-1", scenario.AuctionDomain, rep.NewResource(1, 1, 1), rep.NewPlacementConstraint(models.PreloadedRootFS("linux"), nil, nil)),
+		}}
+		_, err := reps["REP-1"].Perform(logger, work)
+		Expect(err).NotTo(HaveOccurred())
+
+		state, err := reps["REP-1"].State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Tasks).To(HaveLen(1))
+		Expect(state.StartingContainerCount).To(BeZero())
+	})
+
+	Describe("Seed", func() {
+		It("starts the pre-existing lrps of every cell", func() {
+			cells := s.Cells()
+			reps := scenario.BuildReps(cells)
+
+			Expect(scenario.Seed(logger, cells, reps)).To(Succeed())
+
+			state, err := reps["REP-1"].State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.LRPs).To(HaveLen(2))
+			Expect(state.LRPs[0].Domain).To(Equal(scenario.PreloadedDomain))
+			Expect(state.AvailableResources).To(Equal(rep.NewResources(80, 90, 98)))
+			Expect(state.StartingContainerCount).To(BeZero())
+
+			state, err = reps["REP-4"].State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.LRPs).To(BeEmpty())
+		})
+
+		It("fails when a cell cannot fit its pre-existing lrps", func() {
+			s.Pools[0].LRPs = []scenario.ExistingLRPs{{Count: 11, MemoryMB: 10, DiskMB: 1}}
+			cells := s.Cells()
+
+			err := scenario.Seed(logger, cells, scenario.BuildReps(cells))
+			Expect(err).To(MatchError("cell REP-1 of pool small cannot fit 1 of its pre-existing lrps"))
+		})
+
+		It("suspends the cell's faults while seeding it", func() {
+			s.Pools[0].Faults = simulationrep.Faults{PerformErrorRate: 1}
+			cells := s.Cells()
+			reps := scenario.BuildReps(cells)
+
+			Expect(scenario.Seed(logger, cells, reps)).To(Succeed())
+
+			state, err := reps["REP-1"].State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.LRPs).To(HaveLen(2))
+
+			_, err = reps["REP-1"].Perform(logger, rep.Work{})
+			Expect(err).To(Equal(simulationrep.ErrInjectedFault))
+		})
+	})
+})
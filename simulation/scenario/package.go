@@ -0,0 +1 @@
+package scenario // import "code.cloudfoundry.org/auction/simulation/scenario"
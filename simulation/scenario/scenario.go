@@ -0,0 +1,232 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const DefaultStack = "linux"
+
+var DefaultZones = []string{"Z0", "Z1"}
+
+// Scenario describes a fleet of cells, built from pools of identical cells,
+// and the waves of work auctioned against it one after another.
+type Scenario struct {
+	Pools []Pool `yaml:"pools"`
+	Waves []Wave `yaml:"waves"`
+}
+
+// Pool describes Count identical cells spread round-robin across Zones.
+// Every cell serves Stack and any further Stacks, and starts out running the
+// pool's pre-existing LRPs. Its reps misbehave as described by Faults, and
+// its containers evolve over time as described by Lifecycle, if any.
+type Pool struct {
+	Name          string         `yaml:"name"`
+	Count         int            `yaml:"count"`
+	Zones         []string       `yaml:"zones"`
+	Stack         string         `yaml:"stack"`
+	Stacks        []string       `yaml:"stacks"`
+	MemoryMB      int32          `yaml:"memory_mb"`
+	DiskMB        int32          `yaml:"disk_mb"`
+	Containers    int            `yaml:"containers"`
+	VolumeDrivers []string       `yaml:"volume_drivers"`
+	PlacementTags []string       `yaml:"placement_tags"`
+	LRPs          []ExistingLRPs `yaml:"lrps"`
+
+	OptionalPlacementTags []string `yaml:"optional_placement_tags"`
+	Evacuating            bool     `yaml:"evacuating"`
+
+	Faults    simulationrep.Faults     `yaml:"faults"`
+	Lifecycle *simulationrep.Lifecycle `yaml:"lifecycle"`
+}
+
+// ExistingLRPs are Count single-instance LRPs already running on a cell.
+type ExistingLRPs struct {
+	Count    int   `yaml:"count"`
+	MemoryMB int32 `yaml:"memory_mb"`
+	DiskMB   int32 `yaml:"disk_mb"`
+	MaxPids  int32 `yaml:"max_pids"`
+}
+
+// Wave is a batch of LRP starts and tasks submitted to the auction together.
+type Wave struct {
+	Name  string         `yaml:"name"`
+	LRPs  []LRPWorkload  `yaml:"lrps"`
+	Tasks []TaskWorkload `yaml:"tasks"`
+}
+
+// LRPWorkload is Processes desired LRPs of Instances instances each.
+type LRPWorkload struct {
+	Processes   int `yaml:"processes"`
+	Instances   int `yaml:"instances"`
+	Resources   `yaml:",inline"`
+	Constraints `yaml:",inline"`
+}
+
+// TaskWorkload is Count identical tasks.
+type TaskWorkload struct {
+	Count       int `yaml:"count"`
+	Resources   `yaml:",inline"`
+	Constraints `yaml:",inline"`
+}
+
+type Resources struct {
+	MemoryMB int32 `yaml:"memory_mb"`
+	DiskMB   int32 `yaml:"disk_mb"`
+	MaxPids  int32 `yaml:"max_pids"`
+}
+
+type Constraints struct {
+	Stack         string   `yaml:"stack"`
+	PlacementTags []string `yaml:"placement_tags"`
+	VolumeDrivers []string `yaml:"volume_drivers"`
+}
+
+// Load reads a scenario from a YAML or JSON file.
+func Load(path string) (Scenario, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	s, err := Parse(payload)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %s", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes a YAML or JSON scenario, fills in defaults and validates it.
+func Parse(payload []byte) (Scenario, error) {
+	s := Scenario{}
+	err := yaml.UnmarshalStrict(payload, &s)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	s.applyDefaults()
+	return s, s.Validate()
+}
+
+func (s *Scenario) applyDefaults() {
+	for i := range s.Pools {
+		pool := &s.Pools[i]
+		if pool.Name == "" {
+			pool.Name = fmt.Sprintf("pool-%d", i+1)
+		}
+		if len(pool.Zones) == 0 {
+			pool.Zones = DefaultZones
+		}
+		if pool.Stack == "" {
+			pool.Stack = DefaultStack
+		}
+	}
+
+	for i := range s.Waves {
+		wave := &s.Waves[i]
+		if wave.Name == "" {
+			wave.Name = fmt.Sprintf("wave-%d", i+1)
+		}
+		for j := range wave.LRPs {
+			wave.LRPs[j].applyDefaults()
+		}
+		for j := range wave.Tasks {
+			wave.Tasks[j].applyDefaults()
+		}
+	}
+}
+
+func (c *Constraints) applyDefaults() {
+	if c.Stack == "" {
+		c.Stack = DefaultStack
+	}
+}
+
+func (s Scenario) Validate() error {
+	if len(s.Pools) == 0 {
+		return errors.New("no cell pools")
+	}
+	for _, pool := range s.Pools {
+		err := pool.validate()
+		if err != nil {
+			return fmt.Errorf("pool %s: %s", pool.Name, err)
+		}
+	}
+
+	if len(s.Waves) == 0 {
+		return errors.New("no waves")
+	}
+	for _, wave := range s.Waves {
+		err := wave.validate()
+		if err != nil {
+			return fmt.Errorf("wave %s: %s", wave.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p Pool) validate() error {
+	if p.Count <= 0 {
+		return errors.New("count must be positive")
+	}
+	if p.MemoryMB <= 0 || p.DiskMB <= 0 || p.Containers <= 0 {
+		return errors.New("memory_mb, disk_mb and containers must be positive")
+	}
+	for _, lrps := range p.LRPs {
+		if lrps.Count < 0 || lrps.MemoryMB < 0 || lrps.DiskMB < 0 {
+			return errors.New("pre-existing lrps must not be negative")
+		}
+	}
+	err := p.Faults.Validate()
+	if err != nil {
+		return fmt.Errorf("faults: %s", err)
+	}
+	if p.Lifecycle != nil {
+		err = p.Lifecycle.Validate()
+		if err != nil {
+			return fmt.Errorf("lifecycle: %s", err)
+		}
+	}
+	return nil
+}
+
+func (w Wave) validate() error {
+	for _, lrps := range w.LRPs {
+		if lrps.Processes < 0 || lrps.Instances < 0 {
+			return errors.New("processes and instances must not be negative")
+		}
+		if !lrps.Resources.valid() {
+			return errors.New("lrp resources must not be negative")
+		}
+	}
+	for _, tasks := range w.Tasks {
+		if tasks.Count < 0 {
+			return errors.New("task count must not be negative")
+		}
+		if !tasks.Resources.valid() {
+			return errors.New("task resources must not be negative")
+		}
+	}
+	if w.AuctionCount() == 0 {
+		return errors.New("no work")
+	}
+	return nil
+}
+
+func (r Resources) valid() bool {
+	return r.MemoryMB >= 0 && r.DiskMB >= 0 && r.MaxPids >= 0
+}
+
+// CellCount is the number of cells across every pool.
+func (s Scenario) CellCount() int {
+	count := 0
+	for _, pool := range s.Pools {
+		count += pool.Count
+	}
+	return count
+}
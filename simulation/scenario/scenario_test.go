@@ -0,0 +1,223 @@
+package scenario_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const yamlScenario = `
+pools:
+- name: small
+  count: 3
+  memory_mb: 100
+  disk_mb: 100
+  containers: 100
+  lrps:
+  - count: 2
+    memory_mb: 10
+    disk_mb: 5
+- name: isolated
+  count: 2
+  zones: [Z2]
+  stack: windows
+  stacks: [windows2016]
+  memory_mb: 200
+  disk_mb: 300
+  containers: 50
+  volume_drivers: [my-driver]
+  placement_tags: [segment]
+  optional_placement_tags: [ssd]
+  evacuating: true
+  faults:
+    perform_latency:
+      distribution: uniform
+      min: 10ms
+      max: 50ms
+    perform_error_rate: 0.1
+    rejection_rate: 0.2
+    flapping:
+      up: 1m
+      down: 10s
+  lifecycle:
+    start_delay:
+      mean: 5s
+    task_duration:
+      distribution: exponential
+      mean: 1m
+    crash_rate: 0.01
+    time_to_crash:
+      mean: 1h
+waves:
+- name: cold start
+  lrps:
+  - processes: 4
+    instances: 2
+    memory_mb: 1
+    disk_mb: 1
+  tasks:
+  - count: 3
+    memory_mb: 2
+    disk_mb: 2
+    stack: windows
+    placement_tags: [segment]
+    volume_drivers: [my-driver]
+- lrps:
+  - processes: 1
+    instances: 5
+    memory_mb: 1
+    disk_mb: 1
+`
+
+var _ = Describe("Scenario", func() {
+	Describe("Parse", func() {
+		It("decodes YAML and fills in defaults", func() {
+			s, err := scenario.Parse([]byte(yamlScenario))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Pools).To(HaveLen(2))
+			Expect(s.Pools[0].Name).To(Equal("small"))
+			Expect(s.Pools[0].Zones).To(Equal([]string{"Z0", "Z1"}))
+			Expect(s.Pools[0].Stack).To(Equal("linux"))
+			Expect(s.Pools[0].LRPs).To(Equal([]scenario.ExistingLRPs{{Count: 2, MemoryMB: 10, DiskMB: 5}}))
+			Expect(s.Pools[1].Zones).To(Equal([]string{"Z2"}))
+			Expect(s.Pools[1].Stack).To(Equal("windows"))
+			Expect(s.Pools[1].Stacks).To(Equal([]string{"windows2016"}))
+			Expect(s.Pools[1].VolumeDrivers).To(Equal([]string{"my-driver"}))
+			Expect(s.Pools[1].PlacementTags).To(Equal([]string{"segment"}))
+			Expect(s.Pools[1].OptionalPlacementTags).To(Equal([]string{"ssd"}))
+			Expect(s.Pools[1].Evacuating).To(BeTrue())
+			Expect(s.Pools[0].Evacuating).To(BeFalse())
+			Expect(s.Pools[0].Faults).To(Equal(simulationrep.Faults{}))
+			Expect(s.Pools[0].Lifecycle).To(BeNil())
+			Expect(s.Pools[1].Lifecycle).To(Equal(&simulationrep.Lifecycle{
+				StartDelay:   simulationrep.Latency{Mean: 5 * time.Second},
+				TaskDuration: simulationrep.Latency{Distribution: simulationrep.ExponentialDistribution, Mean: time.Minute},
+				CrashRate:    0.01,
+				TimeToCrash:  simulationrep.Latency{Mean: time.Hour},
+			}))
+			Expect(s.Pools[1].Faults).To(Equal(simulationrep.Faults{
+				PerformLatency: simulationrep.Latency{
+					Distribution: simulationrep.UniformDistribution,
+					Min:          10 * time.Millisecond,
+					Max:          50 * time.Millisecond,
+				},
+				PerformErrorRate: 0.1,
+				RejectionRate:    0.2,
+				Flapping:         &simulationrep.Flapping{Up: time.Minute, Down: 10 * time.Second},
+			}))
+			Expect(s.CellCount()).To(Equal(5))
+
+			Expect(s.Waves).To(HaveLen(2))
+			Expect(s.Waves[0].Name).To(Equal("cold start"))
+			Expect(s.Waves[0].LRPs[0].Stack).To(Equal("linux"))
+			Expect(s.Waves[0].Tasks[0].Resources).To(Equal(scenario.Resources{MemoryMB: 2, DiskMB: 2}))
+			Expect(s.Waves[0].Tasks[0].Constraints).To(Equal(scenario.Constraints{
+				Stack:         "windows",
+				PlacementTags: []string{"segment"},
+				VolumeDrivers: []string{"my-driver"},
+			}))
+			Expect(s.Waves[1].Name).To(Equal("wave-2"))
+		})
+
+		It("decodes JSON", func() {
+			s, err := scenario.Parse([]byte(`{
+				"pools": [{"count": 2, "memory_mb": 100, "disk_mb": 100, "containers": 10}],
+				"waves": [{"tasks": [{"count": 1, "memory_mb": 1, "disk_mb": 1}]}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Pools[0].Name).To(Equal("pool-1"))
+			Expect(s.Pools[0].Count).To(Equal(2))
+			Expect(s.Waves[0].Tasks[0].Count).To(Equal(1))
+		})
+
+		It("rejects unknown fields", func() {
+			_, err := scenario.Parse([]byte(`{"pools": [{"count": 1, "memroy_mb": 100}]}`))
+			Expect(err).To(MatchError(ContainSubstring("memroy_mb")))
+		})
+
+		Context("when the scenario is invalid", func() {
+			const pool = `{"count": 1, "memory_mb": 1, "disk_mb": 1, "containers": 1}`
+
+			expectInvalid := func(payload, message string) {
+				_, err := scenario.Parse([]byte(payload))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			}
+
+			It("requires pools", func() {
+				expectInvalid(`{"waves": [{"tasks": [{"count": 1}]}]}`, "no cell pools")
+			})
+
+			It("requires cells in every pool", func() {
+				expectInvalid(`{"pools": [{"memory_mb": 1, "disk_mb": 1, "containers": 1}]}`, "pool pool-1: count must be positive")
+			})
+
+			It("requires resources for every pool", func() {
+				expectInvalid(`{"pools": [{"count": 1, "memory_mb": 1}]}`, "pool pool-1: memory_mb, disk_mb and containers must be positive")
+			})
+
+			It("requires waves", func() {
+				expectInvalid(`{"pools": [`+pool+`]}`, "no waves")
+			})
+
+			It("requires work in every wave", func() {
+				expectInvalid(`{"pools": [`+pool+`], "waves": [{"name": "w"}]}`, "wave w: no work")
+			})
+
+			It("rejects negative resources", func() {
+				expectInvalid(`{"pools": [`+pool+`], "waves": [{"tasks": [{"count": 1, "memory_mb": -1}]}]}`, "task resources must not be negative")
+			})
+
+			It("rejects rates that are not fractions", func() {
+				expectInvalid(`{"pools": [{"count": 1, "memory_mb": 1, "disk_mb": 1, "containers": 1, "faults": {"rejection_rate": 2}}]}`, "pool pool-1: faults: rejection_rate must be between 0 and 1")
+			})
+
+			It("rejects crash rates that are not fractions", func() {
+				expectInvalid(`{"pools": [{"count": 1, "memory_mb": 1, "disk_mb": 1, "containers": 1, "lifecycle": {"crash_rate": -0.5}}]}`, "pool pool-1: lifecycle: crash_rate must be between 0 and 1")
+			})
+
+			It("rejects unknown latency distributions", func() {
+				expectInvalid(`{"pools": [{"count": 1, "memory_mb": 1, "disk_mb": 1, "containers": 1, "faults": {"state_latency": {"distribution": "pareto"}}}]}`, `faults: state_latency: unknown distribution "pareto"`)
+			})
+		})
+	})
+
+	Describe("Load", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "scenario")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("reads the scenario from a file", func() {
+			path := filepath.Join(tmpDir, "scenario.yml")
+			Expect(ioutil.WriteFile(path, []byte(yamlScenario), 0644)).To(Succeed())
+
+			s, err := scenario.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Pools).To(HaveLen(2))
+		})
+
+		It("names the file when it is invalid", func() {
+			path := filepath.Join(tmpDir, "scenario.yml")
+			Expect(ioutil.WriteFile(path, []byte("pools: []"), 0644)).To(Succeed())
+
+			_, err := scenario.Load(path)
+			Expect(err).To(MatchError(path + ": no cell pools"))
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package scenario
+
+import (
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+)
+
+// AuctionDomain is the domain of the work in a wave, which starts out
+// starting on the cell it is placed on.
+const AuctionDomain = simulationrep.AuctionDomain
+
+// InstanceCount is the number of LRP instances the wave starts.
+func (w Wave) InstanceCount() int {
+	count := 0
+	for _, lrps := range w.LRPs {
+		count += lrps.Processes * lrps.Instances
+	}
+	return count
+}
+
+// AuctionCount is the number of LRP instances and tasks the wave starts.
+func (w Wave) AuctionCount() int {
+	count := w.InstanceCount()
+	for _, tasks := range w.Tasks {
+		count += tasks.Count
+	}
+	return count
+}
+
+// Requests builds the auction requests for the wave, with fresh guids.
+func (w Wave) Requests() ([]auctioneer.LRPStartRequest, []auctioneer.TaskStartRequest) {
+	lrpStarts := []auctioneer.LRPStartRequest{}
+	for _, lrps := range w.LRPs {
+		if lrps.Instances == 0 {
+			continue
+		}
+		indices := make([]int, lrps.Instances)
+		for i := range indices {
+			indices[i] = i
+		}
+		for i := 0; i < lrps.Processes; i++ {
+			lrpStarts = append(lrpStarts, auctioneer.NewLRPStartRequest(
+				util.NewGrayscaleGuid("LRP"),
+				AuctionDomain,
+				indices,
+				lrps.Resources.resource(),
+				lrps.Constraints.placementConstraint(),
+			))
+		}
+	}
+
+	taskStarts := []auctioneer.TaskStartRequest{}
+	for _, tasks := range w.Tasks {
+		for i := 0; i < tasks.Count; i++ {
+			taskStarts = append(taskStarts, auctioneer.NewTaskStartRequest(rep.NewTask(
+				util.NewGuid("TASK"),
+				AuctionDomain,
+				tasks.Resources.resource(),
+				tasks.Constraints.placementConstraint(),
+			)))
+		}
+	}
+
+	return lrpStarts, taskStarts
+}
+
+func (r Resources) resource() rep.Resource {
+	return rep.NewResource(r.MemoryMB, r.DiskMB, r.MaxPids)
+}
+
+func (c Constraints) placementConstraint() rep.PlacementConstraint {
+	return rep.NewPlacementConstraint(models.PreloadedRootFS(c.Stack), nonNil(c.PlacementTags), nonNil(c.VolumeDrivers))
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
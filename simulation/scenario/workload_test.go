@@ -0,0 +1,48 @@
+package scenario_test
+
+import (
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Wave", func() {
+	var wave scenario.Wave
+
+	BeforeEach(func() {
+		s, err := scenario.Parse([]byte(yamlScenario))
+		Expect(err).NotTo(HaveOccurred())
+		wave = s.Waves[0]
+	})
+
+	It("counts the auctions it holds", func() {
+		Expect(wave.InstanceCount()).To(Equal(8))
+		Expect(wave.AuctionCount()).To(Equal(11))
+	})
+
+	It("builds a request per process and per task", func() {
+		lrpStarts, taskStarts := wave.Requests()
+
+		Expect(lrpStarts).To(HaveLen(4))
+		guids := map[string]struct{}{}
+		for _, start := range lrpStarts {
+			guids[start.ProcessGuid] = struct{}{}
+			Expect(start.Domain).To(Equal(scenario.AuctionDomain))
+			Expect(start.Indices).To(Equal([]int{0, 1}))
+			Expect(start.Resource).To(Equal(rep.NewResource(1, 1, 0)))
+			Expect(start.PlacementConstraint).To(Equal(rep.NewPlacementConstraint(models.PreloadedRootFS("linux"), []string{}, []string{})))
+		}
+		Expect(guids).To(HaveLen(4))
+
+		Expect(taskStarts).To(HaveLen(3))
+		for _, start := range taskStarts {
+			Expect(start.Domain).To(Equal(scenario.AuctionDomain))
+			Expect(start.Resource).To(Equal(rep.NewResource(2, 2, 0)))
+			Expect(start.PlacementConstraint).To(Equal(rep.NewPlacementConstraint(models.PreloadedRootFS("windows"), []string{"segment"}, []string{"my-driver"})))
+		}
+		Expect(taskStarts[0].TaskGuid).NotTo(Equal(taskStarts[1].TaskGuid))
+	})
+})
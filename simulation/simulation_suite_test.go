@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"sync"
 
 	"code.cloudfoundry.org/clock"
@@ -16,8 +17,10 @@ import (
 	"code.cloudfoundry.org/workpool"
 	"github.com/tedsuo/ifrit"
 
+	"code.cloudfoundry.org/auction/auctionfashion"
 	"code.cloudfoundry.org/auction/auctionrunner"
 	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/scenario"
 	"code.cloudfoundry.org/auction/simulation/simulationrep"
 	"code.cloudfoundry.org/auction/simulation/util"
 	"code.cloudfoundry.org/auction/simulation/visualization"
@@ -37,21 +40,63 @@ var communicationMode string
 const InProcess = "inprocess"
 const HTTP = "http"
 const linuxStack = "linux"
+const windowsStack = "windows"
 
+// numCells is the size of the standard pool, which holds the first cells of
+// the fleet and is what the experiments auction against.
 const numCells = 100
 
+const (
+	standardPool = "standard"
+	windowsPool  = "windows"
+	largePool    = "large"
+	isolatedPool = "isolated"
+	drainingPool = "draining"
+)
+
+const isolationTag = "isolated"
+const isolatedDriver = "isolated-driver"
+const ssdTag = "ssd"
+
 var cells map[string]rep.SimClient
 
-var repResources = rep.Resources{
-	MemoryMB:   100.0,
-	DiskMB:     100.0,
-	Containers: 100,
-}
+// simulationClients reach the simulation routes of the rep nodes in HTTP mode.
+var simulationClients map[string]*simulationrep.Client
 
-var defaultMaxContainerStartCount int = 0
+var defaultZones = []string{"Z0", "Z1"}
 
 var defaultDrivers = []string{"my-driver"}
 
+var fleetPools = []scenario.Pool{
+	{
+		Name: standardPool, Count: numCells, Zones: defaultZones, Stack: linuxStack,
+		MemoryMB: 100, DiskMB: 100, Containers: 100, VolumeDrivers: defaultDrivers,
+	},
+	{
+		Name: windowsPool, Count: 20, Zones: defaultZones, Stack: windowsStack,
+		MemoryMB: 100, DiskMB: 100, Containers: 100,
+	},
+	{
+		Name: largePool, Count: 10, Zones: defaultZones, Stack: linuxStack, Stacks: []string{windowsStack},
+		MemoryMB: 400, DiskMB: 400, Containers: 200, VolumeDrivers: defaultDrivers,
+		OptionalPlacementTags: []string{ssdTag},
+	},
+	{
+		Name: isolatedPool, Count: 20, Zones: defaultZones, Stack: linuxStack,
+		MemoryMB: 100, DiskMB: 100, Containers: 100,
+		VolumeDrivers: []string{defaultDrivers[0], isolatedDriver}, PlacementTags: []string{isolationTag},
+	},
+	{
+		Name: drainingPool, Count: 10, Zones: defaultZones, Stack: linuxStack,
+		MemoryMB: 100, DiskMB: 100, Containers: 100, VolumeDrivers: defaultDrivers,
+		Evacuating: true,
+	},
+}
+
+var fleet = scenario.Scenario{Pools: fleetPools}.Cells()
+
+var defaultMaxContainerStartCount int = 0
+
 var timeout time.Duration
 var workers int
 
@@ -63,6 +108,7 @@ var disableSVGReport bool
 var sessionsToTerminate []*gexec.Session
 var runnerProcess ifrit.Process
 var runnerDelegate *auctionRunnerDelegate
+var metricEmitterDelegate *auctionMetricEmitterDelegate
 var workPool *workpool.WorkPool
 var runner auctiontypes.AuctionRunner
 var logger lager.Logger
@@ -120,9 +166,9 @@ var _ = BeforeEach(func() {
 	util.ResetGuids()
 
 	runnerDelegate = NewAuctionRunnerDelegate(cells)
-	metricEmitterDelegate := NewAuctionMetricEmitterDelegate()
+	metricEmitterDelegate = NewAuctionMetricEmitterDelegate()
 
-	auctionType := auctionrunner.NewAuctionType(auctionrunner.DefaultAuction)
+	auctionType := auctionfashion.NewAuctionType(auctionfashion.DefaultAuction)
 
 	runner = auctionrunner.New(
 		logger,
@@ -154,21 +200,43 @@ var _ = AfterSuite(func() {
 })
 
 func cellGuid(index int) string {
-	return fmt.Sprintf("REP-%d", index+1)
+	return scenario.CellGuid(index)
 }
 
-func zone(index int) string {
-	return fmt.Sprintf("Z%d", index%2)
+// cellsInPools returns the guids of the fleet's cells in the given pools.
+func cellsInPools(pools ...string) []string {
+	guids := []string{}
+	for _, cell := range fleet {
+		for _, pool := range pools {
+			if cell.Pool == pool {
+				guids = append(guids, cell.Guid)
+			}
+		}
+	}
+	return guids
 }
 
-func buildInProcessReps() map[string]rep.SimClient {
-	cells := map[string]rep.SimClient{}
+// setEvacuating changes whether a cell reports itself as evacuating until it
+// is next reset.
+func setEvacuating(guid string, evacuating bool) {
+	if communicationMode == InProcess {
+		cells[guid].(*simulationrep.SimulationRep).SetEvacuating(evacuating)
+		return
+	}
+	Expect(simulationClients[guid].SetEvacuating(evacuating)).To(Succeed())
+}
 
-	for i := 0; i < numCells; i++ {
-		cells[cellGuid(i)] = simulationrep.New(linuxStack, zone(i), repResources, defaultDrivers)
+// setFaults changes how a cell misbehaves until it is next reset.
+func setFaults(guid string, faults simulationrep.Faults) {
+	if communicationMode == InProcess {
+		cells[guid].(*simulationrep.SimulationRep).SetFaults(faults)
+		return
 	}
+	Expect(simulationClients[guid].SetFaults(faults)).To(Succeed())
+}
 
-	return cells
+func buildInProcessReps() map[string]rep.SimClient {
+	return scenario.BuildReps(fleet)
 }
 
 func launchExternalHTTPReps() map[string]rep.SimClient {
@@ -176,27 +244,32 @@ func launchExternalHTTPReps() map[string]rep.SimClient {
 	Expect(err).NotTo(HaveOccurred())
 
 	cells := map[string]rep.SimClient{}
+	simulationClients = map[string]*simulationrep.Client{}
 
-	client := &http.Client{
+	httpClient := &http.Client{
 		Timeout: timeout,
 	}
 
-	factory, err := rep.NewClientFactory(client, client, nil)
+	factory, err := rep.NewClientFactory(httpClient, httpClient, nil)
 	Expect(err).NotTo(HaveOccurred())
 
-	for i := 0; i < numCells; i++ {
-		repGuid := cellGuid(i)
+	for i, cell := range fleet {
+		repGuid := cell.Guid
 		httpAddr := fmt.Sprintf("127.0.0.1:%d", 30000+i)
 
 		serverCmd := exec.Command(
 			repNodeBinary,
 			"-repGuid", repGuid,
 			"-httpAddr", httpAddr,
-			"-memoryMB", fmt.Sprintf("%d", repResources.MemoryMB),
-			"-diskMB", fmt.Sprintf("%d", repResources.DiskMB),
-			"-containers", fmt.Sprintf("%d", repResources.Containers),
-			"-stack", linuxStack,
-			"-zone", zone(i),
+			"-memoryMB", fmt.Sprintf("%d", cell.Resources.MemoryMB),
+			"-diskMB", fmt.Sprintf("%d", cell.Resources.DiskMB),
+			"-containers", fmt.Sprintf("%d", cell.Resources.Containers),
+			"-stack", strings.Join(cell.Stacks, ","),
+			"-zone", cell.Zone,
+			"-volumeDrivers", strings.Join(cell.VolumeDrivers, ","),
+			"-placementTags", strings.Join(cell.PlacementTags, ","),
+			"-optionalPlacementTags", strings.Join(cell.OptionalPlacementTags, ","),
+			fmt.Sprintf("-evacuating=%t", cell.Evacuating),
 		)
 
 		sess, err := gexec.Start(serverCmd, GinkgoWriter, GinkgoWriter)
@@ -206,7 +279,8 @@ func launchExternalHTTPReps() map[string]rep.SimClient {
 
 		client, err := factory.CreateClient("http://"+httpAddr, "")
 		Expect(err).NotTo(HaveOccurred())
-		cells[cellGuid(i)] = client.(rep.SimClient)
+		cells[repGuid] = client.(rep.SimClient)
+		simulationClients[repGuid] = simulationrep.NewClient(httpClient, "http://"+httpAddr)
 	}
 
 	return cells
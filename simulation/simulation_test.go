@@ -68,7 +68,7 @@ var _ = Describe("Auction", func() {
 
 	runStartAuction := func(lrpStartAuctions []auctioneer.LRPStartRequest, numCells int) {
 		runnerDelegate.SetCellLimit(numCells)
-		runner.ScheduleLRPsForAuctions(lrpStartAuctions)
+		Expect(runner.ScheduleLRPsForAuctions(lrpStartAuctions)).To(Succeed())
 
 		Eventually(runnerDelegate.ResultSize, time.Minute, 100*time.Millisecond).Should(Equal(len(lrpStartAuctions)))
 	}
@@ -84,6 +84,7 @@ var _ = Describe("Auction", func() {
 		report := visualization.NewReport(len(lrpStartAuctions), cells, runnerDelegate.Results(), duration)
 
 		visualization.PrintReport(report)
+		metricEmitterDelegate.PrintSummary()
 		svgReport.DrawReportCard(i, j, report)
 		reports = append(reports, report)
 		fmt.Println("Done...")
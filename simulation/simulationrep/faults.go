@@ -0,0 +1,244 @@
+package simulationrep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var ErrInjectedFault = errors.New("simulated rep fault")
+var ErrUnavailable = errors.New("simulated rep unavailable")
+
+// DefaultHang is how long a call that times out blocks when neither its
+// context nor the faults give it a deadline.
+const DefaultHang = time.Minute
+
+type Distribution string
+
+const (
+	FixedDistribution       Distribution = "fixed"
+	UniformDistribution     Distribution = "uniform"
+	NormalDistribution      Distribution = "normal"
+	ExponentialDistribution Distribution = "exponential"
+)
+
+// Latency is a distribution of call durations. A fixed latency is always
+// Mean, a uniform one lies between Min and Max, a normal one has Mean and
+// StdDev and an exponential one has Mean. Latencies are never negative.
+type Latency struct {
+	Distribution Distribution  `yaml:"distribution,omitempty"`
+	Min          time.Duration `yaml:"min,omitempty"`
+	Max          time.Duration `yaml:"max,omitempty"`
+	Mean         time.Duration `yaml:"mean,omitempty"`
+	StdDev       time.Duration `yaml:"std_dev,omitempty"`
+}
+
+// Sample draws a latency from the distribution.
+func (l Latency) Sample(random *rand.Rand) time.Duration {
+	var d float64
+	switch l.Distribution {
+	case UniformDistribution:
+		d = float64(l.Min) + random.Float64()*float64(l.Max-l.Min)
+	case NormalDistribution:
+		d = float64(l.Mean) + random.NormFloat64()*float64(l.StdDev)
+	case ExponentialDistribution:
+		d = random.ExpFloat64() * float64(l.Mean)
+	default:
+		d = float64(l.Mean)
+	}
+	return time.Duration(math.Max(d, 0))
+}
+
+// Flapping makes a rep alternate between being available for Up and
+// unavailable for Down, starting available when it is created or reset.
+type Flapping struct {
+	Up   time.Duration `yaml:"up,omitempty"`
+	Down time.Duration `yaml:"down,omitempty"`
+}
+
+// Faults describe how a SimulationRep misbehaves. Rates are fractions of
+// calls, or of the work in a Perform, between 0 and 1.
+type Faults struct {
+	StateLatency   Latency `yaml:"state_latency,omitempty"`
+	PerformLatency Latency `yaml:"perform_latency,omitempty"`
+
+	StateErrorRate   float64 `yaml:"state_error_rate,omitempty"`
+	PerformErrorRate float64 `yaml:"perform_error_rate,omitempty"`
+
+	// Calls that time out block until their context is done, or for Hang.
+	StateTimeoutRate   float64       `yaml:"state_timeout_rate,omitempty"`
+	PerformTimeoutRate float64       `yaml:"perform_timeout_rate,omitempty"`
+	Hang               time.Duration `yaml:"hang,omitempty"`
+
+	// RejectionRate is the fraction of LRPs and tasks a Perform that
+	// otherwise succeeds hands back as failed work.
+	RejectionRate float64 `yaml:"rejection_rate,omitempty"`
+
+	Flapping *Flapping `yaml:"flapping,omitempty"`
+}
+
+// Validate checks that the rates are fractions and the durations are not
+// negative.
+func (f Faults) Validate() error {
+	rates := []struct {
+		name string
+		rate float64
+	}{
+		{"state_error_rate", f.StateErrorRate},
+		{"perform_error_rate", f.PerformErrorRate},
+		{"state_timeout_rate", f.StateTimeoutRate},
+		{"perform_timeout_rate", f.PerformTimeoutRate},
+		{"rejection_rate", f.RejectionRate},
+	}
+	for _, r := range rates {
+		if r.rate < 0 || r.rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", r.name)
+		}
+	}
+
+	err := f.StateLatency.validate()
+	if err != nil {
+		return fmt.Errorf("state_latency: %s", err)
+	}
+	err = f.PerformLatency.validate()
+	if err != nil {
+		return fmt.Errorf("perform_latency: %s", err)
+	}
+
+	if f.Hang < 0 {
+		return errors.New("hang must not be negative")
+	}
+	if f.Flapping != nil && (f.Flapping.Up <= 0 || f.Flapping.Down < 0) {
+		return errors.New("flapping needs a positive up and a non-negative down")
+	}
+	return nil
+}
+
+func (l Latency) validate() error {
+	switch l.Distribution {
+	case "", FixedDistribution, UniformDistribution, NormalDistribution, ExponentialDistribution:
+	default:
+		return fmt.Errorf("unknown distribution %q", l.Distribution)
+	}
+	if l.Min < 0 || l.Max < l.Min || l.Mean < 0 || l.StdDev < 0 {
+		return errors.New("durations must not be negative and max must not be below min")
+	}
+	return nil
+}
+
+type fault int
+
+const (
+	noFault fault = iota
+	errorFault
+	timeoutFault
+	unavailableFault
+)
+
+// plan is decided under the rep's lock and carried out without it.
+type plan struct {
+	latency time.Duration
+	fault   fault
+	hang    time.Duration
+}
+
+// planCall decides the latency and fault of a call. The caller holds the lock.
+func (r *SimulationRep) planCall(latency Latency, errorRate, timeoutRate float64) plan {
+	p := plan{latency: latency.Sample(r.random)}
+
+	switch {
+	case r.unavailable():
+		p.fault = unavailableFault
+	case r.random.Float64() < timeoutRate:
+		p.fault = timeoutFault
+		p.hang = r.faults.Hang
+		if p.hang <= 0 {
+			p.hang = DefaultHang
+		}
+	case r.random.Float64() < errorRate:
+		p.fault = errorFault
+	}
+
+	return p
+}
+
+// within cuts a call that would take longer than timeout short, as an HTTP
+// client with that timeout would. A zero timeout never cuts a call short.
+func (p plan) within(timeout time.Duration) plan {
+	if timeout <= 0 {
+		return p
+	}
+	if p.latency >= timeout {
+		return plan{fault: timeoutFault, hang: timeout}
+	}
+	if p.fault == timeoutFault && p.latency+p.hang > timeout {
+		p.hang = timeout - p.latency
+	}
+	return p
+}
+
+// unavailable reports whether a flapping rep is down. The caller holds the lock.
+func (r *SimulationRep) unavailable() bool {
+	flapping := r.faults.Flapping
+	if flapping == nil || flapping.Down <= 0 {
+		return false
+	}
+
+	period := flapping.Up + flapping.Down
+	return r.clock.Since(r.flappingSince)%period >= flapping.Up
+}
+
+// wait waits out the planned latency and returns the planned error, if the
+// call fails straight away.
+func (r *SimulationRep) wait(ctx context.Context, p plan) error {
+	err := r.sleep(ctx, p.latency)
+	if err != nil {
+		return err
+	}
+
+	switch p.fault {
+	case unavailableFault:
+		return ErrUnavailable
+	case errorFault:
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+// hang blocks a call that was planned to time out.
+func (r *SimulationRep) hang(ctx context.Context, p plan) error {
+	if p.fault != timeoutFault {
+		return nil
+	}
+
+	err := r.sleep(ctx, p.hang)
+	if err != nil {
+		return err
+	}
+	return context.DeadlineExceeded
+}
+
+// rejected decides whether a Perform hands back a piece of work. The caller
+// holds the lock.
+func (r *SimulationRep) rejected() bool {
+	return r.faults.RejectionRate > 0 && r.random.Float64() < r.faults.RejectionRate
+}
+
+func (r *SimulationRep) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := r.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
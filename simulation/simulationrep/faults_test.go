@@ -0,0 +1,264 @@
+package simulationrep_test
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Faults", func() {
+	var (
+		clock   *fakeclock.FakeClock
+		logger  *lagertest.TestLogger
+		simRep  *simulationrep.SimulationRep
+		options []simulationrep.Option
+		work    rep.Work
+	)
+
+	newLRP := func(guid string) rep.LRP {
+		return rep.NewLRP(
+			models.NewActualLRPKey(guid, 0, "auction"),
+			rep.NewResource(10, 10, 10),
+			rep.NewPlacementConstraint(models.PreloadedRootFS("linux"), nil, nil),
+		)
+	}
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("simulationrep")
+		options = []simulationrep.Option{simulationrep.WithClock(clock), simulationrep.WithSeed(42)}
+		work = rep.Work{LRPs: []rep.LRP{newLRP("pg-1"), newLRP("pg-2")}}
+	})
+
+	JustBeforeEach(func() {
+		simRep = simulationrep.New("linux", "Z0", rep.NewResources(100, 100, 100), nil, options...).(*simulationrep.SimulationRep)
+	})
+
+	stateLRPs := func() int {
+		state, err := simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		return len(state.LRPs)
+	}
+
+	It("answers straight away without faults", func() {
+		_, err := simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		failed, err := simRep.Perform(logger, work)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed).To(Equal(rep.Work{}))
+		Expect(stateLRPs()).To(Equal(2))
+	})
+
+	Context("with latency", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{
+				StateLatency: simulationrep.Latency{Distribution: simulationrep.FixedDistribution, Mean: time.Second},
+			}))
+		})
+
+		It("answers once the latency has passed", func() {
+			errs := make(chan error, 1)
+			go func() {
+				_, err := simRep.State(logger)
+				errs <- err
+			}()
+
+			clock.WaitForWatcherAndIncrement(999 * time.Millisecond)
+			Consistently(errs).ShouldNot(Receive())
+
+			clock.Increment(time.Millisecond)
+			Eventually(errs).Should(Receive(BeNil()))
+		})
+
+		It("gives up when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errs := make(chan error, 1)
+			go func() {
+				_, err := simRep.StateContext(ctx, logger)
+				errs <- err
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			cancel()
+			Eventually(errs).Should(Receive(Equal(context.Canceled)))
+		})
+	})
+
+	Context("with errors", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{
+				StateErrorRate:   1,
+				PerformErrorRate: 1,
+			}))
+		})
+
+		It("fails every call without placing work", func() {
+			_, err := simRep.State(logger)
+			Expect(err).To(Equal(simulationrep.ErrInjectedFault))
+
+			_, err = simRep.Perform(logger, work)
+			Expect(err).To(Equal(simulationrep.ErrInjectedFault))
+
+			simRep.SetFaults(simulationrep.Faults{})
+			Expect(stateLRPs()).To(BeZero())
+		})
+	})
+
+	Context("with timeouts", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{
+				PerformTimeoutRate: 1,
+				Hang:               time.Minute,
+			}))
+		})
+
+		It("places the work and hangs until the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errs := make(chan error, 1)
+			go func() {
+				_, err := simRep.PerformContext(ctx, logger, work)
+				errs <- err
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			Expect(stateLRPs()).To(Equal(2))
+
+			cancel()
+			Eventually(errs).Should(Receive(Equal(context.Canceled)))
+		})
+
+		It("hangs for the configured time without a context", func() {
+			errs := make(chan error, 1)
+			go func() {
+				_, err := simRep.Perform(logger, work)
+				errs <- err
+			}()
+
+			clock.WaitForWatcherAndIncrement(time.Minute)
+			Eventually(errs).Should(Receive(Equal(context.DeadlineExceeded)))
+		})
+	})
+
+	Context("with partial rejection", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{RejectionRate: 0.5}))
+			work = rep.Work{}
+			for i := 0; i < 10; i++ {
+				work.LRPs = append(work.LRPs, newLRP(string(rune('a'+i))))
+			}
+		})
+
+		It("hands back some of the work and places the rest", func() {
+			failed, err := simRep.Perform(logger, work)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(len(failed.LRPs)).To(BeNumerically(">", 0))
+			Expect(len(failed.LRPs)).To(BeNumerically("<", 10))
+			Expect(stateLRPs()).To(Equal(10 - len(failed.LRPs)))
+		})
+	})
+
+	Context("when flapping", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{
+				Flapping: &simulationrep.Flapping{Up: 10 * time.Second, Down: 5 * time.Second},
+			}))
+		})
+
+		It("alternates between being available and unavailable", func() {
+			_, err := simRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.Increment(10 * time.Second)
+			_, err = simRep.State(logger)
+			Expect(err).To(Equal(simulationrep.ErrUnavailable))
+			_, err = simRep.Perform(logger, work)
+			Expect(err).To(Equal(simulationrep.ErrUnavailable))
+
+			clock.Increment(5 * time.Second)
+			_, err = simRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("changing faults at runtime", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithFaults(simulationrep.Faults{StateErrorRate: 1}))
+		})
+
+		It("applies them until the rep is reset", func() {
+			simRep.SetFaults(simulationrep.Faults{})
+			Expect(simRep.Faults()).To(Equal(simulationrep.Faults{}))
+			_, err := simRep.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(simRep.Reset()).To(Succeed())
+			Expect(simRep.Faults()).To(Equal(simulationrep.Faults{StateErrorRate: 1}))
+			_, err = simRep.State(logger)
+			Expect(err).To(Equal(simulationrep.ErrInjectedFault))
+		})
+	})
+})
+
+var _ = Describe("Latency", func() {
+	var random *rand.Rand
+
+	BeforeEach(func() {
+		random = rand.New(rand.NewSource(42))
+	})
+
+	samples := func(latency simulationrep.Latency) []time.Duration {
+		durations := []time.Duration{}
+		for i := 0; i < 1000; i++ {
+			durations = append(durations, latency.Sample(random))
+		}
+		return durations
+	}
+
+	mean := func(durations []time.Duration) time.Duration {
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		return total / time.Duration(len(durations))
+	}
+
+	It("is always the mean when fixed", func() {
+		for _, d := range samples(simulationrep.Latency{Mean: time.Second}) {
+			Expect(d).To(Equal(time.Second))
+		}
+	})
+
+	It("lies between the bounds when uniform", func() {
+		durations := samples(simulationrep.Latency{Distribution: simulationrep.UniformDistribution, Min: time.Second, Max: 2 * time.Second})
+		for _, d := range durations {
+			Expect(d).To(BeNumerically(">=", time.Second))
+			Expect(d).To(BeNumerically("<=", 2*time.Second))
+		}
+		Expect(mean(durations)).To(BeNumerically("~", 1500*time.Millisecond, 50*time.Millisecond))
+	})
+
+	It("is centred on the mean and never negative when normal", func() {
+		durations := samples(simulationrep.Latency{Distribution: simulationrep.NormalDistribution, Mean: 100 * time.Millisecond, StdDev: 100 * time.Millisecond})
+		for _, d := range durations {
+			Expect(d).To(BeNumerically(">=", 0))
+		}
+		Expect(durations).To(ContainElement(time.Duration(0)))
+		Expect(mean(durations)).To(BeNumerically(">", 100*time.Millisecond))
+	})
+
+	It("has the mean when exponential", func() {
+		durations := samples(simulationrep.Latency{Distribution: simulationrep.ExponentialDistribution, Mean: time.Second})
+		Expect(mean(durations)).To(BeNumerically("~", time.Second, 100*time.Millisecond))
+	})
+})
@@ -0,0 +1,132 @@
+package simulationrep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/rata"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	SetEvacuatingRoute = "SetEvacuating"
+	SetFaultsRoute     = "SetFaults"
+)
+
+// Routes let a simulation change a rep node while it runs. They are served
+// alongside the rep's own routes.
+var Routes = rata.Routes{
+	{Path: "/sim/evacuating", Method: "PUT", Name: SetEvacuatingRoute},
+	{Path: "/sim/faults", Method: "PUT", Name: SetFaultsRoute},
+}
+
+func NewHandlers(simulationRep *SimulationRep, logger lager.Logger) rata.Handlers {
+	return rata.Handlers{
+		SetEvacuatingRoute: &setEvacuatingHandler{simulationRep: simulationRep, logger: logger},
+		SetFaultsRoute:     &setFaultsHandler{simulationRep: simulationRep, logger: logger},
+	}
+}
+
+type setEvacuatingHandler struct {
+	simulationRep *SimulationRep
+	logger        lager.Logger
+}
+
+func (h *setEvacuatingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("set-evacuating")
+
+	var evacuating bool
+	err := json.NewDecoder(r.Body).Decode(&evacuating)
+	if err != nil {
+		logger.Error("failed-to-decode", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	h.simulationRep.SetEvacuating(evacuating)
+	logger.Info("set", lager.Data{"evacuating": evacuating})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type setFaultsHandler struct {
+	simulationRep *SimulationRep
+	logger        lager.Logger
+}
+
+// ServeHTTP takes the faults as YAML or JSON, like repnode's -faults flag.
+func (h *setFaultsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("set-faults")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("failed-to-read", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	faults := Faults{}
+	err = yaml.UnmarshalStrict(body, &faults)
+	if err == nil {
+		err = faults.Validate()
+	}
+	if err != nil {
+		logger.Error("invalid-faults", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	h.simulationRep.SetFaults(faults)
+	logger.Info("set")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Client changes a rep node through the simulation routes.
+type Client struct {
+	httpClient       *http.Client
+	requestGenerator *rata.RequestGenerator
+}
+
+func NewClient(httpClient *http.Client, address string) *Client {
+	return &Client{
+		httpClient:       httpClient,
+		requestGenerator: rata.NewRequestGenerator(address, Routes),
+	}
+}
+
+func (c *Client) SetEvacuating(evacuating bool) error {
+	body, err := json.Marshal(evacuating)
+	if err != nil {
+		return err
+	}
+	return c.put(SetEvacuatingRoute, body)
+}
+
+func (c *Client) SetFaults(faults Faults) error {
+	body, err := yaml.Marshal(faults)
+	if err != nil {
+		return err
+	}
+	return c.put(SetFaultsRoute, body)
+}
+
+func (c *Client) put(route string, body []byte) error {
+	req, err := c.requestGenerator.CreateRequest(route, nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package simulationrep_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulation routes", func() {
+	var (
+		logger *lagertest.TestLogger
+		simRep *simulationrep.SimulationRep
+		server *httptest.Server
+		client *simulationrep.Client
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("simulationrep")
+		simRep = simulationrep.New("linux", "Z0", rep.NewResources(100, 100, 100), nil).(*simulationrep.SimulationRep)
+
+		router, err := rata.NewRouter(simulationrep.Routes, simulationrep.NewHandlers(simRep, logger))
+		Expect(err).NotTo(HaveOccurred())
+		server = httptest.NewServer(router)
+		client = simulationrep.NewClient(http.DefaultClient, server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("starts and stops the rep evacuating", func() {
+		Expect(client.SetEvacuating(true)).To(Succeed())
+		state, err := simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Evacuating).To(BeTrue())
+
+		Expect(client.SetEvacuating(false)).To(Succeed())
+		state, err = simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Evacuating).To(BeFalse())
+	})
+
+	It("changes the rep's faults", func() {
+		faults := simulationrep.Faults{
+			StateLatency:     simulationrep.Latency{Mean: 10 * time.Millisecond},
+			PerformErrorRate: 0.5,
+			Flapping:         &simulationrep.Flapping{Up: time.Second, Down: time.Second},
+		}
+		Expect(client.SetFaults(faults)).To(Succeed())
+		Expect(simRep.Faults()).To(Equal(faults))
+
+		Expect(client.SetFaults(simulationrep.Faults{})).To(Succeed())
+		Expect(simRep.Faults()).To(Equal(simulationrep.Faults{}))
+	})
+
+	It("rejects invalid faults", func() {
+		Expect(client.SetFaults(simulationrep.Faults{StateErrorRate: 2})).NotTo(Succeed())
+		Expect(simRep.Faults()).To(Equal(simulationrep.Faults{}))
+	})
+
+	It("rejects a malformed request", func() {
+		resp, err := http.DefaultClient.Do(newPut(server.URL+"/sim/evacuating", "maybe"))
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
+
+func newPut(url, body string) *http.Request {
+	req, err := http.NewRequest("PUT", url, strings.NewReader(body))
+	Expect(err).NotTo(HaveOccurred())
+	return req
+}
@@ -0,0 +1,134 @@
+package simulationrep
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AuctionDomain is the domain of work that starts out starting. Work in any
+// other domain, such as the LRPs a simulation seeds its cells with, is placed
+// already running.
+const AuctionDomain = "auction"
+
+// Lifecycle describes how the containers of a rep evolve over time. Without
+// one, containers placed by the auction start forever, tasks never complete
+// and LRPs never crash.
+type Lifecycle struct {
+	// StartDelay is how long a container starts for before it runs.
+	StartDelay Latency `yaml:"start_delay,omitempty"`
+
+	// TaskDuration is how long a task runs before it completes and frees its
+	// resources. Tasks run forever when it is zero.
+	TaskDuration Latency `yaml:"task_duration,omitempty"`
+
+	// CrashRate is the fraction of LRPs that crash, which frees their
+	// resources, once they have run for TimeToCrash.
+	CrashRate   float64 `yaml:"crash_rate,omitempty"`
+	TimeToCrash Latency `yaml:"time_to_crash,omitempty"`
+}
+
+func (l Lifecycle) Validate() error {
+	if l.CrashRate < 0 || l.CrashRate > 1 {
+		return errors.New("crash_rate must be between 0 and 1")
+	}
+
+	err := l.StartDelay.validate()
+	if err != nil {
+		return fmt.Errorf("start_delay: %s", err)
+	}
+	err = l.TaskDuration.validate()
+	if err != nil {
+		return fmt.Errorf("task_duration: %s", err)
+	}
+	err = l.TimeToCrash.validate()
+	if err != nil {
+		return fmt.Errorf("time_to_crash: %s", err)
+	}
+	return nil
+}
+
+// timeline is when a container stops starting and when it goes away. A zero
+// time never comes.
+type timeline struct {
+	running time.Time
+	stopped time.Time
+}
+
+func (t timeline) starting(now time.Time) bool {
+	return t.running.IsZero() || now.Before(t.running)
+}
+
+func (t timeline) gone(now time.Time) bool {
+	return !t.stopped.IsZero() && !now.Before(t.stopped)
+}
+
+// lrpTimeline decides the lifecycle of an LRP placed now. The caller holds
+// the lock.
+func (r *SimulationRep) lrpTimeline(domain string) timeline {
+	t := r.startTimeline(domain)
+	if r.lifecycle != nil && !t.running.IsZero() && r.random.Float64() < r.lifecycle.CrashRate {
+		t.stopped = t.running.Add(r.lifecycle.TimeToCrash.Sample(r.random))
+	}
+	return t
+}
+
+// taskTimeline decides the lifecycle of a task placed now. The caller holds
+// the lock.
+func (r *SimulationRep) taskTimeline(domain string) timeline {
+	t := r.startTimeline(domain)
+	if r.lifecycle != nil && !t.running.IsZero() && r.lifecycle.TaskDuration != (Latency{}) {
+		t.stopped = t.running.Add(r.lifecycle.TaskDuration.Sample(r.random))
+	}
+	return t
+}
+
+func (r *SimulationRep) startTimeline(domain string) timeline {
+	now := r.clock.Now()
+	switch {
+	case domain != AuctionDomain:
+		return timeline{running: now}
+	case r.lifecycle != nil:
+		return timeline{running: now.Add(r.lifecycle.StartDelay.Sample(r.random))}
+	default:
+		return timeline{}
+	}
+}
+
+// advance removes the tasks that have completed and the LRPs that have
+// crashed. The caller holds the lock.
+func (r *SimulationRep) advance() {
+	now := r.clock.Now()
+	for identifier, t := range r.lrpTimelines {
+		if t.gone(now) {
+			delete(r.lrps, identifier)
+			delete(r.lrpTimelines, identifier)
+			r.crashedLRPs++
+		}
+	}
+	for guid, t := range r.taskTimelines {
+		if t.gone(now) {
+			delete(r.tasks, guid)
+			delete(r.taskTimelines, guid)
+			r.completedTasks++
+		}
+	}
+}
+
+// startingContainerCount counts the containers that are not yet running. The
+// caller holds the lock.
+func (r *SimulationRep) startingContainerCount() int {
+	now := r.clock.Now()
+	count := 0
+	for _, t := range r.lrpTimelines {
+		if t.starting(now) {
+			count++
+		}
+	}
+	for _, t := range r.taskTimelines {
+		if t.starting(now) {
+			count++
+		}
+	}
+	return count
+}
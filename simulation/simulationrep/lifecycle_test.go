@@ -0,0 +1,169 @@
+package simulationrep_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lifecycle", func() {
+	var (
+		clock   *fakeclock.FakeClock
+		logger  *lagertest.TestLogger
+		simRep  *simulationrep.SimulationRep
+		options []simulationrep.Option
+	)
+
+	constraint := rep.NewPlacementConstraint(models.PreloadedRootFS("linux"), nil, nil)
+
+	newLRP := func(guid, domain string) rep.LRP {
+		return rep.NewLRP(models.NewActualLRPKey(guid, 0, domain), rep.NewResource(10, 10, 10), constraint)
+	}
+
+	newTask := func(guid string) rep.Task {
+		return rep.NewTask(guid, simulationrep.AuctionDomain, rep.NewResource(10, 10, 10), constraint)
+	}
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("simulationrep")
+		options = []simulationrep.Option{simulationrep.WithClock(clock), simulationrep.WithSeed(42)}
+	})
+
+	JustBeforeEach(func() {
+		simRep = simulationrep.New("linux", "Z0", rep.NewResources(100, 100, 100), nil, options...).(*simulationrep.SimulationRep)
+	})
+
+	state := func() rep.CellState {
+		state, err := simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		return state
+	}
+
+	perform := func(work rep.Work) {
+		failed, err := simRep.Perform(logger, work)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed).To(Equal(rep.Work{}))
+	}
+
+	Context("without a lifecycle", func() {
+		It("keeps auctioned containers starting and everything placed forever", func() {
+			perform(rep.Work{
+				LRPs:  []rep.LRP{newLRP("pg-1", simulationrep.AuctionDomain), newLRP("pg-2", "preloaded")},
+				Tasks: []rep.Task{newTask("tg-1")},
+			})
+
+			clock.Increment(time.Hour)
+			Expect(state().StartingContainerCount).To(Equal(2))
+			Expect(state().LRPs).To(HaveLen(2))
+			Expect(state().Tasks).To(HaveLen(1))
+		})
+	})
+
+	Context("with a start delay", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithLifecycle(simulationrep.Lifecycle{
+				StartDelay: simulationrep.Latency{Mean: 10 * time.Second},
+			}))
+		})
+
+		It("runs auctioned containers once they have started", func() {
+			perform(rep.Work{
+				LRPs:  []rep.LRP{newLRP("pg-1", simulationrep.AuctionDomain), newLRP("pg-2", "preloaded")},
+				Tasks: []rep.Task{newTask("tg-1")},
+			})
+			Expect(state().StartingContainerCount).To(Equal(2))
+
+			clock.Increment(9 * time.Second)
+			perform(rep.Work{LRPs: []rep.LRP{newLRP("pg-3", simulationrep.AuctionDomain)}})
+			Expect(state().StartingContainerCount).To(Equal(3))
+
+			clock.Increment(time.Second)
+			Expect(state().StartingContainerCount).To(Equal(1))
+
+			clock.Increment(9 * time.Second)
+			Expect(state().StartingContainerCount).To(BeZero())
+			Expect(state().LRPs).To(HaveLen(3))
+			Expect(state().Tasks).To(HaveLen(1))
+		})
+	})
+
+	Context("with a task duration", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithLifecycle(simulationrep.Lifecycle{
+				StartDelay:   simulationrep.Latency{Mean: time.Second},
+				TaskDuration: simulationrep.Latency{Mean: time.Minute},
+			}))
+		})
+
+		It("completes tasks once they have run for it, freeing their resources", func() {
+			perform(rep.Work{Tasks: []rep.Task{newTask("tg-1"), newTask("tg-2")}})
+			Expect(state().AvailableResources).To(Equal(rep.NewResources(80, 80, 98)))
+
+			clock.Increment(time.Minute)
+			Expect(state().Tasks).To(HaveLen(2))
+
+			clock.Increment(time.Second)
+			Expect(state().Tasks).To(BeEmpty())
+			Expect(state().AvailableResources).To(Equal(rep.NewResources(100, 100, 100)))
+			Expect(simRep.CompletedTasks()).To(Equal(2))
+		})
+	})
+
+	Context("with crashes", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithLifecycle(simulationrep.Lifecycle{
+				CrashRate:   0.5,
+				TimeToCrash: simulationrep.Latency{Distribution: simulationrep.UniformDistribution, Min: time.Minute, Max: 2 * time.Minute},
+			}))
+		})
+
+		It("removes the LRPs that crash once they have run long enough", func() {
+			work := rep.Work{}
+			for i := 0; i < 100; i++ {
+				work.LRPs = append(work.LRPs, rep.NewLRP(models.NewActualLRPKey("pg", int32(i), simulationrep.AuctionDomain), rep.NewResource(1, 1, 1), constraint))
+			}
+			perform(work)
+
+			clock.Increment(time.Minute - time.Second)
+			Expect(state().LRPs).To(HaveLen(100))
+
+			clock.Increment(time.Minute + time.Second)
+			remaining := len(state().LRPs)
+			Expect(remaining).To(BeNumerically("~", 50, 15))
+			Expect(simRep.CrashedLRPs()).To(Equal(100 - remaining))
+			Expect(state().AvailableResources.MemoryMB).To(Equal(int32(100 - remaining)))
+
+			clock.Increment(time.Hour)
+			Expect(state().LRPs).To(HaveLen(remaining))
+		})
+	})
+
+	Describe("Reset", func() {
+		BeforeEach(func() {
+			options = append(options, simulationrep.WithLifecycle(simulationrep.Lifecycle{
+				TaskDuration: simulationrep.Latency{Mean: time.Second},
+				CrashRate:    1,
+				TimeToCrash:  simulationrep.Latency{Mean: time.Second},
+			}))
+		})
+
+		It("forgets completed tasks and crashed LRPs", func() {
+			perform(rep.Work{LRPs: []rep.LRP{newLRP("pg-1", simulationrep.AuctionDomain)}, Tasks: []rep.Task{newTask("tg-1")}})
+			clock.Increment(time.Second)
+			Expect(simRep.CompletedTasks()).To(Equal(1))
+			Expect(simRep.CrashedLRPs()).To(Equal(1))
+
+			Expect(simRep.Reset()).To(Succeed())
+			Expect(simRep.CompletedTasks()).To(BeZero())
+			Expect(simRep.CrashedLRPs()).To(BeZero())
+		})
+	})
+})
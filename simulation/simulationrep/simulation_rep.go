@@ -1,45 +1,168 @@
 package simulationrep
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 )
 
+var ErrContainerNotFound = errors.New("simulated container not found")
+
 type SimulationRep struct {
-	stack                  string
-	zone                   string
-	totalResources         rep.Resources
-	lrps                   map[string]rep.LRP
-	tasks                  map[string]rep.Task
-	startingContainerCount int
-	volumeDrivers          []string
+	rootFSProviders       rep.RootFSProviders
+	zone                  string
+	totalResources        rep.Resources
+	lrps                  map[string]rep.LRP
+	tasks                 map[string]rep.Task
+	volumeDrivers         []string
+	placementTags         []string
+	optionalPlacementTags []string
+	evacuatingOnReset     bool
+	evacuating            bool
+
+	clock         clock.Clock
+	random        *rand.Rand
+	faultsOnReset Faults
+	faults        Faults
+	flappingSince time.Time
+
+	lifecycle      *Lifecycle
+	lrpTimelines   map[string]timeline
+	taskTimelines  map[string]timeline
+	completedTasks int
+	crashedLRPs    int
+
+	stateClient *http.Client
 
 	lock *sync.Mutex
 }
 
-func New(stack string, zone string, totalResources rep.Resources, volumeDrivers []string) rep.SimClient {
-	return &SimulationRep{
-		stack:          stack,
+// Option configures a SimulationRep beyond its stack, zone, resources and
+// volume drivers.
+type Option func(*SimulationRep)
+
+// WithRootFSProviders replaces the preloaded rootfs of the rep's stack with
+// the given providers.
+func WithRootFSProviders(providers rep.RootFSProviders) Option {
+	return func(r *SimulationRep) {
+		r.rootFSProviders = providers
+	}
+}
+
+// WithPlacementTags makes the rep require the given placement tags.
+func WithPlacementTags(tags ...string) Option {
+	return func(r *SimulationRep) {
+		r.placementTags = tags
+	}
+}
+
+// WithOptionalPlacementTags lets the rep accept work with the given
+// placement tags as well as work without them.
+func WithOptionalPlacementTags(tags ...string) Option {
+	return func(r *SimulationRep) {
+		r.optionalPlacementTags = tags
+	}
+}
+
+// WithEvacuating starts the rep, and resets it, evacuating.
+func WithEvacuating(evacuating bool) Option {
+	return func(r *SimulationRep) {
+		r.evacuatingOnReset = evacuating
+		r.evacuating = evacuating
+	}
+}
+
+// WithClock replaces the real clock the rep times its faults and the
+// lifecycle of its containers with.
+func WithClock(clock clock.Clock) Option {
+	return func(r *SimulationRep) {
+		r.clock = clock
+	}
+}
+
+// WithSeed makes the rep's faults and lifecycle repeatable.
+func WithSeed(seed int64) Option {
+	return func(r *SimulationRep) {
+		r.random = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithFaults starts the rep, and resets it, with the given faults.
+func WithFaults(faults Faults) Option {
+	return func(r *SimulationRep) {
+		r.faultsOnReset = faults
+		r.faults = faults
+	}
+}
+
+// WithLifecycle makes the rep's containers finish starting, complete and
+// crash over time.
+func WithLifecycle(lifecycle Lifecycle) Option {
+	return func(r *SimulationRep) {
+		r.lifecycle = &lifecycle
+	}
+}
+
+func New(stack string, zone string, totalResources rep.Resources, volumeDrivers []string, options ...Option) rep.SimClient {
+	r := &SimulationRep{
+		rootFSProviders: rep.RootFSProviders{
+			models.PreloadedRootFSScheme: rep.NewFixedSetRootFSProvider(stack),
+		},
 		totalResources: totalResources,
 		lrps:           map[string]rep.LRP{},
 		tasks:          map[string]rep.Task{},
-		startingContainerCount: 0,
-		zone:          zone,
-		volumeDrivers: volumeDrivers,
+		zone:           zone,
+		volumeDrivers:  volumeDrivers,
+
+		clock:  clock.NewClock(),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		lrpTimelines:  map[string]timeline{},
+		taskTimelines: map[string]timeline{},
 
 		lock: &sync.Mutex{},
 	}
+
+	for _, option := range options {
+		option(r)
+	}
+	r.flappingSince = r.clock.Now()
+
+	return r
+}
+
+func (r *SimulationRep) State(logger lager.Logger) (rep.CellState, error) {
+	return r.StateContext(context.Background(), logger)
 }
 
-func (r *SimulationRep) State(_ lager.Logger) (rep.CellState, error) {
+func (r *SimulationRep) StateContext(ctx context.Context, _ lager.Logger) (rep.CellState, error) {
+	r.lock.Lock()
+	p := r.planCall(r.faults.StateLatency, r.faults.StateErrorRate, r.faults.StateTimeoutRate)
+	p = p.within(r.stateClientTimeout())
+	r.lock.Unlock()
+
+	err := r.wait(ctx, p)
+	if err == nil {
+		err = r.hang(ctx, p)
+	}
+	if err != nil {
+		return rep.CellState{}, err
+	}
+
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	r.advance()
+
 	lrps := []rep.LRP{}
 	for _, lrp := range r.lrps {
 		lrps = append(lrps, lrp)
@@ -52,42 +175,72 @@ func (r *SimulationRep) State(_ lager.Logger) (rep.CellState, error) {
 
 	availableResources := r.availableResources()
 
-	// util.RandomSleep(800, 900)
-
 	return rep.CellState{
-		RootFSProviders: rep.RootFSProviders{
-			models.PreloadedRootFSScheme: rep.NewFixedSetRootFSProvider(r.stack),
-		},
-		AvailableResources: availableResources,
-		TotalResources:     r.totalResources,
-		LRPs:               lrps,
-		Tasks:              tasks,
-		StartingContainerCount: r.startingContainerCount,
-		Zone:          r.zone,
-		VolumeDrivers: r.volumeDrivers,
+		RootFSProviders:        r.rootFSProviders,
+		AvailableResources:     availableResources,
+		TotalResources:         r.totalResources,
+		LRPs:                   lrps,
+		Tasks:                  tasks,
+		StartingContainerCount: r.startingContainerCount(),
+		Zone:                   r.zone,
+		Evacuating:             r.evacuating,
+		VolumeDrivers:          r.volumeDrivers,
+		PlacementTags:          r.placementTags,
+		OptionalPlacementTags:  r.optionalPlacementTags,
 	}, nil
 }
 
-func (r *SimulationRep) Perform(_ lager.Logger, work rep.Work) (rep.Work, error) {
+func (r *SimulationRep) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+	return r.PerformContext(context.Background(), logger, work)
+}
+
+// PerformContext places the work. A call that times out places it before it
+// hangs, as if the response had been lost.
+func (r *SimulationRep) PerformContext(ctx context.Context, _ lager.Logger, work rep.Work) (rep.Work, error) {
+	r.lock.Lock()
+	p := r.planCall(r.faults.PerformLatency, r.faults.PerformErrorRate, r.faults.PerformTimeoutRate)
+	r.lock.Unlock()
+
+	err := r.wait(ctx, p)
+	if err != nil {
+		return rep.Work{}, err
+	}
+
+	failedWork := r.perform(work)
+
+	err = r.hang(ctx, p)
+	if err != nil {
+		return rep.Work{}, err
+	}
+
+	return failedWork, nil
+}
+
+func (r *SimulationRep) perform(work rep.Work) rep.Work {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	r.advance()
+
 	failedWork := rep.Work{}
 
 	availableResources := r.availableResources()
 
 	for _, start := range work.LRPs {
+		if r.rejected() {
+			failedWork.LRPs = append(failedWork.LRPs, start)
+			continue
+		}
+
 		hasRoom := availableResources.Containers >= 0
 		hasRoom = hasRoom && availableResources.MemoryMB >= start.MemoryMB
 		hasRoom = hasRoom && availableResources.DiskMB >= start.DiskMB
 
 		if hasRoom {
 			r.lrps[start.Identifier()] = start
+			r.lrpTimelines[start.Identifier()] = r.lrpTimeline(start.Domain)
 
 			availableResources.Containers -= 1
-			if start.Domain == "auction" {
-				r.startingContainerCount++
-			}
 			availableResources.MemoryMB -= start.MemoryMB
 			availableResources.DiskMB -= start.DiskMB
 		} else {
@@ -96,17 +249,20 @@ func (r *SimulationRep) Perform(_ lager.Logger, work rep.Work) (rep.Work, error)
 	}
 
 	for _, task := range work.Tasks {
+		if r.rejected() {
+			failedWork.Tasks = append(failedWork.Tasks, task)
+			continue
+		}
+
 		hasRoom := availableResources.Containers >= 0
 		hasRoom = hasRoom && availableResources.MemoryMB >= task.MemoryMB
 		hasRoom = hasRoom && availableResources.DiskMB >= task.DiskMB
 
 		if hasRoom {
 			r.tasks[task.TaskGuid] = task
+			r.taskTimelines[task.TaskGuid] = r.taskTimeline(task.Domain)
 
 			availableResources.Containers -= 1
-			if task.Domain == "auction" {
-				r.startingContainerCount++
-			}
 			availableResources.MemoryMB -= task.MemoryMB
 			availableResources.DiskMB -= task.DiskMB
 		} else {
@@ -114,7 +270,7 @@ func (r *SimulationRep) Perform(_ lager.Logger, work rep.Work) (rep.Work, error)
 		}
 	}
 
-	return failedWork, nil
+	return failedWork
 }
 
 //simulation only
@@ -125,30 +281,151 @@ func (r *SimulationRep) Reset() error {
 
 	r.lrps = map[string]rep.LRP{}
 	r.tasks = map[string]rep.Task{}
-	r.startingContainerCount = 0
+	r.lrpTimelines = map[string]timeline{}
+	r.taskTimelines = map[string]timeline{}
+	r.completedTasks = 0
+	r.crashedLRPs = 0
+	r.evacuating = r.evacuatingOnReset
+	r.faults = r.faultsOnReset
+	r.flappingSince = r.clock.Now()
 	return nil
 }
 
-//these are rep client methods the auction does not use
+// SetEvacuating changes whether the rep reports itself as evacuating until
+// it is next reset.
+func (r *SimulationRep) SetEvacuating(evacuating bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.evacuating = evacuating
+}
 
-func (rep *SimulationRep) StopLRPInstance(lager.Logger, models.ActualLRPKey, models.ActualLRPInstanceKey) error {
-	panic("UNIMPLEMENTED METHOD")
+// SetFaults changes how the rep misbehaves until it is next reset.
+func (r *SimulationRep) SetFaults(faults Faults) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.faults = faults
+	r.flappingSince = r.clock.Now()
 }
 
-func (rep *SimulationRep) CancelTask(lager.Logger, string) error {
-	panic("UNIMPLEMENTED METHOD")
+func (r *SimulationRep) Faults() Faults {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.faults
 }
 
-func (rep *SimulationRep) SetStateClient(client *http.Client) {
-	panic("UNIMPLEMENTED METHOD")
+// CompletedTasks is the number of tasks that have completed since the rep
+// was last reset.
+func (r *SimulationRep) CompletedTasks() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance()
+	return r.completedTasks
 }
 
-func (rep *SimulationRep) StateClientTimeout() time.Duration {
-	panic("UNIMPLEMENTED METHOD")
+// CrashedLRPs is the number of LRPs that have crashed since the rep was last
+// reset.
+func (r *SimulationRep) CrashedLRPs() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance()
+	return r.crashedLRPs
+}
+
+// InstanceGuid is the instance guid of the LRP the rep runs for key. Only
+// stopping the LRP over HTTP needs it.
+func InstanceGuid(key models.ActualLRPKey) string {
+	return fmt.Sprintf("%s-%d", key.ProcessGuid, key.Index)
+}
+
+// StopLRPInstance stops the LRP for key and frees its resources. The rep
+// runs a single instance per key, so the instance key is not checked.
+func (r *SimulationRep) StopLRPInstance(_ lager.Logger, key models.ActualLRPKey, _ models.ActualLRPInstanceKey) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance()
+
+	lrp := rep.LRP{ActualLRPKey: key}
+	return r.stopLRP(lrp.Identifier())
+}
+
+// CancelTask stops the task and frees its resources.
+func (r *SimulationRep) CancelTask(_ lager.Logger, taskGuid string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance()
+
+	return r.cancelTask(taskGuid)
+}
+
+// DeleteContainer stops the LRP or task running in the container with the
+// given guid, as the executor would when the rep's HTTP handlers stop an LRP
+// instance or cancel a task.
+func (r *SimulationRep) DeleteContainer(_ lager.Logger, guid string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.advance()
+
+	for identifier, lrp := range r.lrps {
+		if rep.LRPContainerGuid(lrp.ProcessGuid, InstanceGuid(lrp.ActualLRPKey)) == guid {
+			return r.stopLRP(identifier)
+		}
+	}
+	return r.cancelTask(guid)
+}
+
+// SetStateClient sets the client whose timeout State keeps to. The rep makes
+// no HTTP requests.
+func (r *SimulationRep) SetStateClient(client *http.Client) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.stateClient = client
+}
+
+func (r *SimulationRep) StateClientTimeout() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.stateClientTimeout()
 }
 
 //internal -- no locks here
 
+func (r *SimulationRep) stopLRP(identifier string) error {
+	if _, ok := r.lrps[identifier]; !ok {
+		return ErrContainerNotFound
+	}
+
+	delete(r.lrps, identifier)
+	delete(r.lrpTimelines, identifier)
+	return nil
+}
+
+func (r *SimulationRep) cancelTask(taskGuid string) error {
+	if _, ok := r.tasks[taskGuid]; !ok {
+		return ErrContainerNotFound
+	}
+
+	delete(r.tasks, taskGuid)
+	delete(r.taskTimelines, taskGuid)
+	return nil
+}
+
+func (r *SimulationRep) stateClientTimeout() time.Duration {
+	if r.stateClient == nil {
+		return 0
+	}
+	return r.stateClient.Timeout
+}
+
 func (rep *SimulationRep) availableResources() rep.Resources {
 	resources := rep.totalResources
 	for _, lrp := range rep.lrps {
@@ -0,0 +1,147 @@
+package simulationrep_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/simulationrep"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SimulationRep", func() {
+	var (
+		clock   *fakeclock.FakeClock
+		logger  *lagertest.TestLogger
+		simRep  *simulationrep.SimulationRep
+		options []simulationrep.Option
+		lrp     rep.LRP
+		task    rep.Task
+	)
+
+	BeforeEach(func() {
+		clock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("simulationrep")
+		options = []simulationrep.Option{simulationrep.WithClock(clock), simulationrep.WithSeed(42)}
+
+		constraint := rep.NewPlacementConstraint(models.PreloadedRootFS("linux"), nil, nil)
+		lrp = rep.NewLRP(models.NewActualLRPKey("pg-1", 1, simulationrep.AuctionDomain), rep.NewResource(10, 20, 10), constraint)
+		task = rep.NewTask("tg-1", simulationrep.AuctionDomain, rep.NewResource(30, 40, 10), constraint)
+	})
+
+	JustBeforeEach(func() {
+		simRep = simulationrep.New("linux", "Z0", rep.NewResources(100, 100, 100), nil, options...).(*simulationrep.SimulationRep)
+
+		failed, err := simRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed).To(Equal(rep.Work{}))
+	})
+
+	state := func() rep.CellState {
+		state, err := simRep.State(logger)
+		Expect(err).NotTo(HaveOccurred())
+		return state
+	}
+
+	Describe("StopLRPInstance", func() {
+		It("stops the LRP and frees its resources", func() {
+			instanceKey := models.NewActualLRPInstanceKey(simulationrep.InstanceGuid(lrp.ActualLRPKey), "cell")
+			Expect(simRep.StopLRPInstance(logger, lrp.ActualLRPKey, instanceKey)).To(Succeed())
+
+			Expect(state().LRPs).To(BeEmpty())
+			Expect(state().StartingContainerCount).To(Equal(1))
+			Expect(state().AvailableResources).To(Equal(rep.NewResources(70, 60, 99)))
+		})
+
+		It("fails for an LRP the rep does not run", func() {
+			key := models.NewActualLRPKey("pg-1", 2, simulationrep.AuctionDomain)
+			Expect(simRep.StopLRPInstance(logger, key, models.ActualLRPInstanceKey{})).To(Equal(simulationrep.ErrContainerNotFound))
+			Expect(state().LRPs).To(HaveLen(1))
+		})
+	})
+
+	Describe("CancelTask", func() {
+		It("stops the task and frees its resources", func() {
+			Expect(simRep.CancelTask(logger, "tg-1")).To(Succeed())
+
+			Expect(state().Tasks).To(BeEmpty())
+			Expect(state().AvailableResources).To(Equal(rep.NewResources(90, 80, 99)))
+		})
+
+		It("fails for a task the rep does not run", func() {
+			Expect(simRep.CancelTask(logger, "tg-2")).To(Equal(simulationrep.ErrContainerNotFound))
+		})
+	})
+
+	Describe("DeleteContainer", func() {
+		It("stops the LRP or task in the container", func() {
+			containerGuid := rep.LRPContainerGuid(lrp.ProcessGuid, simulationrep.InstanceGuid(lrp.ActualLRPKey))
+			Expect(simRep.DeleteContainer(logger, containerGuid)).To(Succeed())
+			Expect(state().LRPs).To(BeEmpty())
+
+			Expect(simRep.DeleteContainer(logger, "tg-1")).To(Succeed())
+			Expect(state().Tasks).To(BeEmpty())
+
+			Expect(simRep.DeleteContainer(logger, "tg-1")).To(Equal(simulationrep.ErrContainerNotFound))
+		})
+
+		It("stops the LRP only once when the container is deleted concurrently", func() {
+			containerGuid := rep.LRPContainerGuid(lrp.ProcessGuid, simulationrep.InstanceGuid(lrp.ActualLRPKey))
+
+			errs := make(chan error, 10)
+			for i := 0; i < cap(errs); i++ {
+				go func() {
+					defer GinkgoRecover()
+					errs <- simRep.DeleteContainer(logger, containerGuid)
+				}()
+			}
+
+			succeeded := 0
+			for i := 0; i < cap(errs); i++ {
+				if err := <-errs; err == nil {
+					succeeded++
+				} else {
+					Expect(err).To(Equal(simulationrep.ErrContainerNotFound))
+				}
+			}
+			Expect(succeeded).To(Equal(1))
+			Expect(state().LRPs).To(BeEmpty())
+		})
+	})
+
+	Describe("the state client", func() {
+		It("reports the timeout of the state client", func() {
+			Expect(simRep.StateClientTimeout()).To(BeZero())
+
+			simRep.SetStateClient(&http.Client{Timeout: 5 * time.Second})
+			Expect(simRep.StateClientTimeout()).To(Equal(5 * time.Second))
+		})
+
+		Context("when State is slower than the timeout", func() {
+			BeforeEach(func() {
+				options = append(options, simulationrep.WithFaults(simulationrep.Faults{
+					StateLatency: simulationrep.Latency{Mean: 10 * time.Second},
+				}))
+			})
+
+			It("gives up once the timeout has passed", func() {
+				simRep.SetStateClient(&http.Client{Timeout: 5 * time.Second})
+
+				errs := make(chan error, 1)
+				go func() {
+					_, err := simRep.State(logger)
+					errs <- err
+				}()
+
+				clock.WaitForWatcherAndIncrement(5 * time.Second)
+				Eventually(errs).Should(Receive(Equal(context.DeadlineExceeded)))
+			})
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package simulationrep_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSimulationrep(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulationrep Suite")
+}
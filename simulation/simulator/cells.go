@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/rep"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const repNodeStartTimeout = 10 * time.Second
+
+// launchExternalHTTPReps starts a repnode per cell and returns clients for
+// them. The commands are returned even on error so they can be stopped.
+// Every repnode starts its cell's pre-existing LRPs itself.
+func launchExternalHTTPReps(opts options, cells []scenario.Cell) (map[string]rep.SimClient, []*exec.Cmd, error) {
+	reps := map[string]rep.SimClient{}
+	repNodes := []*exec.Cmd{}
+
+	client := &http.Client{
+		Timeout: opts.timeout,
+	}
+
+	factory, err := rep.NewClientFactory(client, client, nil)
+	if err != nil {
+		return nil, repNodes, err
+	}
+
+	for i, cell := range cells {
+		httpAddr := fmt.Sprintf("127.0.0.1:%d", opts.basePort+i)
+
+		faults, err := yaml.Marshal(cell.Faults)
+		if err != nil {
+			return nil, repNodes, err
+		}
+
+		args := []string{
+			"-repGuid", cell.Guid,
+			"-httpAddr", httpAddr,
+			"-memoryMB", fmt.Sprintf("%d", cell.Resources.MemoryMB),
+			"-diskMB", fmt.Sprintf("%d", cell.Resources.DiskMB),
+			"-containers", fmt.Sprintf("%d", cell.Resources.Containers),
+			"-stack", strings.Join(cell.Stacks, ","),
+			"-zone", cell.Zone,
+			"-volumeDrivers", strings.Join(cell.VolumeDrivers, ","),
+			"-placementTags", strings.Join(cell.PlacementTags, ","),
+			"-optionalPlacementTags", strings.Join(cell.OptionalPlacementTags, ","),
+			fmt.Sprintf("-evacuating=%t", cell.Evacuating),
+			"-faults", string(faults),
+		}
+		if cell.Lifecycle != nil {
+			lifecycle, err := yaml.Marshal(cell.Lifecycle)
+			if err != nil {
+				return nil, repNodes, err
+			}
+			args = append(args, "-lifecycle", string(lifecycle))
+		}
+		if work := cell.PreloadedWork(); len(work.LRPs) > 0 {
+			preloadedWork, err := json.Marshal(work)
+			if err != nil {
+				return nil, repNodes, err
+			}
+			args = append(args, "-preloadedWork", string(preloadedWork))
+		}
+
+		serverCmd := exec.Command(opts.repNodeBinary, args...)
+		serverCmd.Stderr = os.Stderr
+
+		err = startRepNode(serverCmd)
+		if err != nil {
+			return nil, repNodes, fmt.Errorf("failed to start rep node %s: %s", cell.Guid, err)
+		}
+		repNodes = append(repNodes, serverCmd)
+
+		cellClient, err := factory.CreateClient("http://"+httpAddr, "")
+		if err != nil {
+			return nil, repNodes, err
+		}
+		reps[cell.Guid] = cellClient.(rep.SimClient)
+	}
+
+	return reps, repNodes, nil
+}
+
+func startRepNode(cmd *exec.Cmd) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	listening := make(chan bool, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		found := false
+		for scanner.Scan() {
+			if !found && strings.Contains(scanner.Text(), "listening") {
+				found = true
+				listening <- true
+			}
+		}
+		if !found {
+			listening <- false
+		}
+	}()
+
+	select {
+	case ok := <-listening:
+		if ok {
+			return nil
+		}
+		cmd.Wait()
+		return fmt.Errorf("exited before listening")
+	case <-time.After(repNodeStartTimeout):
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("did not start listening within %s", repNodeStartTimeout)
+	}
+}
+
+func stopRepNodes(repNodes []*exec.Cmd) {
+	for _, cmd := range repNodes {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/rep"
+)
+
+type auctionRunnerDelegate struct {
+	cells   map[string]rep.Client
+	results auctiontypes.AuctionResults
+	lock    *sync.Mutex
+}
+
+func newAuctionRunnerDelegate(cells map[string]rep.SimClient) *auctionRunnerDelegate {
+	clients := map[string]rep.Client{}
+	for guid, cell := range cells {
+		clients[guid] = cell
+	}
+	return &auctionRunnerDelegate{
+		cells: clients,
+		lock:  &sync.Mutex{},
+	}
+}
+
+func (a *auctionRunnerDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	return a.cells, nil
+}
+
+// reset forgets the results of the previous wave.
+func (a *auctionRunnerDelegate) reset() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.results = auctiontypes.AuctionResults{}
+}
+
+func (a *auctionRunnerDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.results.SuccessfulLRPs = append(a.results.SuccessfulLRPs, results.SuccessfulLRPs...)
+	a.results.SuccessfulTasks = append(a.results.SuccessfulTasks, results.SuccessfulTasks...)
+	a.results.FailedLRPs = append(a.results.FailedLRPs, results.FailedLRPs...)
+	a.results.FailedTasks = append(a.results.FailedTasks, results.FailedTasks...)
+	a.results.UnknownLRPs = append(a.results.UnknownLRPs, results.UnknownLRPs...)
+	a.results.UnknownTasks = append(a.results.UnknownTasks, results.UnknownTasks...)
+	a.results.CancelledLRPs = append(a.results.CancelledLRPs, results.CancelledLRPs...)
+	a.results.CancelledTasks = append(a.results.CancelledTasks, results.CancelledTasks...)
+}
+
+func (a *auctionRunnerDelegate) resultSize() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return len(a.results.SuccessfulLRPs) + len(a.results.SuccessfulTasks) +
+		len(a.results.FailedLRPs) + len(a.results.FailedTasks) +
+		len(a.results.UnknownLRPs) + len(a.results.UnknownTasks) +
+		len(a.results.CancelledLRPs) + len(a.results.CancelledTasks)
+}
+
+func (a *auctionRunnerDelegate) Results() auctiontypes.AuctionResults {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.results
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctionfashion"
+	"code.cloudfoundry.org/auction/auctionmetrics"
+	"code.cloudfoundry.org/auction/auctionrunner"
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/auction/simulation/util"
+	"code.cloudfoundry.org/auction/simulation/visualization"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/workpool"
+	"github.com/tedsuo/ifrit"
+)
+
+const (
+	inProcess = "inprocess"
+	httpMode  = "http"
+)
+
+const reportColumns = 4
+
+var auctionTypes = map[string]auctionrunner.AuctionTypeFunc{
+	"default": auctionfashion.DefaultAuction,
+	"bestfit": auctionfashion.BestFit,
+}
+
+type options struct {
+	scenarioPath            string
+	auctionTypeFunc         auctionrunner.AuctionTypeFunc
+	communicationMode       string
+	repNodeBinary           string
+	basePort                int
+	timeout                 time.Duration
+	waveTimeout             time.Duration
+	workers                 int
+	startingContainerWeight float64
+	reportName              string
+	disableSVGReport        bool
+	verbose                 bool
+}
+
+// parseOptions parses the command line arguments, without the program name.
+func parseOptions(args []string) (options, error) {
+	opts := options{}
+	var fashion string
+
+	flags := flag.NewFlagSet("simulator", flag.ContinueOnError)
+	flags.StringVar(&opts.scenarioPath, "scenario", "", "path to the YAML or JSON scenario file")
+	flags.StringVar(&fashion, "fashion", "default", "auction fashion to simulate: default or bestfit")
+	flags.StringVar(&opts.communicationMode, "communicationMode", inProcess, "one of inprocess or http")
+	flags.StringVar(&opts.repNodeBinary, "repNodeBinary", "repnode", "repnode binary to launch in http mode")
+	flags.IntVar(&opts.basePort, "basePort", 30000, "port of the first rep node in http mode")
+	flags.DurationVar(&opts.timeout, "timeout", time.Second, "timeout when waiting for responses from remote calls")
+	flags.DurationVar(&opts.waveTimeout, "waveTimeout", time.Minute, "time to wait for every auction of a wave to complete")
+	flags.IntVar(&opts.workers, "workers", 500, "number of concurrent communication worker pools")
+	flags.Float64Var(&opts.startingContainerWeight, "startingContainerWeight", 0.25, "weight given to starting containers when scoring cells")
+	flags.StringVar(&opts.reportName, "reportName", "report", "report name")
+	flags.BoolVar(&opts.disableSVGReport, "disableSVGReport", false, "do not write an SVG report of the waves")
+	flags.BoolVar(&opts.verbose, "verbose", false, "log while auctioning")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return options{}, err
+	}
+
+	if opts.scenarioPath == "" {
+		return options{}, fmt.Errorf("need a scenario")
+	}
+
+	var ok bool
+	opts.auctionTypeFunc, ok = auctionTypes[fashion]
+	if !ok {
+		return options{}, fmt.Errorf("unknown fashion %q", fashion)
+	}
+
+	if opts.communicationMode != inProcess && opts.communicationMode != httpMode {
+		return options{}, fmt.Errorf("unknown communication mode: %s", opts.communicationMode)
+	}
+
+	return opts, nil
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	s, err := scenario.Load(opts.scenarioPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load scenario: %s\n", err)
+		os.Exit(1)
+	}
+
+	logger := lager.NewLogger("simulator")
+	if opts.verbose {
+		logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.INFO))
+	}
+
+	err = simulate(logger, s, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func simulate(logger lager.Logger, s scenario.Scenario, opts options) error {
+	cells := s.Cells()
+	util.ResetGuids()
+
+	workPool, err := workpool.NewWorkPool(opts.workers)
+	if err != nil {
+		return err
+	}
+	defer workPool.Stop()
+
+	var reps map[string]rep.SimClient
+	switch opts.communicationMode {
+	case inProcess:
+		reps = scenario.BuildReps(cells)
+		resetCells(workPool, reps)
+
+		err = scenario.Seed(logger, cells, reps)
+		if err != nil {
+			return err
+		}
+	case httpMode:
+		// rep nodes start their own pre-existing LRPs, with their faults
+		// suspended, as they are launched
+		var repNodes []*exec.Cmd
+		reps, repNodes, err = launchExternalHTTPReps(opts, cells)
+		defer stopRepNodes(repNodes)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown communication mode: %s", opts.communicationMode)
+	}
+
+	var svgReport *visualization.SVGReport
+	if !opts.disableSVGReport {
+		rows := (len(s.Waves) + reportColumns - 1) / reportColumns
+		svgReport, err = visualization.NewSVGReport(opts.reportName+".svg", reportColumns, rows, len(cells))
+		if err != nil {
+			return fmt.Errorf("failed to create svg report: %s", err)
+		}
+		svgReport.DrawHeader(opts.communicationMode)
+	}
+
+	delegate := newAuctionRunnerDelegate(reps)
+	runner := auctionrunner.New(
+		logger,
+		delegate,
+		auctionmetrics.NewPrometheusEmitter(),
+		clock.NewClock(),
+		workPool,
+		opts.startingContainerWeight,
+		0,
+		auctionfashion.NewAuctionType(opts.auctionTypeFunc),
+	)
+	process := ifrit.Invoke(runner)
+	defer func() {
+		process.Signal(os.Interrupt)
+		<-process.Wait()
+	}()
+
+	reports := []*visualization.Report{}
+	for i, wave := range s.Waves {
+		fmt.Printf("Running %s\n", wave.Name)
+		report, err := runWave(runner, delegate, wave, opts.waveTimeout)
+		if err != nil {
+			return fmt.Errorf("%s: %s", wave.Name, err)
+		}
+
+		visualization.PrintReport(report)
+		if svgReport != nil {
+			svgReport.DrawReportCard(i%reportColumns, i/reportColumns, report)
+		}
+		reports = append(reports, report)
+	}
+
+	if svgReport != nil {
+		svgReport.Done()
+	}
+
+	data, err := json.Marshal(reports)
+	if err == nil {
+		err = ioutil.WriteFile(opts.reportName+".json", data, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write json report: %s", err)
+	}
+
+	return nil
+}
+
+func runWave(runner auctiontypes.AuctionRunner, delegate *auctionRunnerDelegate, wave scenario.Wave, waveTimeout time.Duration) (*visualization.Report, error) {
+	delegate.reset()
+
+	lrpStarts, taskStarts := wave.Requests()
+	numAuctions := wave.AuctionCount()
+
+	start := time.Now()
+	if len(lrpStarts) > 0 {
+		err := runner.ScheduleLRPsForAuctions(lrpStarts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(taskStarts) > 0 {
+		err := runner.ScheduleTasksForAuctions(taskStarts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deadline := time.Now().Add(waveTimeout)
+	for delegate.resultSize() < numAuctions {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out with %d of %d auctions complete", delegate.resultSize(), numAuctions)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	duration := time.Since(start)
+
+	cells, _ := delegate.FetchCellReps()
+	return visualization.NewReport(wave.InstanceCount(), cells, delegate.Results(), duration), nil
+}
+
+func resetCells(workPool *workpool.WorkPool, cells map[string]rep.SimClient) {
+	wg := &sync.WaitGroup{}
+	wg.Add(len(cells))
+	for _, cell := range cells {
+		cell := cell
+		workPool.Submit(func() {
+			cell.Reset()
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auction/simulation/scenario"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulator", func() {
+	Describe("parseOptions", func() {
+		It("defaults everything but the scenario", func() {
+			opts, err := parseOptions([]string{"-scenario", "scenario.yml"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(opts.scenarioPath).To(Equal("scenario.yml"))
+			Expect(opts.auctionTypeFunc).NotTo(BeNil())
+			Expect(opts.communicationMode).To(Equal(inProcess))
+			Expect(opts.repNodeBinary).To(Equal("repnode"))
+			Expect(opts.basePort).To(Equal(30000))
+			Expect(opts.timeout).To(Equal(time.Second))
+			Expect(opts.waveTimeout).To(Equal(time.Minute))
+			Expect(opts.workers).To(Equal(500))
+			Expect(opts.startingContainerWeight).To(Equal(0.25))
+			Expect(opts.reportName).To(Equal("report"))
+			Expect(opts.disableSVGReport).To(BeFalse())
+			Expect(opts.verbose).To(BeFalse())
+		})
+
+		It("parses every flag", func() {
+			opts, err := parseOptions([]string{
+				"-scenario", "scenario.yml",
+				"-fashion", "bestfit",
+				"-communicationMode", "http",
+				"-repNodeBinary", "/bin/repnode",
+				"-basePort", "40000",
+				"-timeout", "2s",
+				"-waveTimeout", "3m",
+				"-workers", "10",
+				"-startingContainerWeight", "0.5",
+				"-reportName", "bestfit",
+				"-disableSVGReport",
+				"-verbose",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(opts.auctionTypeFunc).NotTo(BeNil())
+			Expect(opts.communicationMode).To(Equal(httpMode))
+			Expect(opts.repNodeBinary).To(Equal("/bin/repnode"))
+			Expect(opts.basePort).To(Equal(40000))
+			Expect(opts.timeout).To(Equal(2 * time.Second))
+			Expect(opts.waveTimeout).To(Equal(3 * time.Minute))
+			Expect(opts.workers).To(Equal(10))
+			Expect(opts.startingContainerWeight).To(Equal(0.5))
+			Expect(opts.reportName).To(Equal("bestfit"))
+			Expect(opts.disableSVGReport).To(BeTrue())
+			Expect(opts.verbose).To(BeTrue())
+		})
+
+		It("fails without a scenario", func() {
+			_, err := parseOptions([]string{"-fashion", "bestfit"})
+			Expect(err).To(MatchError("need a scenario"))
+		})
+
+		It("fails for an unknown fashion", func() {
+			_, err := parseOptions([]string{"-scenario", "scenario.yml", "-fashion", "worstfit"})
+			Expect(err).To(MatchError(`unknown fashion "worstfit"`))
+		})
+
+		It("fails for an unknown communication mode", func() {
+			_, err := parseOptions([]string{"-scenario", "scenario.yml", "-communicationMode", "carrier-pigeon"})
+			Expect(err).To(MatchError("unknown communication mode: carrier-pigeon"))
+		})
+	})
+
+	Describe("simulating", func() {
+		var (
+			tmpDir string
+			args   []string
+		)
+
+		type report struct {
+			NumAuctions    int
+			AuctionResults auctiontypes.AuctionResults
+			CellStates     map[string]rep.CellState
+		}
+
+		simulateScenario := func(yaml string) []report {
+			scenarioPath := filepath.Join(tmpDir, "scenario.yml")
+			Expect(ioutil.WriteFile(scenarioPath, []byte(yaml), 0644)).To(Succeed())
+
+			opts, err := parseOptions(append([]string{
+				"-scenario", scenarioPath,
+				"-reportName", filepath.Join(tmpDir, "report"),
+				"-waveTimeout", "10s",
+			}, args...))
+			Expect(err).NotTo(HaveOccurred())
+
+			s, err := scenario.Load(scenarioPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(simulate(lagertest.NewTestLogger("simulator"), s, opts)).To(Succeed())
+
+			payload, err := ioutil.ReadFile(filepath.Join(tmpDir, "report.json"))
+			Expect(err).NotTo(HaveOccurred())
+			reports := []report{}
+			Expect(json.Unmarshal(payload, &reports)).To(Succeed())
+			return reports
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "simulator")
+			Expect(err).NotTo(HaveOccurred())
+			args = []string{}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("auctions every wave and writes the reports", func() {
+			reports := simulateScenario(`
+pools:
+- count: 4
+  memory_mb: 100
+  disk_mb: 100
+  containers: 100
+  lrps:
+  - count: 2
+    memory_mb: 10
+    disk_mb: 10
+waves:
+- lrps:
+  - processes: 5
+    instances: 2
+    memory_mb: 10
+    disk_mb: 10
+- tasks:
+  - count: 100
+    memory_mb: 10
+    disk_mb: 10
+`)
+
+			Expect(reports).To(HaveLen(2))
+			Expect(reports[0].NumAuctions).To(Equal(10))
+			Expect(reports[0].AuctionResults.SuccessfulLRPs).To(HaveLen(10))
+
+			lrps := 0
+			for _, state := range reports[0].CellStates {
+				lrps += len(state.LRPs)
+			}
+			Expect(lrps).To(Equal(4*2 + 10))
+
+			// the memory left by the pre-existing LRPs and the first wave
+			Expect(reports[1].AuctionResults.SuccessfulTasks).To(HaveLen(22))
+			Expect(reports[1].AuctionResults.FailedTasks).To(HaveLen(78))
+
+			Expect(filepath.Join(tmpDir, "report.svg")).To(BeAnExistingFile())
+		})
+
+		It("seeds the pre-existing LRPs with the faults suspended", func() {
+			reports := simulateScenario(`
+pools:
+- count: 2
+  memory_mb: 100
+  disk_mb: 100
+  containers: 100
+  lrps:
+  - count: 5
+    memory_mb: 10
+    disk_mb: 10
+  faults:
+    rejection_rate: 1
+waves:
+- lrps:
+  - processes: 4
+    instances: 1
+    memory_mb: 10
+    disk_mb: 10
+`)
+
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].AuctionResults.FailedLRPs).To(HaveLen(4))
+			for _, state := range reports[0].CellStates {
+				Expect(state.LRPs).To(HaveLen(5))
+			}
+		})
+	})
+})
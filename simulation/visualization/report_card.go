@@ -41,11 +41,20 @@ type SVGReport struct {
 }
 
 func StartSVGReport(path string, width, height int, numCells int) *SVGReport {
+	report, err := NewSVGReport(path, width, height, numCells)
+	Expect(err).NotTo(HaveOccurred())
+	return report
+}
+
+// NewSVGReport is StartSVGReport for callers outside of a test suite.
+func NewSVGReport(path string, width, height int, numCells int) (*SVGReport, error) {
 	instanceBoxHeight = instanceSize*numCells + instanceSpacing*(numCells-1)
 	ReportCardHeight = border*3 + instanceBoxHeight
 
 	f, err := os.Create(path)
-	Expect(err).NotTo(HaveOccurred())
+	if err != nil {
+		return nil, err
+	}
 	s := svg.New(f)
 	s.Start(width*ReportCardWidth, headerHeight+height*ReportCardHeight)
 	return &SVGReport{
@@ -53,7 +62,7 @@ func StartSVGReport(path string, width, height int, numCells int) *SVGReport {
 		SVG:    s,
 		width:  width,
 		height: height,
-	}
+	}, nil
 }
 
 func (r *SVGReport) Done() {